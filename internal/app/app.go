@@ -9,8 +9,10 @@ import (
     "github.com/gin-contrib/cors"
     "github.com/gin-gonic/gin"
     "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/internal/domain/rating"
     "github.com/tem-mars/tft-game-server/internal/handler"
     "github.com/tem-mars/tft-game-server/internal/middleware"   
+    "github.com/tem-mars/tft-game-server/pkg/broker"
     "github.com/tem-mars/tft-game-server/pkg/logger"
     "github.com/tem-mars/tft-game-server/internal/service"
     "github.com/tem-mars/tft-game-server/internal/repository"
@@ -20,6 +22,8 @@ type App struct {
     cfg    *Config  
     log    logger.Logger
     server *http.Server
+    broker broker.Broker
+    cancel context.CancelFunc
 }
 
 func New(cfg *Config, log logger.Logger) (*App, error) {
@@ -39,11 +43,91 @@ func New(cfg *Config, log logger.Logger) (*App, error) {
 
     playerRepo := repository.NewMemoryPlayerRepository()
     authService := service.NewAuthService(playerRepo, cfg.JWT.Secret)
+    for _, admin := range cfg.Admins {
+        if err := authService.SeedAdmin(admin.Username, admin.Email, admin.Password); err != nil {
+            return nil, fmt.Errorf("failed to seed admin %s: %w", admin.Username, err)
+        }
+    }
     gameManager := game.NewGameManager(playerRepo)
+    if cfg.Bots.BackfillAfter > 0 {
+        gameManager.SetBotBackfill(cfg.Bots.BackfillAfter, game.BotDifficulty(cfg.Bots.Difficulty))
+    }
+
+    // เดินเวลาของเกม (AFK, ล้างเกมเก่า) จนกว่าจะ shutdown
+    ctx, cancel := context.WithCancel(context.Background())
+    go gameManager.Run(ctx)
+
+    ratingService := service.NewRatingService(playerRepo, rating.NewGlicko2(repository.DefaultMMR))
+    gameManager.AddOnGameFinished(ratingService.RecordGame)
+
+    seasonLength := cfg.Seasons.Length
+    if seasonLength <= 0 {
+        seasonLength = defaultSeasonLength
+    }
+    rankService := service.NewRankService(playerRepo, seasonLength)
+    gameManager.AddOnGameFinished(rankService.RecordGame)
+    go rankService.Run(ctx)
+
+    matchHistoryService := service.NewMatchHistoryService(playerRepo)
+    gameManager.AddOnGameFinished(matchHistoryService.RecordGame)
+
+    replayService := service.NewReplayService(playerRepo)
+    gameManager.AddOnGameFinished(replayService.RecordGame)
+
+    leaderboardService := service.NewLeaderboardService(playerRepo, cfg.Leaderboard.RefreshInterval)
+    gameManager.AddOnGameFinished(leaderboardService.MarkDirty)
+    // soft reset เปลี่ยน rating ทุกคน กระดาน rating ต้องสร้างใหม่
+    rankService.AddOnSeasonEnded(func(service.Season) { leaderboardService.MarkDirty(nil) })
+    go leaderboardService.Run(ctx)
+
+    mmConfig := service.DefaultMatchmakingConfig()
+    if cfg.Matchmaking.LobbySize > 0 {
+        mmConfig.LobbySize = cfg.Matchmaking.LobbySize
+    }
+    if cfg.Matchmaking.AcceptTimeout > 0 {
+        mmConfig.AcceptTimeout = cfg.Matchmaking.AcceptTimeout
+    }
+    matchmakingService := service.NewMatchmakingService(mmConfig, playerRepo, playerRepo, gameManager)
+    go matchmakingService.Run(ctx)
+
+    partyService := service.NewPartyService(playerRepo, matchmakingService, cfg.Matchmaking.MaxPartySize)
+
+    // มีหลายเครื่องเมื่อตั้ง redis_addr ข้อความถึงผู้เล่นจึงต้องผ่าน Redis ไม่อย่างนั้นใช้ broker ภายใน process
+    var eventBroker broker.Broker = broker.NewLocal()
+    if cfg.Broker.RedisAddr != "" {
+        redisBroker, err := broker.NewRedis(broker.RedisConfig{
+            Addr:     cfg.Broker.RedisAddr,
+            Password: cfg.Broker.Password,
+            Prefix:   cfg.Broker.Prefix,
+        }, log)
+        if err != nil {
+            cancel()
+            return nil, fmt.Errorf("failed to connect to broker: %w", err)
+        }
+        eventBroker = redisBroker
+    }
 
     // Initialize handlers
     authHandler := handler.NewAuthHandler(authService, log)
     gameHandler := handler.NewGameHandler(gameManager, log, cfg.JWT.Secret)
+    gameHandler.SetWSConfig(handler.WSConfig{
+        SendQueueSize:  cfg.WebSocket.SendQueueSize,
+        WriteTimeout:   cfg.WebSocket.WriteTimeout,
+        SlowConsumer:   handler.SlowConsumerPolicy(cfg.WebSocket.SlowConsumer),
+        PingInterval:   cfg.WebSocket.PingInterval,
+        PongWait:       cfg.WebSocket.PongWait,
+        MaxMessageSize: cfg.WebSocket.MaxMessageSize,
+    })
+    gameHandler.SetSessionPolicy(handler.SessionPolicy(cfg.WebSocket.SessionPolicy))
+    gameHandler.SetBroker(eventBroker)
+    gameHandler.SetQueueLockout(matchmakingService.CheckLockout)
+    matchmakingHandler := handler.NewMatchmakingHandler(matchmakingService, partyService, gameHandler.SendToPlayer, gameHandler.Publish, log)
+    gameHandler.RegisterTopic(handler.TopicQueue, matchmakingHandler.QueueTopic)
+    partyHandler := handler.NewPartyHandler(partyService, gameHandler.SendToPlayer, log)
+    playerHandler := handler.NewPlayerHandler(ratingService, rankService, log)
+    leaderboardHandler := handler.NewLeaderboardHandler(leaderboardService, log)
+    matchHandler := handler.NewMatchHandler(matchHistoryService, gameHandler.SendToPlayer, log)
+    replayHandler := handler.NewReplayHandler(replayService, log, cfg.JWT.Secret)
 
     // Public routes
     router.GET("/health", func(c *gin.Context) {
@@ -54,6 +138,7 @@ func New(cfg *Config, log logger.Logger) (*App, error) {
 
     // WebSocket route
     router.GET("/games/ws", gameHandler.HandleWebSocket)
+    router.GET("/replays/:id/ws", replayHandler.StreamReplay)
 
     // Protected routes
     protected := router.Group("")
@@ -62,8 +147,52 @@ func New(cfg *Config, log logger.Logger) (*App, error) {
         // Game routes
         protected.POST("/games", gameHandler.CreateGame)
         protected.POST("/games/:gameId/join", gameHandler.JoinGame)
+        protected.POST("/games/:gameId/leave", gameHandler.LeaveGame)
+        protected.POST("/games/join", gameHandler.JoinByCode)
+        protected.POST("/games/:gameId/kick", gameHandler.KickPlayer)
+        protected.POST("/games/:gameId/slots", gameHandler.SetSlots)
+        protected.POST("/games/:gameId/ready", gameHandler.SetReady)
+        protected.POST("/games/:gameId/start", gameHandler.StartGame)
+        protected.POST("/games/:gameId/bots", gameHandler.AddBot)
         protected.GET("/games/waiting", gameHandler.GetWaitingGames)
+        protected.GET("/games/settings", gameHandler.GetGameSettings)
+        protected.GET("/games/events", gameHandler.StreamEvents)
+        protected.GET("/games/:gameId", gameHandler.GetGame)
+        protected.GET("/games/:gameId/state", gameHandler.GetGameState)
         protected.POST("/games/match", gameHandler.AutoMatch)
+        protected.POST("/announcements", gameHandler.Announce)
+
+        // Matchmaking routes
+        protected.POST("/matchmaking/queue", matchmakingHandler.JoinQueue)
+        protected.DELETE("/matchmaking/queue", matchmakingHandler.LeaveQueue)
+        protected.GET("/matchmaking/queue", matchmakingHandler.GetQueueStatus)
+        protected.POST("/matchmaking/accept", matchmakingHandler.AcceptMatch)
+        protected.POST("/matchmaking/decline", matchmakingHandler.DeclineMatch)
+
+        // Party routes
+        protected.GET("/party", partyHandler.GetParty)
+        protected.POST("/party/invite", partyHandler.Invite)
+        protected.POST("/party/:partyId/accept", partyHandler.AcceptInvite)
+        protected.POST("/party/:partyId/decline", partyHandler.DeclineInvite)
+        protected.POST("/party/leave", partyHandler.Leave)
+        protected.POST("/party/kick", partyHandler.Kick)
+
+        // Player routes
+        protected.GET("/players/:id/rating", playerHandler.GetRating)
+        protected.GET("/players/:id/rating/history", playerHandler.GetRatingHistory)
+        protected.GET("/players/:id/rank", playerHandler.GetRank)
+        protected.GET("/players/:id/seasons", playerHandler.GetSeasonHistory)
+        protected.GET("/players/:id/matches", matchHandler.GetPlayerMatches)
+        protected.GET("/seasons/current", playerHandler.GetCurrentSeason)
+
+        // Match history routes
+        protected.GET("/matches/:id", matchHandler.GetMatch)
+        protected.GET("/replays/:id/export", replayHandler.ExportReplay)
+
+        // Leaderboard routes (board: rating | wins | placement)
+        protected.GET("/leaderboards/:board", leaderboardHandler.GetLeaderboard)
+        protected.GET("/leaderboards/:board/me", leaderboardHandler.GetMyPosition)
+        protected.GET("/leaderboards/:board/friends", leaderboardHandler.GetFriendsLeaderboard)
 
         protected.GET("/items", gameHandler.GetAvailableItems)
         protected.POST("/items/buy", gameHandler.BuyItem)
@@ -78,6 +207,8 @@ func New(cfg *Config, log logger.Logger) (*App, error) {
         cfg:    cfg,
         log:    log,
         server: server,
+        broker: eventBroker,
+        cancel: cancel,
     }, nil
 }
 
@@ -90,7 +221,10 @@ func (a *App) Start() error {
 }
 
 func (a *App) Shutdown(ctx context.Context) error {
-    return a.server.Shutdown(ctx)
+    a.cancel()
+    err := a.server.Shutdown(ctx)
+    a.broker.Close()
+    return err
 }
 
 func LoggerMiddleware(log logger.Logger) gin.HandlerFunc {
@@ -1,5 +1,7 @@
 package app
 
+import "time"
+
 type Config struct {
     Server struct {
         Host string
@@ -8,14 +10,68 @@ type Config struct {
     JWT struct {
         Secret string
     }
+    Admins []AdminConfig // บัญชีผู้ดูแลที่สร้างให้ตอนเริ่มระบบ login แล้วได้ role admin
+    Bots struct {
+        BackfillAfter time.Duration // 0 = ไม่เติมบอท
+        Difficulty    string
+    }
+    Matchmaking struct {
+        LobbySize     int           // 0 = ใช้ค่าเริ่มต้นของ service
+        AcceptTimeout time.Duration // 0 = ใช้ค่าเริ่มต้นของ service
+        MaxPartySize  int
+    }
+    Seasons struct {
+        Length time.Duration
+    }
+    Leaderboard struct {
+        RefreshInterval time.Duration
+    }
+    WebSocket struct {
+        SendQueueSize  int
+        WriteTimeout   time.Duration
+        SlowConsumer   string // disconnect | resync
+        PingInterval   time.Duration
+        PongWait       time.Duration
+        MaxMessageSize int64
+        SessionPolicy  string // multiple | kick_older
+    }
+    Broker struct {
+        RedisAddr string // ว่าง = เครื่องเดียว ใช้ broker ภายใน process
+        Password  string
+        Prefix    string // ใช้นำหน้าชื่อ channel เมื่อใช้ Redis ร่วมกับระบบอื่น
+    }
 }
 
+// AdminConfig คือบัญชีผู้ดูแลหนึ่งบัญชี ถ้ามีชื่อนี้อยู่แล้วจะใช้บัญชีเดิม
+type AdminConfig struct {
+    Username string
+    Email    string
+    Password string
+}
+
+const defaultSeasonLength = 90 * 24 * time.Hour
+
 func LoadConfig() (*Config, error) {
     cfg := &Config{}
     
     cfg.Server.Host = "localhost"
     cfg.Server.Port = "8080"
     cfg.JWT.Secret = "your-secret-key"  
+    cfg.Bots.BackfillAfter = 30 * time.Second
+    cfg.Bots.Difficulty = "greedy"
+    cfg.Matchmaking.LobbySize = 8
+    cfg.Matchmaking.AcceptTimeout = 15 * time.Second
+    cfg.Matchmaking.MaxPartySize = 2
+    cfg.Seasons.Length = defaultSeasonLength
+    cfg.Leaderboard.RefreshInterval = 30 * time.Second
+    cfg.WebSocket.SendQueueSize = 64
+    cfg.WebSocket.WriteTimeout = 10 * time.Second
+    cfg.WebSocket.SlowConsumer = "resync"
+    cfg.WebSocket.PingInterval = 30 * time.Second
+    cfg.WebSocket.PongWait = 60 * time.Second
+    cfg.WebSocket.MaxMessageSize = 4096
+    cfg.WebSocket.SessionPolicy = "multiple"
+    cfg.Broker.Prefix = "tft:"
 
     return cfg, nil
 }
\ No newline at end of file
@@ -0,0 +1,133 @@
+package game
+
+import (
+    "time"
+)
+
+// AFKAction คือสิ่งที่ระบบจะทำกับผู้เล่นที่ไม่ได้เล่นนานเกินกำหนด
+type AFKAction string
+
+const (
+    AFKForfeit   AFKAction = "forfeit"    // ยอมแพ้และได้อันดับแย่สุดที่เหลืออยู่
+    AFKAutoPilot AFKAction = "auto_pilot" // ให้ระบบเล่นแทนเพื่อไม่ให้ล็อบบี้พัง
+)
+
+type AFKConfig struct {
+    IdleTimeout       time.Duration `json:"idle_timeout"`       // ไม่มี action นานเกินนี้ถือว่า AFK
+    DisconnectTimeout time.Duration `json:"disconnect_timeout"` // หลุดการเชื่อมต่อนานเกินนี้ถือว่า AFK
+    Action            AFKAction     `json:"action"`
+    AutoPilotInterval time.Duration `json:"auto_pilot_interval"` // ระยะห่างระหว่าง action ของ auto-pilot
+}
+
+// ค่าเริ่มต้นของแต่ละโหมด
+var DefaultAFKConfigs = map[GameMode]AFKConfig{
+    ModeClassic: {
+        IdleTimeout:       2 * time.Minute,
+        DisconnectTimeout: time.Minute,
+        Action:            AFKAutoPilot,
+        AutoPilotInterval: 5 * time.Second,
+    },
+    // เพื่อนร่วมทีมเสียเปรียบถ้าปล่อยให้ยอมแพ้ จึงให้ระบบเล่นแทนเร็วกว่าปกติ
+    ModeDoubleUp: {
+        IdleTimeout:       90 * time.Second,
+        DisconnectTimeout: 45 * time.Second,
+        Action:            AFKAutoPilot,
+        AutoPilotInterval: 5 * time.Second,
+    },
+}
+
+func (m *GameManager) SetAFKConfig(mode GameMode, cfg AFKConfig) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.afkConfigs[mode] = cfg
+}
+
+func (m *GameManager) afkConfig(mode GameMode) AFKConfig {
+    if cfg, exists := m.afkConfigs[mode]; exists {
+        return cfg
+    }
+    return DefaultAFKConfigs[ModeClassic]
+}
+
+// SetPlayerConnected ให้ handler แจ้งเมื่อผู้เล่นเชื่อมต่อหรือหลุดการเชื่อมต่อ
+func (m *GameManager) SetPlayerConnected(playerID string, connected bool) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if connected {
+        delete(m.disconnectedAt, playerID)
+        return
+    }
+    m.disconnectedAt[playerID] = time.Now()
+}
+
+// checkIdlePlayers ต้องถูกเรียกขณะถือ m.mu อยู่
+func (m *GameManager) checkIdlePlayers(now time.Time) {
+    for _, game := range m.games {
+        if game.Status != StatusPlaying {
+            continue
+        }
+
+        cfg := m.afkConfig(game.Mode)
+        changed := false
+
+        for _, p := range game.alivePlayers() {
+            if game.Status != StatusPlaying {
+                break
+            }
+            // บอทถูกจัดการใน runBots
+            if p.IsBot {
+                continue
+            }
+
+            if p.AutoPilot {
+                if now.Sub(p.lastAutoPilotAt) >= cfg.AutoPilotInterval {
+                    changed = m.runAutoPilot(game, p, now) || changed
+                }
+                continue
+            }
+
+            if !m.isAFK(p, cfg, now) {
+                continue
+            }
+
+            actionType := ActionAutoPilot
+            if cfg.Action == AFKForfeit {
+                actionType = ActionForfeit
+            }
+
+            err := m.applyAction(game, GameAction{
+                Type:      actionType,
+                PlayerID:  p.ID,
+                Timestamp: now,
+            })
+            changed = err == nil || changed
+        }
+
+        if changed && m.onGameUpdate != nil {
+            m.onGameUpdate(game)
+        }
+    }
+}
+
+func (m *GameManager) isAFK(p *Player, cfg AFKConfig, now time.Time) bool {
+    if cfg.IdleTimeout > 0 && now.Sub(p.LastActiveAt) > cfg.IdleTimeout {
+        return true
+    }
+
+    // นับเวลาหลุดจากเวลาที่ล่าสุดระหว่างตอนหลุดกับ action สุดท้าย
+    disconnectedAt, disconnected := m.disconnectedAt[p.ID]
+    if !disconnected || cfg.DisconnectTimeout <= 0 {
+        return false
+    }
+    if p.LastActiveAt.After(disconnectedAt) {
+        disconnectedAt = p.LastActiveAt
+    }
+    return now.Sub(disconnectedAt) > cfg.DisconnectTimeout
+}
+
+// runAutoPilot เล่นแทนผู้เล่นที่ AFK ด้วยกลยุทธ์เดียวกับบอทแบบ greedy
+func (m *GameManager) runAutoPilot(game *Game, player *Player, now time.Time) bool {
+    strategy, _ := botStrategy(BotGreedy)
+    return m.runBot(game, player, strategy, now)
+}
\ No newline at end of file
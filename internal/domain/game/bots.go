@@ -0,0 +1,275 @@
+package game
+
+import (
+    "fmt"
+    "math/rand"
+    "sort"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+type BotDifficulty string
+
+const (
+    BotRandom    BotDifficulty = "random"    // สุ่มซื้อสุ่มตี
+    BotGreedy    BotDifficulty = "greedy"    // ใช้เงินให้หมดก่อนแล้วค่อยตีตัวที่เลือดน้อยสุด
+    BotStrategic BotDifficulty = "strategic" // ดูค่าสถานะของคู่แข่งแล้วเลือกของและเป้าหมาย
+
+    // ระยะห่างระหว่าง action ของบอทแต่ละตัว
+    botActionInterval = 3 * time.Second
+)
+
+// BotStrategy ตัดสินใจ action ถัดไปของผู้เล่นที่ระบบเล่นแทน
+// คืน false ถ้าไม่มีอะไรให้ทำในตอนนี้
+type BotStrategy interface {
+    NextAction(game *Game, self *Player, rng *rand.Rand) (GameAction, bool)
+}
+
+// botStrategies ลงทะเบียนเพิ่มได้ตลอด จึงต้องอ่านและเขียนผ่าน botStrategiesMu
+var botStrategiesMu sync.RWMutex
+var botStrategies = map[BotDifficulty]BotStrategy{
+    BotRandom:    randomStrategy{},
+    BotGreedy:    greedyStrategy{},
+    BotStrategic: strategicStrategy{},
+}
+
+// RegisterBotStrategy เพิ่มหรือแทนที่ระดับความยากของบอท
+func RegisterBotStrategy(difficulty BotDifficulty, strategy BotStrategy) {
+    botStrategiesMu.Lock()
+    defer botStrategiesMu.Unlock()
+    botStrategies[difficulty] = strategy
+}
+
+// botStrategy คืนกลยุทธ์ของระดับความยาก
+func botStrategy(difficulty BotDifficulty) (BotStrategy, bool) {
+    botStrategiesMu.RLock()
+    defer botStrategiesMu.RUnlock()
+    strategy, exists := botStrategies[difficulty]
+    return strategy, exists
+}
+
+var botSeq atomic.Int64
+
+func newBot(difficulty BotDifficulty, settings GameSettings) *Player {
+    n := botSeq.Add(1)
+    return &Player{
+        ID:            fmt.Sprintf("bot_%d", n),
+        Username:      fmt.Sprintf("Bot %d (%s)", n, difficulty),
+        Health:        settings.StartingHealth,
+        Gold:          settings.StartingGold,
+        Level:         1,
+        Attack:        settings.StartingAttack,
+        Defense:       settings.StartingDefense,
+        Ready:         true,
+        IsBot:         true,
+        BotDifficulty: difficulty,
+        LastActiveAt:  time.Now(),
+    }
+}
+
+// AddBot ให้ host เพิ่มบอทเข้าล็อบบี้
+func (m *GameManager) AddBot(gameID string, hostID string, difficulty BotDifficulty) (*Player, error) {
+    if _, exists := botStrategy(difficulty); !exists {
+        return nil, ErrUnknownBotDifficulty
+    }
+
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    game, err := m.hostLobby(gameID, hostID)
+    if err != nil {
+        return nil, err
+    }
+
+    bot := newBot(difficulty, game.Settings)
+    if err := m.addPlayer(game, bot); err != nil {
+        return nil, err
+    }
+    return bot, nil
+}
+
+// SetBotBackfill ให้ AutoMatch เติมบอทในล็อบบี้ที่รอนานเกิน wait ใส่ 0 เพื่อปิด
+func (m *GameManager) SetBotBackfill(wait time.Duration, difficulty BotDifficulty) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.botBackfillAfter = wait
+    m.botBackfillDifficulty = difficulty
+}
+
+// backfillBots ต้องเรียกขณะถือ m.mu อยู่
+func (m *GameManager) backfillBots(now time.Time) {
+    if m.botBackfillAfter <= 0 {
+        return
+    }
+
+    for _, game := range m.games {
+        if !game.BotBackfill || !game.isOpen() || now.Sub(game.CreatedAt) < m.botBackfillAfter {
+            continue
+        }
+        // addPlayer จะเริ่มเกมให้เองเมื่อคนครบ
+        for game.Status == StatusWaiting && len(game.Players) < game.Settings.MaxPlayers {
+            if err := m.addPlayer(game, newBot(m.botBackfillDifficulty, game.Settings)); err != nil {
+                break
+            }
+        }
+    }
+}
+
+// runBots ให้บอทในเกมที่กำลังเล่นทำ action ต้องเรียกขณะถือ m.mu อยู่
+func (m *GameManager) runBots(now time.Time) {
+    for _, game := range m.games {
+        changed := false
+        for _, p := range game.alivePlayers() {
+            if game.Status != StatusPlaying {
+                break
+            }
+            if !p.IsBot || now.Sub(p.lastAutoPilotAt) < botActionInterval {
+                continue
+            }
+            strategy, _ := botStrategy(p.BotDifficulty)
+            changed = m.runBot(game, p, strategy, now) || changed
+        }
+
+        if changed && m.onGameUpdate != nil {
+            m.onGameUpdate(game)
+        }
+    }
+}
+
+// runBot ส่ง action ที่ strategy เลือกเข้า applyAction เหมือนผู้เล่นจริง
+func (m *GameManager) runBot(game *Game, player *Player, strategy BotStrategy, now time.Time) bool {
+    player.lastAutoPilotAt = now
+    if strategy == nil {
+        strategy, _ = botStrategy(BotGreedy)
+    }
+
+    action, ok := strategy.NextAction(game, player, game.random())
+    if !ok {
+        return false
+    }
+    action.PlayerID = player.ID
+    action.Timestamp = now
+
+    return m.applyAction(game, action) == nil
+}
+
+func (g *Game) random() *rand.Rand {
+    if g.rng == nil {
+        g.rng = rand.New(rand.NewSource(g.Seed))
+    }
+    return g.rng
+}
+
+// opponentsOf คืนคู่แข่งที่ยังไม่ตกรอบของผู้เล่น ไม่นับเพื่อนร่วมทีม
+func (g *Game) opponentsOf(self *Player) []*Player {
+    var opponents []*Player
+    for _, p := range g.alivePlayers() {
+        if p != self && (self.TeamID == "" || p.TeamID != self.TeamID) {
+            opponents = append(opponents, p)
+        }
+    }
+    return opponents
+}
+
+func buyAction(itemID string) GameAction {
+    return GameAction{Type: ActionBuyItem, ItemID: itemID}
+}
+
+func attackAction(target *Player) GameAction {
+    return GameAction{Type: ActionAttack, TargetID: target.ID}
+}
+
+func affordable(game *Game, self *Player, itemID string) bool {
+    item, exists := game.items()[itemID]
+    return exists && self.Gold >= item.Cost
+}
+
+type randomStrategy struct{}
+
+func (randomStrategy) NextAction(game *Game, self *Player, rng *rand.Rand) (GameAction, bool) {
+    var choices []string
+    for id := range game.items() {
+        if affordable(game, self, id) {
+            choices = append(choices, id)
+        }
+    }
+    opponents := game.opponentsOf(self)
+
+    if len(choices) > 0 && (len(opponents) == 0 || rng.Intn(2) == 0) {
+        // เรียงก่อนสุ่มเพื่อให้ผลเหมือนเดิมเมื่อใช้ seed เดิม
+        sort.Strings(choices)
+        return buyAction(choices[rng.Intn(len(choices))]), true
+    }
+    if len(opponents) == 0 {
+        return GameAction{}, false
+    }
+    return attackAction(opponents[rng.Intn(len(opponents))]), true
+}
+
+type greedyStrategy struct{}
+
+// NextAction ใช้เงินเพิ่มค่าสถานะที่ต่ำกว่าจนหมด แล้วค่อยตีตัวที่เลือดน้อยที่สุด
+func (greedyStrategy) NextAction(game *Game, self *Player, rng *rand.Rand) (GameAction, bool) {
+    itemID := "sword"
+    if self.Defense < self.Attack {
+        itemID = "shield"
+    }
+    if affordable(game, self, itemID) {
+        return buyAction(itemID), true
+    }
+
+    var target *Player
+    for _, p := range game.opponentsOf(self) {
+        if target == nil || p.Health < target.Health {
+            target = p
+        }
+    }
+    if target == nil {
+        return GameAction{}, false
+    }
+    return attackAction(target), true
+}
+
+type strategicStrategy struct{}
+
+// NextAction ดูค่าเฉลี่ยของคู่แข่งเพื่อเลือกของที่คุ้มที่สุด
+// และตีตัวที่จะตายเร็วที่สุดจากความเสียหายที่ทำได้จริง
+func (strategicStrategy) NextAction(game *Game, self *Player, rng *rand.Rand) (GameAction, bool) {
+    opponents := game.opponentsOf(self)
+    if len(opponents) == 0 {
+        return GameAction{}, false
+    }
+
+    var oppAttack, oppDefense int
+    for _, p := range opponents {
+        oppAttack += p.Attack
+        oppDefense += p.Defense
+    }
+    oppAttack /= len(opponents)
+    oppDefense /= len(opponents)
+
+    // เลือดเหลือน้อยกว่าครึ่งให้กินยาก่อน
+    if self.Health*2 < game.Settings.StartingHealth && affordable(game, self, "potion") {
+        return buyAction("potion"), true
+    }
+    // ถ้าเกราะคู่แข่งทำให้ตีเข้าน้อย ให้เพิ่มดาบ ถ้าโดนตีแรงกว่าที่ตีได้ ให้เพิ่มโล่
+    if game.rules().Damage(oppAttack, self.Defense) > game.rules().Damage(self.Attack, oppDefense) {
+        if affordable(game, self, "shield") {
+            return buyAction("shield"), true
+        }
+    } else if affordable(game, self, "sword") {
+        return buyAction("sword"), true
+    }
+
+    var target *Player
+    bestHits := 0
+    for _, p := range opponents {
+        damage := game.rules().Damage(self.Attack, p.Defense)
+        hits := (p.Health + damage - 1) / damage
+        if target == nil || hits < bestHits {
+            target, bestHits = p, hits
+        }
+    }
+    return attackAction(target), true
+}
\ No newline at end of file
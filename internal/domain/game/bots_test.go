@@ -0,0 +1,76 @@
+package game
+
+import (
+    "testing"
+    "time"
+)
+
+func TestBots(t *testing.T) {
+    t.Run("Bots Play Through Action Pipeline", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice")
+
+        game, err := gm.CreateGame(ids[0], LobbyOptions{Settings: settingsWithMaxPlayers(4)})
+        if err != nil {
+            t.Fatalf("failed to create game: %v", err)
+        }
+        for _, difficulty := range []BotDifficulty{BotRandom, BotGreedy, BotStrategic} {
+            if _, err := gm.AddBot(game.ID, ids[0], difficulty); err != nil {
+                t.Fatalf("failed to add %s bot: %v", difficulty, err)
+            }
+        }
+        if game.Status != StatusPlaying {
+            t.Fatalf("expected full lobby to start, got %s", game.Status)
+        }
+
+        // ให้ alice ยอมแพ้ไปก่อน แล้วปล่อยให้บอทเล่นกันเองจนจบ
+        if err := gm.Surrender(game.ID, ids[0]); err != nil {
+            t.Fatalf("failed to surrender: %v", err)
+        }
+
+        now := time.Now()
+        for i := 0; i < 1000 && game.Status == StatusPlaying; i++ {
+            now = now.Add(botActionInterval)
+            gm.tick(now)
+        }
+
+        if game.Status != StatusFinished {
+            t.Fatalf("expected bots to finish the game, got %s", game.Status)
+        }
+        placements := map[int]bool{}
+        for _, p := range game.Players {
+            placements[p.Placement] = true
+        }
+        if len(placements) != 4 {
+            t.Errorf("expected 4 distinct placements, got %v", placements)
+        }
+    })
+
+    t.Run("Unknown Difficulty", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice")
+        game, _ := gm.CreateGame(ids[0], LobbyOptions{})
+
+        if _, err := gm.AddBot(game.ID, ids[0], "impossible"); err != ErrUnknownBotDifficulty {
+            t.Errorf("expected %v, got %v", ErrUnknownBotDifficulty, err)
+        }
+    })
+
+    t.Run("AutoMatch Backfill", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice")
+        gm.SetBotBackfill(10*time.Second, BotGreedy)
+
+        game, err := gm.AutoMatch(ids[0])
+        if err != nil {
+            t.Fatalf("failed to auto match: %v", err)
+        }
+
+        gm.tick(time.Now().Add(5 * time.Second))
+        if len(game.Players) != 1 {
+            t.Fatalf("expected no backfill before wait, got %d players", len(game.Players))
+        }
+
+        gm.tick(time.Now().Add(11 * time.Second))
+        if game.Status != StatusPlaying || !game.Players[1].IsBot {
+            t.Errorf("expected bot backfill to start the game, got %s", game.Status)
+        }
+    })
+}
\ No newline at end of file
@@ -5,5 +5,21 @@ import "errors"
 var (
     ErrGameNotFound        = errors.New("game not found")
     ErrGameNotJoinable     = errors.New("game is not joinable")
+    ErrGameNotPlaying      = errors.New("game is not in playing state")
     ErrPlayerAlreadyInGame = errors.New("player is already in game")
+    ErrPlayerEliminated    = errors.New("player has been eliminated")
+    ErrPlayerNotInGame     = errors.New("player is not in game")
+    ErrLobbyFull           = errors.New("lobby is full")
+    ErrNotHost             = errors.New("only the host can do this")
+    ErrInvalidPassword     = errors.New("invalid lobby password")
+    ErrPlayersNotReady     = errors.New("not all players are ready")
+    ErrNotEnoughPlayers    = errors.New("not enough players to start")
+    ErrInvalidLobbySize    = errors.New("invalid lobby size")
+    ErrInvalidSettings     = errors.New("invalid game settings")
+    ErrUnknownBotDifficulty = errors.New("unknown bot difficulty")
+    ErrUnknownMode         = errors.New("unknown game mode")
+    ErrUnevenTeams         = errors.New("players cannot be split into full teams")
+    ErrNotTeammate         = errors.New("target is not a teammate")
+    ErrAttackTeammate      = errors.New("cannot attack a teammate")
+    ErrItemNotTransferable = errors.New("item cannot be sent")
 )
\ No newline at end of file
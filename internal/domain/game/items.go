@@ -46,35 +46,37 @@ var DefaultItems = map[string]Item{  // เปลี่ยนจาก *Item เ
 }
 
 // แก้ไขเมธอดให้สอดคล้องกับ types ที่เปลี่ยน
-func (m *GameManager) GetAvailableItems() []Item {  // เปลี่ยนจาก []*Item เป็น []Item
-    items := make([]Item, 0, len(DefaultItems))
-    for _, item := range DefaultItems {
+func (m *GameManager) GetAvailableItems(pack string) ([]Item, error) {  // เปลี่ยนจาก []*Item เป็น []Item
+    packItems, exists := ContentPackItems(pack)
+    if !exists {
+        return nil, ErrItemNotFound
+    }
+
+    items := make([]Item, 0, len(packItems))
+    for _, item := range packItems {
         items = append(items, item)
     }
-    return items
+    return items, nil
 }
 
+// BuyItem ซื้อไอเทมผ่าน action buy_item เหมือน action อื่นของผู้เล่น
 func (m *GameManager) BuyItem(gameID string, playerID string, itemID string) error {
-    m.mu.Lock()
-    defer m.mu.Unlock()
-
-    game, exists := m.games[gameID]
-    if !exists {
-        return ErrGameNotFound
-    }
+    return m.ProcessAction(gameID, GameAction{
+        Type:      ActionBuyItem,
+        PlayerID:  playerID,
+        ItemID:    itemID,
+        Timestamp: time.Now(),
+    })
+}
 
-    var player *Player
-    for _, p := range game.Players {
-        if p.ID == playerID {
-            player = p
-            break
-        }
-    }
-    if player == nil {
-        return ErrPlayerNotFound
+// buyItem หักเงินและใส่ไอเทมจาก content pack ของเกมให้ผู้เล่น ต้องผ่าน Game.apply เท่านั้น
+func buyItem(game *Game, player *Player, itemID string) error {
+    // ซื้อได้เฉพาะตอนเล่นอยู่ ของที่ซื้อในห้องรอจะติดไปตอนเริ่มเกม
+    if game.Status != StatusPlaying {
+        return ErrGameNotPlaying
     }
 
-    item, exists := DefaultItems[itemID]
+    item, exists := game.items()[itemID]
     if !exists {
         return ErrItemNotFound
     }
@@ -85,27 +87,19 @@ func (m *GameManager) BuyItem(gameID string, playerID string, itemID string) err
 
     // หักเงินและเพิ่มไอเทม
     player.Gold -= item.Cost
+    player.GoldSpent += item.Cost
     player.Inventory = append(player.Inventory, item)  // เปลี่ยนจาก Items เป็น Inventory
 
     // อัพเดทค่าสถานะตามไอเทม
     player.Attack += item.Attack
     player.Defense += item.Defense
     if item.Type == ItemTypePotion {  // แก้ให้ตรงกับ constant ใน types.go
-        player.Health = min(100, player.Health+item.Health)
-    }
-
-    game.UpdatedAt = time.Now()
-
-    // เพิ่มประวัติการซื้อไอเทม
-    game.Actions = append(game.Actions, GameAction{
-        Type:      ActionBuyItem,  // ใช้ constant จาก types.go
-        PlayerID:  playerID,
-        ItemID:    itemID,
-        Timestamp: time.Now(),
-    })
-
-    if m.onGameUpdate != nil {
-        m.onGameUpdate(game)
+        // โหมดทีมรักษาเลือดรวมของทั้งทีม
+        if team := game.teamOf(player); team != nil {
+            game.setTeamHealth(team, min(game.Settings.StartingHealth, team.Health+item.Health))
+        } else {
+            player.Health = min(game.Settings.StartingHealth, player.Health+item.Health)
+        }
     }
 
     return nil
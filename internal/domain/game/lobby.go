@@ -0,0 +1,199 @@
+package game
+
+import (
+    "context"
+    "math/rand"
+    "strings"
+    "time"
+)
+
+const (
+    DefaultMaxPlayers = 2
+    MinLobbySize      = 2
+    MaxLobbySize      = 8
+
+    joinCodeLength = 6
+    // ไม่ใช้ตัวที่อ่านสับสนง่าย เช่น 0/O และ 1/I
+    joinCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// LobbyOptions คือค่าที่เลือกได้ตอนสร้างล็อบบี้
+// ถ้า Settings เป็นค่าว่างจะใช้ DefaultGameSettings
+type LobbyOptions struct {
+    Private  bool         `json:"private"`
+    Password string       `json:"password"`
+    Mode     GameMode     `json:"mode"`
+    Settings GameSettings `json:"settings"`
+}
+
+// isOpen บอกว่าเกมนี้แสดงใน GetWaitingGames และให้ AutoMatch หยิบไปได้หรือไม่
+func (g *Game) isOpen() bool {
+    return g.Status == StatusWaiting &&
+        !g.Private &&
+        len(g.Players) > 0 &&
+        len(g.Players) < g.Settings.MaxPlayers
+}
+
+// generateJoinCode ต้องเรียกขณะถือ m.mu อยู่
+func (m *GameManager) generateJoinCode() string {
+    for {
+        code := make([]byte, joinCodeLength)
+        for i := range code {
+            code[i] = joinCodeAlphabet[rand.Intn(len(joinCodeAlphabet))]
+        }
+        if m.findByJoinCode(string(code)) == nil {
+            return string(code)
+        }
+    }
+}
+
+func (m *GameManager) findByJoinCode(code string) *Game {
+    for _, game := range m.games {
+        if game.Private && game.JoinCode == code {
+            return game
+        }
+    }
+    return nil
+}
+
+// JoinByCode เข้าล็อบบี้ส่วนตัวด้วยโค้ดและรหัสผ่าน (ถ้ามี)
+func (m *GameManager) JoinByCode(code string, password string, playerID string) (*Game, error) {
+    player, err := m.playerRepo.GetByID(context.Background(), playerID)
+    if err != nil {
+        return nil, err
+    }
+
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    game := m.findByJoinCode(strings.ToUpper(strings.TrimSpace(code)))
+    if game == nil {
+        return nil, ErrGameNotFound
+    }
+    if game.Password != "" && game.Password != password {
+        return nil, ErrInvalidPassword
+    }
+
+    if err := m.addPlayer(game, newPlayer(player, game.Settings)); err != nil {
+        return nil, err
+    }
+    return game, nil
+}
+
+// hostLobby หาล็อบบี้ที่ยังรออยู่และตรวจว่าผู้เรียกเป็น host ต้องเรียกขณะถือ m.mu อยู่
+func (m *GameManager) hostLobby(gameID string, hostID string) (*Game, error) {
+    game, exists := m.games[gameID]
+    if !exists {
+        return nil, ErrGameNotFound
+    }
+    if game.HostID != hostID {
+        return nil, ErrNotHost
+    }
+    if game.Status != StatusWaiting {
+        return nil, ErrGameNotJoinable
+    }
+    return game, nil
+}
+
+// KickPlayer ให้ host เตะผู้เล่นออกจากล็อบบี้
+func (m *GameManager) KickPlayer(gameID string, hostID string, targetID string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    game, err := m.hostLobby(gameID, hostID)
+    if err != nil {
+        return err
+    }
+    if targetID == hostID || game.findPlayer(targetID) == nil {
+        return ErrPlayerNotInGame
+    }
+
+    removePlayer(game, targetID)
+    game.UpdatedAt = time.Now()
+
+    if m.onGameUpdate != nil {
+        m.onGameUpdate(game)
+    }
+    return nil
+}
+
+// SetMaxPlayers ให้ host ปรับจำนวนช่องในล็อบบี้ ต้องไม่น้อยกว่าคนที่อยู่แล้ว
+func (m *GameManager) SetMaxPlayers(gameID string, hostID string, maxPlayers int) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    game, err := m.hostLobby(gameID, hostID)
+    if err != nil {
+        return err
+    }
+    if maxPlayers < MinLobbySize || maxPlayers > MaxLobbySize || maxPlayers < len(game.Players) {
+        return ErrInvalidLobbySize
+    }
+    settings := game.Settings
+    settings.MaxPlayers = maxPlayers
+    if err := game.rules().ValidateSettings(settings); err != nil {
+        return err
+    }
+
+    game.Settings.MaxPlayers = maxPlayers
+    game.UpdatedAt = time.Now()
+
+    if m.onGameUpdate != nil {
+        m.onGameUpdate(game)
+    }
+    return nil
+}
+
+// SetReady ให้ผู้เล่นกดพร้อมหรือยกเลิกพร้อม
+func (m *GameManager) SetReady(gameID string, playerID string, ready bool) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    game, exists := m.games[gameID]
+    if !exists {
+        return ErrGameNotFound
+    }
+    if game.Status != StatusWaiting {
+        return ErrGameNotJoinable
+    }
+
+    player := game.findPlayer(playerID)
+    if player == nil {
+        return ErrPlayerNotInGame
+    }
+
+    player.Ready = ready
+    game.UpdatedAt = time.Now()
+
+    if m.onGameUpdate != nil {
+        m.onGameUpdate(game)
+    }
+    return nil
+}
+
+// StartGame ให้ host เริ่มเกมได้เมื่อทุกคนยกเว้น host กดพร้อมแล้ว
+func (m *GameManager) StartGame(gameID string, hostID string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    game, err := m.hostLobby(gameID, hostID)
+    if err != nil {
+        return err
+    }
+    if err := game.rules().CanStart(len(game.Players)); err != nil {
+        return err
+    }
+    for _, p := range game.Players {
+        if p.ID != hostID && !p.Ready {
+            return ErrPlayersNotReady
+        }
+    }
+
+    startGame(game)
+    game.UpdatedAt = time.Now()
+
+    if m.onGameUpdate != nil {
+        m.onGameUpdate(game)
+    }
+    return nil
+}
\ No newline at end of file
@@ -4,6 +4,7 @@ import (
     "context"  
     "fmt"
     "sync"
+    "sync/atomic"
     "time"
     "github.com/tem-mars/tft-game-server/internal/repository"  // เพิ่ม import
 )
@@ -13,13 +14,28 @@ type GameManager struct {
     games      map[string]*Game
     playerRepo repository.PlayerRepository
     onGameUpdate func(*Game) 
+    onGameFinished []func(*Game)
+    onGameRemoved  []func(*Game)
+
+    afkConfigs     map[GameMode]AFKConfig
+    disconnectedAt map[string]time.Time // key: playerID
+
+    botBackfillAfter      time.Duration
+    botBackfillDifficulty BotDifficulty
 }
 
 func NewGameManager(playerRepo repository.PlayerRepository) *GameManager {
+    afkConfigs := make(map[GameMode]AFKConfig, len(DefaultAFKConfigs))
+    for mode, cfg := range DefaultAFKConfigs {
+        afkConfigs[mode] = cfg
+    }
+
     return &GameManager{
         games:      make(map[string]*Game),
         playerRepo: playerRepo,
         onGameUpdate: func(*Game) {}, // default empty function
+        afkConfigs:     afkConfigs,
+        disconnectedAt: make(map[string]time.Time),
     }
 }
 
@@ -29,30 +45,77 @@ func (m *GameManager) SetOnGameUpdate(callback func(*Game)) {
     m.onGameUpdate = callback
 }
 
-func (m *GameManager) CreateGame(playerID string) (*Game, error) {
+// AddOnGameFinished เพิ่ม callback ที่จะถูกเรียกครั้งเดียวเมื่อเกมจบและทุกคนได้อันดับแล้ว
+func (m *GameManager) AddOnGameFinished(callback func(*Game)) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.onGameFinished = append(m.onGameFinished, callback)
+}
+
+// AddOnGameRemoved เพิ่ม callback ที่จะถูกเรียกเมื่อเกมถูกลบออกเพราะไม่มีใครใช้แล้ว
+// ถูกเรียกขณะถือ lock ของ GameManager จึงห้ามเรียกเมธอดของ GameManager ใน callback
+func (m *GameManager) AddOnGameRemoved(callback func(*Game)) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.onGameRemoved = append(m.onGameRemoved, callback)
+}
+
+// Run เดินเวลาของเกมทุกวินาทีจนกว่า ctx จะถูกยกเลิก
+func (m *GameManager) Run(ctx context.Context) {
+    ticker := time.NewTicker(time.Second)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case now := <-ticker.C:
+            m.tick(now)
+        }
+    }
+}
+
+func (m *GameManager) tick(now time.Time) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    m.advancePhases(now)
+    m.backfillBots(now)
+    m.runBots(now)
+    m.checkIdlePlayers(now)
+    m.cleanupInactiveGames()
+}
+
+func (m *GameManager) CreateGame(playerID string, opts LobbyOptions) (*Game, error) {
+    rules, err := RuleSetFor(opts.Mode)
+    if err != nil {
+        return nil, err
+    }
+    if opts.Settings == (GameSettings{}) {
+        opts.Settings = rules.DefaultSettings()
+    }
+    if err := rules.ValidateSettings(opts.Settings); err != nil {
+        return nil, err
+    }
+
     // ดึงข้อมูล player จาก repository
     player, err := m.playerRepo.GetByID(context.Background(), playerID)
     if err != nil {
         return nil, err
     }
 
-    game := &Game{
-        ID:     generateGameID(),
-        Status: "waiting",  // ใช้ string แทน constant
-        Players: []*Player{{
-            ID:       playerID,
-            Username: player.Username,
-            Health:   100,
-            Gold:     player.Stats.Gold,
-            Level:    player.Stats.Level,
-            Attack:   10,
-            Defense:  5,
-        }},
-        CreatedAt: time.Now(),
-        UpdatedAt: time.Now(),
-    }
+    game := NewGame(generateGameID())
+    game.HostID = playerID
+    game.Mode = rules.Mode()
+    game.Settings = opts.Settings
+    game.Players = append(game.Players, newPlayer(player, game.Settings))
 
     m.mu.Lock()
+    if opts.Private {
+        game.Private = true
+        game.Password = opts.Password
+        game.JoinCode = m.generateJoinCode()
+    }
     m.games[game.ID] = game
     m.mu.Unlock()
 
@@ -73,27 +136,38 @@ func (m *GameManager) JoinGame(gameID string, playerID string) error {
         return ErrGameNotFound
     }
 
+    // ล็อบบี้ส่วนตัวต้องเข้าด้วยโค้ดเท่านั้น
+    if game.Private {
+        return ErrGameNotJoinable
+    }
+
+    return m.addPlayer(game, newPlayer(player, game.Settings))
+}
+
+// addPlayer เพิ่มผู้เล่นหรือบอทเข้าล็อบบี้ ต้องเรียกขณะถือ m.mu อยู่
+func (m *GameManager) addPlayer(game *Game, player *Player) error {
+    if game.Status != StatusWaiting {
+        return ErrGameNotJoinable
+    }
+
     // เช็คว่าผู้เล่นอยู่ในเกมแล้วหรือไม่
-    for _, p := range game.Players {
-        if p.ID == playerID {
-            return ErrPlayerAlreadyInGame
-        }
+    if game.findPlayer(player.ID) != nil {
+        return ErrPlayerAlreadyInGame
+    }
+
+    if len(game.Players) >= game.Settings.MaxPlayers {
+        return ErrLobbyFull
     }
 
     // เพิ่มผู้เล่นใหม่
-    game.Players = append(game.Players, &Player{
-        ID:       playerID,
-        Username: player.Username,
-        Health:   100,
-        Gold:     player.Stats.Gold,
-        Level:    player.Stats.Level,
-        Attack:   10,
-        Defense:  5,
-    })
+    game.Players = append(game.Players, player)
+    if game.HostID == "" && !player.IsBot {
+        game.HostID = player.ID
+    }
 
-    // อัพเดทสถานะเกม
-    if len(game.Players) == 2 {
-        game.Status = "playing"
+    // ล็อบบี้สาธารณะเริ่มเองเมื่อคนครบ ล็อบบี้ส่วนตัวรอ host กดเริ่ม
+    if !game.Private && len(game.Players) == game.Settings.MaxPlayers {
+        startGame(game)
     }
 
     game.UpdatedAt = time.Now()
@@ -106,6 +180,114 @@ func (m *GameManager) JoinGame(gameID string, playerID string) error {
     return nil
 }
 
+// CreateMatch สร้างเกมจากกลุ่มผู้เล่นที่ matchmaking จับคู่ให้และเริ่มเล่นทันที
+func (m *GameManager) CreateMatch(playerIDs []string) (*Game, error) {
+    if len(playerIDs) < MinLobbySize || len(playerIDs) > MaxLobbySize {
+        return nil, ErrInvalidLobbySize
+    }
+
+    game := NewGame(generateGameID())
+    game.HostID = playerIDs[0]
+    game.Ranked = true
+    game.Settings.MaxPlayers = len(playerIDs)
+
+    for _, playerID := range playerIDs {
+        player, err := m.playerRepo.GetByID(context.Background(), playerID)
+        if err != nil {
+            return nil, err
+        }
+        game.Players = append(game.Players, newPlayer(player, game.Settings))
+    }
+
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    startGame(game)
+    m.games[game.ID] = game
+
+    if m.onGameUpdate != nil {
+        m.onGameUpdate(game)
+    }
+
+    return game, nil
+}
+
+// LeaveGame ออกจากเกม ถ้ายังรออยู่จะลบผู้เล่นออกจากล็อบบี้
+// ถ้าเกมเริ่มแล้วจะถือว่ายอมแพ้
+func (m *GameManager) LeaveGame(gameID string, playerID string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    game, exists := m.games[gameID]
+    if !exists {
+        return ErrGameNotFound
+    }
+    if game.findPlayer(playerID) == nil {
+        return ErrPlayerNotInGame
+    }
+
+    switch game.Status {
+    case StatusWaiting:
+        removePlayer(game, playerID)
+        game.UpdatedAt = time.Now()
+    case StatusPlaying:
+        err := m.applyAction(game, GameAction{
+            Type:      ActionSurrender,
+            PlayerID:  playerID,
+            Timestamp: time.Now(),
+        })
+        if err != nil {
+            return err
+        }
+    default:
+        // เกมจบแล้ว ไม่ต้องทำอะไร
+        return nil
+    }
+
+    if m.onGameUpdate != nil {
+        m.onGameUpdate(game)
+    }
+
+    return nil
+}
+
+// Surrender ยอมแพ้ในเกมที่กำลังเล่นอยู่
+func (m *GameManager) Surrender(gameID string, playerID string) error {
+    return m.ProcessAction(gameID, GameAction{
+        Type:      ActionSurrender,
+        PlayerID:  playerID,
+        Timestamp: time.Now(),
+    })
+}
+
+// SendItem ส่งไอเทมให้เพื่อนร่วมทีมในโหมดทีม
+func (m *GameManager) SendItem(gameID, playerID, targetID, itemID string) error {
+    return m.ProcessAction(gameID, GameAction{
+        Type:      ActionSendItem,
+        PlayerID:  playerID,
+        TargetID:  targetID,
+        ItemID:    itemID,
+        Timestamp: time.Now(),
+    })
+}
+
+// removePlayer ลบผู้เล่นออกจากล็อบบี้และย้าย host ไปให้คนถัดไปถ้าจำเป็น
+func removePlayer(game *Game, playerID string) {
+    for i, p := range game.Players {
+        if p.ID == playerID {
+            game.Players = append(game.Players[:i], game.Players[i+1:]...)
+            break
+        }
+    }
+
+    if game.HostID != playerID {
+        return
+    }
+    game.HostID = ""
+    if len(game.Players) > 0 {
+        game.HostID = game.Players[0].ID
+    }
+}
 
 func (m *GameManager) GetGame(gameID string) (*Game, error) {
     m.mu.RLock()
@@ -119,8 +301,67 @@ func (m *GameManager) GetGame(gameID string) (*Game, error) {
     return game, nil
 }
 
+var gameSeq atomic.Int64
+
 func generateGameID() string {
-    return "game_" + time.Now().Format("20060102150405")
+    // ต่อท้ายด้วยลำดับเพื่อไม่ให้เกมที่สร้างในวินาทีเดียวกันชนกัน
+    return fmt.Sprintf("game_%s_%d", time.Now().Format("20060102150405"), gameSeq.Add(1))
+}
+
+func newPlayer(player *repository.Player, settings GameSettings) *Player {
+    return &Player{
+        ID:           player.ID,
+        Username:     player.Username,
+        Health:       settings.StartingHealth,
+        Gold:         settings.StartingGold,
+        Level:        player.Stats.Level,
+        Attack:       settings.StartingAttack,
+        Defense:      settings.StartingDefense,
+        LastActiveAt: time.Now(),
+    }
+}
+
+// startGame เปลี่ยนสถานะเป็น playing ตั้งค่าผู้เล่นตามกติกาของโหมด เริ่มรอบแรก และเริ่มนับเวลา AFK ใหม่ให้ทุกคน
+func startGame(game *Game) {
+    now := time.Now()
+    game.Status = StatusPlaying
+    game.StartedAt = now
+    game.Round = 1
+    game.Phase = PhasePlanning
+    game.PhaseEndsAt = now.Add(game.Settings.planningDuration())
+    for _, p := range game.Players {
+        p.LastActiveAt = now
+    }
+    game.rules().Setup(game)
+
+    game.startPlayers = clonePlayers(game.Players)
+    game.startAction = len(game.Actions)
+}
+
+// advancePhases สลับเฟส planning/combat เมื่อหมดเวลา ต้องเรียกขณะถือ m.mu อยู่
+func (m *GameManager) advancePhases(now time.Time) {
+    for _, game := range m.games {
+        if game.Status != StatusPlaying || now.Before(game.PhaseEndsAt) {
+            continue
+        }
+
+        // การเปลี่ยนเฟสถูกบันทึกเป็น action ของระบบเพื่อให้ replay เดินรอบได้ตรงกัน
+        action := GameAction{Type: ActionPhase, Round: game.Round, Phase: PhaseCombat, Timestamp: now}
+        duration := game.Settings.combatDuration()
+        if game.Phase == PhaseCombat {
+            action.Round++
+            action.Phase = PhasePlanning
+            duration = game.Settings.planningDuration()
+        }
+        if err := m.applyAction(game, action); err != nil {
+            continue
+        }
+        game.PhaseEndsAt = now.Add(duration)
+
+        if m.onGameUpdate != nil {
+            m.onGameUpdate(game)
+        }
+    }
 }
 
 func (m *GameManager) ProcessAction(gameID string, action GameAction) error {
@@ -132,66 +373,206 @@ func (m *GameManager) ProcessAction(gameID string, action GameAction) error {
         return ErrGameNotFound
     }
 
-    // ตรวจสอบว่าเกมกำลังเล่นอยู่
-    if game.Status != StatusPlaying {
-        return fmt.Errorf("game is not in playing state")
+    // action ของระบบส่งมาจากภายนอกไม่ได้
+    if action.Type == ActionPhase {
+        return fmt.Errorf("unknown action type: %s", action.Type)
     }
 
-    // หาผู้เล่นที่ทำการโจมตี
-    var attacker, target *Player
-    for _, p := range game.Players {
-        if p.ID == action.PlayerID {
-            attacker = p
+    // ผู้เล่นกลับมาเล่นเองแล้ว ไม่นับเป็น AFK
+    markActive(game, action.PlayerID)
+
+    if err := m.applyAction(game, action); err != nil {
+        return err
+    }
+
+    // ส่งอัพเดทให้ผู้เล่น
+    if m.onGameUpdate != nil {
+        m.onGameUpdate(game)
+    }
+
+    return nil
+}
+
+// applyAction เปลี่ยนสถานะเกมตาม action บันทึกลงประวัติ แล้วอัพเดทผลของคนที่ได้อันดับ
+// ใช้ร่วมกันทั้ง action จากผู้เล่น auto-pilot บอท และการเปลี่ยนเฟส ต้องเรียกขณะถือ m.mu อยู่
+func (m *GameManager) applyAction(game *Game, action GameAction) error {
+    placed, err := game.apply(action)
+    if err != nil {
+        return err
+    }
+
+    // เพิ่มประวัติการกระทำ ก่อนเรียก callback ตอนจบเกมเพื่อให้ replay ครบ
+    game.Actions = append(game.Actions, action)
+    game.UpdatedAt = time.Now()
+
+    if game.rules().Scored() {
+        for _, p := range placed {
+            m.recordResult(p)
         }
-        if p.ID == action.TargetID {
-            target = p
+    }
+    if len(placed) > 0 && game.Status == StatusFinished {
+        for _, callback := range m.onGameFinished {
+            callback(game)
         }
     }
 
-    if attacker == nil || target == nil {
-        return fmt.Errorf("player not found")
+    return nil
+}
+
+// apply เปลี่ยนสถานะของเกมตาม action อย่างเดียว ไม่แตะ repository หรือ callback
+// ใช้ทั้งตอนเล่นจริงและตอนเล่น replay คืนผู้เล่นที่ได้อันดับจาก action นี้
+func (g *Game) apply(action GameAction) ([]*Player, error) {
+    // ตรวจสอบว่าเกมกำลังเล่นอยู่
+    if g.Status != StatusPlaying {
+        return nil, ErrGameNotPlaying
+    }
+
+    if action.Type == ActionPhase {
+        g.applyPhase(action)
+        return nil, nil
+    }
+
+    player := g.findPlayer(action.PlayerID)
+    if player == nil {
+        return nil, ErrPlayerNotFound
+    }
+    if player.Eliminated {
+        return nil, ErrPlayerEliminated
     }
 
+    var placed []*Player
     switch action.Type {
     case ActionAttack:
+        target := g.findPlayer(action.TargetID)
+        if target == nil {
+            return nil, ErrPlayerNotFound
+        }
+        if target.Eliminated {
+            return nil, ErrPlayerEliminated
+        }
+
+        // โหมดทีมหักเลือดรวมของทีมเป้าหมาย และตีเพื่อนร่วมทีมไม่ได้
+        if team := g.teamOf(target); team != nil {
+            if target.TeamID == player.TeamID {
+                return nil, ErrAttackTeammate
+            }
+            placed = g.attackTeam(player, target, team, action.Timestamp)
+            break
+        }
+
         // คำนวณความเสียหาย
-        damage := calculateDamage(attacker.Attack, target.Defense)
+        damage := g.rules().Damage(player.Attack, target.Defense)
+        player.DamageDealt += min(damage, target.Health)
         target.Health -= damage
 
         // เช็คว่าผู้เล่นตายหรือไม่
         if target.Health <= 0 {
             target.Health = 0
-            game.Status = StatusFinished
+            placed = g.eliminate(target, action.Timestamp)
         }
 
-        // เพิ่มประวัติการกระทำ
-        game.Actions = append(game.Actions, action)
-        game.UpdatedAt = time.Now()
-
-        // ส่งอัพเดทให้ผู้เล่น
-        if m.onGameUpdate != nil {
-            m.onGameUpdate(game)
+    case ActionBuyItem:
+        if err := buyItem(g, player, action.ItemID); err != nil {
+            return nil, err
         }
 
-    case ActionBuyItem:
-        // TODO: Implement item purchase
-        return fmt.Errorf("buy item not implemented yet")
+    case ActionSendItem:
+        if err := g.sendItem(player, action.TargetID, action.ItemID); err != nil {
+            return nil, err
+        }
 
     case ActionUseItem:
         // TODO: Implement item usage
-        return fmt.Errorf("use item not implemented yet")
+        return nil, fmt.Errorf("use item not implemented yet")
+
+    case ActionForfeit, ActionSurrender:
+        placed = g.eliminate(player, action.Timestamp)
+
+    case ActionAutoPilot:
+        player.AutoPilot = true
+
+    default:
+        return nil, fmt.Errorf("unknown action type: %s", action.Type)
+    }
+
+    return placed, nil
+}
+
+// applyPhase เปลี่ยนเฟสตาม action ของระบบ เริ่มรอบใหม่จะแจกเงินตามกติกาให้คนที่ยังอยู่
+func (g *Game) applyPhase(action GameAction) {
+    if action.Phase == PhaseCombat {
+        g.Phase = PhaseCombat
+        return
+    }
+
+    g.Round = action.Round
+    g.Phase = PhasePlanning
+    income := g.rules().RoundIncome(g.Round)
+    for _, p := range g.alivePlayers() {
+        p.Gold += income
     }
+}
 
+// eliminate ตัดผู้เล่นออกและให้อันดับแย่สุดที่ยังเหลืออยู่
+// ถ้าเข้าเงื่อนไขชนะของโหมดจะจบเกมและให้คนที่เหลือได้ที่ 1 คืนผู้เล่นทุกคนที่ได้อันดับ
+func (g *Game) eliminate(player *Player, at time.Time) []*Player {
+    if player.Eliminated {
+        return nil
+    }
+    if team := g.teamOf(player); team != nil {
+        return g.leaveTeam(player, team, at)
+    }
+
+    alive := g.alivePlayers()
+    player.Eliminated = true
+    player.AutoPilot = false
+    player.Placement = len(alive)
+    player.LastRound = g.Round
+    placed := []*Player{player}
+
+    if g.rules().Finished(len(alive) - 1) {
+        for _, p := range alive {
+            if p != player {
+                p.Placement = 1
+                p.LastRound = g.Round
+                placed = append(placed, p)
+            }
+        }
+        g.Status = StatusFinished
+        g.FinishedAt = at
+    }
+    return placed
+}
+
+// recordResult อัพเดท Stats ใน repository ตามอันดับที่ได้
+func (m *GameManager) recordResult(player *Player) {
+    m.playerRepo.RecordPlacement(context.Background(), player.ID, player.Placement)
+}
+
+func markActive(game *Game, playerID string) {
+    if p := game.findPlayer(playerID); p != nil {
+        p.LastActiveAt = time.Now()
+        p.AutoPilot = false
+    }
+}
+
+func (g *Game) findPlayer(playerID string) *Player {
+    for _, p := range g.Players {
+        if p.ID == playerID {
+            return p
+        }
+    }
     return nil
 }
 
-// เพิ่มฟังก์ชันคำนวณความเสียหาย
-func calculateDamage(attack, defense int) int {
-    damage := attack - (defense / 2)
-    if damage < 1 {
-        damage = 1
+func (g *Game) alivePlayers() []*Player {
+    alive := make([]*Player, 0, len(g.Players))
+    for _, p := range g.Players {
+        if !p.Eliminated {
+            alive = append(alive, p)
+        }
     }
-    return damage
+    return alive
 }
 
 // เพิ่มเมธอดใหม่
@@ -201,7 +582,7 @@ func (m *GameManager) GetWaitingGames() []*Game {
 
     var waitingGames []*Game
     for _, game := range m.games {
-        if game.Status == "waiting" && len(game.Players) < 2 {
+        if game.isOpen() {
             waitingGames = append(waitingGames, game)
         }
     }
@@ -214,7 +595,7 @@ func (m *GameManager) AutoMatch(playerID string) (*Game, error) {
 
     // ค้นหาเกมที่รอผู้เล่น
     for _, game := range m.games {
-        if game.Status == "waiting" && len(game.Players) < 2 {
+        if game.isOpen() {
             // ตรวจสอบว่าผู้เล่นไม่ได้อยู่ในเกมนี้
             playerInGame := false
             for _, p := range game.Players {
@@ -232,22 +613,8 @@ func (m *GameManager) AutoMatch(playerID string) (*Game, error) {
                 }
 
                 // เพิ่มผู้เล่น
-                game.Players = append(game.Players, &Player{
-                    ID:       playerID,
-                    Username: player.Username,
-                    Health:   100,
-                    Gold:     player.Stats.Gold,
-                    Level:    player.Stats.Level,
-                    Attack:   10,
-                    Defense:  5,
-                })
-
-                game.Status = "playing"
-                game.UpdatedAt = time.Now()
-
-                // เรียก callback
-                if m.onGameUpdate != nil {
-                    m.onGameUpdate(game)
+                if err := m.addPlayer(game, newPlayer(player, game.Settings)); err != nil {
+                    return nil, err
                 }
 
                 return game, nil
@@ -261,21 +628,10 @@ func (m *GameManager) AutoMatch(playerID string) (*Game, error) {
         return nil, err
     }
 
-    game := &Game{
-        ID:     generateGameID(),
-        Status: "waiting",
-        Players: []*Player{{
-            ID:       playerID,
-            Username: player.Username,
-            Health:   100,
-            Gold:     player.Stats.Gold,
-            Level:    player.Stats.Level,
-            Attack:   10,
-            Defense:  5,
-        }},
-        CreatedAt: time.Now(),
-        UpdatedAt: time.Now(),
-    }
+    game := NewGame(generateGameID())
+    game.HostID = playerID
+    game.BotBackfill = true
+    game.Players = append(game.Players, newPlayer(player, game.Settings))
 
     m.games[game.ID] = game
 
@@ -290,19 +646,25 @@ func (m *GameManager) AutoMatch(playerID string) (*Game, error) {
 func (m *GameManager) cleanupInactiveGames() {
     now := time.Now()
     for id, game := range m.games {
+        var inactive bool
+        switch {
         // ลบเกมที่ไม่มีผู้เล่นเกิน 5 นาที
-        if len(game.Players) == 0 && now.Sub(game.UpdatedAt) > 5*time.Minute {
-            delete(m.games, id)
-            continue
-        }
+        case len(game.Players) == 0:
+            inactive = now.Sub(game.UpdatedAt) > 5*time.Minute
         // ลบเกมที่รอคนเล่นเกิน 10 นาที
-        if game.Status == StatusWaiting && now.Sub(game.UpdatedAt) > 10*time.Minute {
-            delete(m.games, id)
+        case game.Status == StatusWaiting:
+            inactive = now.Sub(game.UpdatedAt) > 10*time.Minute
+        // ลบเกมที่จบแล้วเกิน 30 นาที
+        case game.Status == StatusFinished:
+            inactive = now.Sub(game.UpdatedAt) > 30*time.Minute
+        }
+        if !inactive {
             continue
         }
-        // ลบเกมที่จบแล้วเกิน 30 นาที
-        if game.Status == StatusFinished && now.Sub(game.UpdatedAt) > 30*time.Minute {
-            delete(m.games, id)
+
+        delete(m.games, id)
+        for _, callback := range m.onGameRemoved {
+            callback(game)
         }
     }
-}
+}
\ No newline at end of file
@@ -1,71 +1,83 @@
 package game
 
 import (
+    "context"
+    "sync"
     "testing"
+    "time"
+
+    "github.com/tem-mars/tft-game-server/internal/repository"
 )
 
+// newTestManager สร้าง GameManager พร้อมผู้เล่นที่ลงทะเบียนไว้แล้วตามชื่อที่ให้มา
+func newTestManager(t *testing.T, usernames ...string) (*GameManager, []string) {
+    t.Helper()
+
+    repo := repository.NewMemoryPlayerRepository()
+    ids := make([]string, 0, len(usernames))
+    for _, username := range usernames {
+        player, err := repo.Create(context.Background(), username, username+"@example.com", "secret")
+        if err != nil {
+            t.Fatalf("failed to create player: %v", err)
+        }
+        ids = append(ids, player.ID)
+    }
+
+    return NewGameManager(repo), ids
+}
+
 func TestGameManager(t *testing.T) {
     t.Run("Create and Get Game", func(t *testing.T) {
-        gm := NewGameManager()
-        gameID := "test-game-1"
+        gm, ids := newTestManager(t, "alice")
 
         // Test CreateGame
-        game, err := gm.CreateGame(gameID)
+        game, err := gm.CreateGame(ids[0], LobbyOptions{})
         if err != nil {
             t.Fatalf("failed to create game: %v", err)
         }
-        if game.ID != gameID {
-            t.Errorf("expected game ID %s, got %s", gameID, game.ID)
+        if game.Status != StatusWaiting {
+            t.Errorf("expected status %s, got %s", StatusWaiting, game.Status)
         }
 
         // Test GetGame
-        fetchedGame, err := gm.GetGame(gameID)
+        fetchedGame, err := gm.GetGame(game.ID)
         if err != nil {
             t.Fatalf("failed to get game: %v", err)
         }
-        if fetchedGame.ID != gameID {
-            t.Errorf("expected game ID %s, got %s", gameID, fetchedGame.ID)
+        if fetchedGame.ID != game.ID {
+            t.Errorf("expected game ID %s, got %s", game.ID, fetchedGame.ID)
         }
     })
 
-    t.Run("Add Player", func(t *testing.T) {
-        gm := NewGameManager()
-        gameID := "test-game-1"
-        
+    t.Run("Join Game", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice", "bob")
+
         // Create game first
-        _, err := gm.CreateGame(gameID)
+        game, err := gm.CreateGame(ids[0], LobbyOptions{})
         if err != nil {
             t.Fatalf("failed to create game: %v", err)
         }
 
-        // Test adding player
-        player := &Player{
-            ID:       "player-1",
-            Username: "TestPlayer",
-            Health:   100,
-            Gold:     0,
-            Level:    1,
-        }
-
-        err = gm.AddPlayer(gameID, player)
-        if err != nil {
-            t.Fatalf("failed to add player: %v", err)
+        // Test joining
+        if err := gm.JoinGame(game.ID, ids[1]); err != nil {
+            t.Fatalf("failed to join game: %v", err)
         }
 
         // Verify player was added
-        game, _ := gm.GetGame(gameID)
-        if len(game.Players) != 1 {
-            t.Errorf("expected 1 player, got %d", len(game.Players))
+        game, _ = gm.GetGame(game.ID)
+        if len(game.Players) != 2 {
+            t.Errorf("expected 2 players, got %d", len(game.Players))
         }
-
-        addedPlayer := game.Players[player.ID]
-        if addedPlayer.Username != player.Username {
-            t.Errorf("expected username %s, got %s", player.Username, addedPlayer.Username)
+        if game.Players[1].Username != "bob" {
+            t.Errorf("expected username bob, got %s", game.Players[1].Username)
+        }
+        if game.Status != StatusPlaying {
+            t.Errorf("expected status %s, got %s", StatusPlaying, game.Status)
         }
     })
 
     t.Run("Game Not Found", func(t *testing.T) {
-        gm := NewGameManager()
+        gm, _ := newTestManager(t)
         
         _, err := gm.GetGame("non-existent")
         if err == nil {
@@ -73,20 +85,271 @@ func TestGameManager(t *testing.T) {
         }
     })
 
-    t.Run("Duplicate Game Creation", func(t *testing.T) {
-        gm := NewGameManager()
-        gameID := "test-game-1"
+    t.Run("Duplicate Join", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice")
 
-        // Create first game
-        _, err := gm.CreateGame(gameID)
+        game, err := gm.CreateGame(ids[0], LobbyOptions{})
         if err != nil {
-            t.Fatalf("failed to create first game: %v", err)
+            t.Fatalf("failed to create game: %v", err)
         }
 
-        // Try to create duplicate
-        _, err = gm.CreateGame(gameID)
-        if err == nil {
-            t.Error("expected error for duplicate game creation, got nil")
+        // Try to join own game again
+        if err := gm.JoinGame(game.ID, ids[0]); err != ErrPlayerAlreadyInGame {
+            t.Errorf("expected %v, got %v", ErrPlayerAlreadyInGame, err)
+        }
+    })
+
+    t.Run("Stale Lobby Is Removed", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice")
+
+        var removed []string
+        gm.AddOnGameRemoved(func(g *Game) { removed = append(removed, g.ID) })
+        game, _ := gm.CreateGame(ids[0], LobbyOptions{})
+        game.UpdatedAt = time.Now().Add(-11 * time.Minute)
+
+        gm.tick(time.Now())
+        if _, err := gm.GetGame(game.ID); err != ErrGameNotFound {
+            t.Errorf("expected %v, got %v", ErrGameNotFound, err)
+        }
+        if len(removed) != 1 || removed[0] != game.ID {
+            t.Errorf("expected removal callback for %s, got %v", game.ID, removed)
         }
     })
+}
+
+// startTestGame สร้างเกมที่เริ่มเล่นแล้วระหว่างผู้เล่นสองคน
+func startTestGame(t *testing.T, gm *GameManager, ids []string) *Game {
+    t.Helper()
+
+    game, err := gm.CreateGame(ids[0], LobbyOptions{})
+    if err != nil {
+        t.Fatalf("failed to create game: %v", err)
+    }
+    for _, id := range ids[1:] {
+        if err := gm.JoinGame(game.ID, id); err != nil {
+            t.Fatalf("failed to join game: %v", err)
+        }
+    }
+    return game
+}
+
+func TestAFKDetection(t *testing.T) {
+    t.Run("Idle Player Forfeits", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice", "bob")
+        gm.SetAFKConfig(ModeClassic, AFKConfig{IdleTimeout: time.Minute, Action: AFKForfeit})
+        game := startTestGame(t, gm, ids)
+
+        // bob เล่นอยู่ alice ไม่ได้ทำอะไรเลย
+        later := time.Now().Add(2 * time.Minute)
+        game.Players[1].LastActiveAt = later
+
+        gm.tick(later)
+
+        alice, bob := game.Players[0], game.Players[1]
+        if !alice.Eliminated || alice.Placement != 2 {
+            t.Errorf("expected alice eliminated in 2nd place, got eliminated=%v placement=%d", alice.Eliminated, alice.Placement)
+        }
+        if bob.Placement != 1 {
+            t.Errorf("expected bob in 1st place, got %d", bob.Placement)
+        }
+        if game.Status != StatusFinished {
+            t.Errorf("expected status %s, got %s", StatusFinished, game.Status)
+        }
+    })
+
+    t.Run("Disconnected Player Gets Auto-Pilot", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice", "bob")
+        gm.SetAFKConfig(ModeClassic, AFKConfig{
+            DisconnectTimeout: 30 * time.Second,
+            Action:            AFKAutoPilot,
+            AutoPilotInterval: time.Second,
+        })
+        game := startTestGame(t, gm, ids)
+
+        gm.SetPlayerConnected(ids[0], false)
+        later := time.Now().Add(time.Minute)
+        gm.tick(later)
+
+        alice := game.Players[0]
+        if !alice.AutoPilot {
+            t.Fatal("expected alice to be on auto-pilot")
+        }
+
+        // auto-pilot ต้องใช้เงินซื้อไอเทม
+        gold := alice.Gold
+        gm.tick(later.Add(2 * time.Second))
+        if alice.Gold >= gold || len(alice.Inventory) != 1 {
+            t.Errorf("expected auto-pilot to buy an item, gold %d -> %d", gold, alice.Gold)
+        }
+
+        // ผู้เล่นกลับมาเล่นเองจะยกเลิก auto-pilot
+        if err := gm.BuyItem(game.ID, ids[0], "potion"); err != nil {
+            t.Fatalf("failed to buy item: %v", err)
+        }
+        if alice.AutoPilot {
+            t.Error("expected auto-pilot to be cleared after player action")
+        }
+    })
+}
+func TestLeaveGame(t *testing.T) {
+    t.Run("Host Leaves Waiting Lobby", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice", "bob")
+
+        game, err := gm.CreateGame(ids[0], LobbyOptions{})
+        if err != nil {
+            t.Fatalf("failed to create game: %v", err)
+        }
+        // เพิ่ม bob เข้าไปตรงๆ เพื่อให้ล็อบบี้ยังไม่เริ่ม
+        game.Players = append(game.Players, &Player{ID: ids[1], Username: "bob"})
+
+        if err := gm.LeaveGame(game.ID, ids[0]); err != nil {
+            t.Fatalf("failed to leave game: %v", err)
+        }
+        if len(game.Players) != 1 || game.HostID != ids[1] {
+            t.Errorf("expected bob to be the only player and host, got host %s with %d players", game.HostID, len(game.Players))
+        }
+
+        if err := gm.LeaveGame(game.ID, ids[1]); err != nil {
+            t.Fatalf("failed to leave game: %v", err)
+        }
+        if len(game.Players) != 0 || game.HostID != "" {
+            t.Errorf("expected empty lobby, got host %q with %d players", game.HostID, len(game.Players))
+        }
+        if games := gm.GetWaitingGames(); len(games) != 0 {
+            t.Errorf("expected empty lobby to be hidden, got %d waiting games", len(games))
+        }
+    })
+
+    t.Run("Surrender Updates Placement And Stats", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice", "bob")
+        game := startTestGame(t, gm, ids)
+
+        if err := gm.Surrender(game.ID, ids[0]); err != nil {
+            t.Fatalf("failed to surrender: %v", err)
+        }
+        if game.Players[0].Placement != 2 || game.Players[1].Placement != 1 {
+            t.Errorf("expected placements 2 and 1, got %d and %d", game.Players[0].Placement, game.Players[1].Placement)
+        }
+        if game.Status != StatusFinished {
+            t.Errorf("expected status %s, got %s", StatusFinished, game.Status)
+        }
+
+        loser, _ := gm.playerRepo.GetStats(context.Background(), ids[0])
+        winner, _ := gm.playerRepo.GetStats(context.Background(), ids[1])
+        if loser.Losses != 1 || winner.Wins != 1 {
+            t.Errorf("expected 1 loss and 1 win, got %d and %d", loser.Losses, winner.Wins)
+        }
+
+        if err := gm.Surrender(game.ID, ids[1]); err == nil {
+            t.Error("expected error when surrendering a finished game")
+        }
+    })
+
+    t.Run("Concurrent Results Are All Counted", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice")
+
+        var wg sync.WaitGroup
+        for i := 0; i < 50; i++ {
+            wg.Add(1)
+            go func(placement int) {
+                defer wg.Done()
+                gm.recordResult(&Player{ID: ids[0], Placement: placement})
+            }(i%2 + 1)
+        }
+        wg.Wait()
+
+        stats, _ := gm.playerRepo.GetStats(context.Background(), ids[0])
+        if stats.Games != 50 || stats.Wins != 25 || stats.Losses != 25 || stats.PlacementTotal != 75 {
+            t.Errorf("expected 50 games with 25 wins, got %+v", stats)
+        }
+    })
+
+    t.Run("Results While Profiles Are Read", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice")
+
+        // บันทึกผลไปเรื่อยๆ ระหว่างที่อีกฝั่งอ่านโปรไฟล์ Stats ที่ได้ไปต้องไม่ใช่ตัวเดียวกับใน repository
+        started, done := make(chan struct{}), make(chan struct{})
+        go func() {
+            close(started)
+            for {
+                select {
+                case <-done:
+                    return
+                default:
+                    gm.recordResult(&Player{ID: ids[0], Placement: 1})
+                }
+            }
+        }()
+
+        <-started
+        for i := 0; i < 200; i++ {
+            player, err := gm.playerRepo.GetByID(context.Background(), ids[0])
+            if err != nil {
+                t.Fatalf("failed to get player: %v", err)
+            }
+            if player.Stats != nil && player.Stats.Wins != player.Stats.Games {
+                t.Fatalf("expected every game to be a win, got %+v", player.Stats)
+            }
+        }
+        close(done)
+    })
+}
+func TestPrivateLobby(t *testing.T) {
+    gm, ids := newTestManager(t, "alice", "bob", "carol")
+
+    game, err := gm.CreateGame(ids[0], LobbyOptions{Private: true, Password: "hunter2", Settings: settingsWithMaxPlayers(3)})
+    if err != nil {
+        t.Fatalf("failed to create game: %v", err)
+    }
+    if game.JoinCode == "" {
+        t.Fatal("expected private lobby to have a join code")
+    }
+
+    // ล็อบบี้ส่วนตัวต้องไม่โผล่ในรายการและ AutoMatch
+    if games := gm.GetWaitingGames(); len(games) != 0 {
+        t.Errorf("expected private lobby to be hidden, got %d waiting games", len(games))
+    }
+    if err := gm.JoinGame(game.ID, ids[1]); err != ErrGameNotJoinable {
+        t.Errorf("expected %v, got %v", ErrGameNotJoinable, err)
+    }
+    if _, err := gm.JoinByCode(game.JoinCode, "wrong", ids[1]); err != ErrInvalidPassword {
+        t.Errorf("expected %v, got %v", ErrInvalidPassword, err)
+    }
+
+    for _, id := range ids[1:] {
+        if _, err := gm.JoinByCode(game.JoinCode, "hunter2", id); err != nil {
+            t.Fatalf("failed to join by code: %v", err)
+        }
+    }
+    if game.Status != StatusWaiting {
+        t.Errorf("expected private lobby to wait for host, got %s", game.Status)
+    }
+
+    if err := gm.KickPlayer(game.ID, ids[1], ids[2]); err != ErrNotHost {
+        t.Errorf("expected %v, got %v", ErrNotHost, err)
+    }
+    if err := gm.KickPlayer(game.ID, ids[0], ids[2]); err != nil {
+        t.Fatalf("failed to kick player: %v", err)
+    }
+    if err := gm.SetMaxPlayers(game.ID, ids[0], 1); err != ErrInvalidLobbySize {
+        t.Errorf("expected %v, got %v", ErrInvalidLobbySize, err)
+    }
+
+    if err := gm.StartGame(game.ID, ids[0]); err != ErrPlayersNotReady {
+        t.Errorf("expected %v, got %v", ErrPlayersNotReady, err)
+    }
+    if err := gm.SetReady(game.ID, ids[1], true); err != nil {
+        t.Fatalf("failed to set ready: %v", err)
+    }
+    if err := gm.StartGame(game.ID, ids[0]); err != nil {
+        t.Fatalf("failed to start game: %v", err)
+    }
+    if game.Status != StatusPlaying {
+        t.Errorf("expected status %s, got %s", StatusPlaying, game.Status)
+    }
+}
+func settingsWithMaxPlayers(maxPlayers int) GameSettings {
+    settings := DefaultGameSettings()
+    settings.MaxPlayers = maxPlayers
+    return settings
 }
\ No newline at end of file
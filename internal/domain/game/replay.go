@@ -0,0 +1,205 @@
+package game
+
+import (
+    "bytes"
+    "compress/gzip"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "sort"
+    "time"
+)
+
+// ReplayVersion เพิ่มเมื่อรูปแบบไฟล์ replay เปลี่ยนจนอ่านของเก่าไม่ได้
+const ReplayVersion = 1
+
+var ErrReplayUnavailable = errors.New("game has not started, nothing to replay")
+
+// Replay คือสถานะตอนเริ่มเกม seed และ action ทุกตัวหลังเริ่ม
+// เล่น action ตามลำดับด้วย Game.apply จะได้เกมเหมือนที่เกิดขึ้นจริงทุกขั้น
+type Replay struct {
+    Version   int          `json:"v"`
+    GameID    string       `json:"game_id"`
+    Mode      GameMode     `json:"mode"`
+    Ranked    bool         `json:"ranked"`
+    Seed      int64        `json:"seed"`
+    Settings  GameSettings `json:"settings"`
+    StartedAt time.Time    `json:"started_at"`
+    Players   []Player     `json:"players"`
+    Actions   []GameAction `json:"actions"`
+}
+
+func NewReplay(game *Game) (*Replay, error) {
+    if game.StartedAt.IsZero() || game.startPlayers == nil {
+        return nil, ErrReplayUnavailable
+    }
+
+    return &Replay{
+        Version:   ReplayVersion,
+        GameID:    game.ID,
+        Mode:      game.Mode,
+        Ranked:    game.Ranked,
+        Seed:      game.Seed,
+        Settings:  game.Settings,
+        StartedAt: game.StartedAt,
+        Players:   clonePlayerValues(game.startPlayers),
+        Actions:   append([]GameAction(nil), game.Actions[game.startAction:]...),
+    }, nil
+}
+
+// Encode แปลง replay เป็นไฟล์ JSON ที่บีบอัดด้วย gzip
+func (r *Replay) Encode() ([]byte, error) {
+    var buf bytes.Buffer
+    zw := gzip.NewWriter(&buf)
+    if err := json.NewEncoder(zw).Encode(r); err != nil {
+        return nil, err
+    }
+    if err := zw.Close(); err != nil {
+        return nil, err
+    }
+    return buf.Bytes(), nil
+}
+
+func DecodeReplay(data []byte) (*Replay, error) {
+    zr, err := gzip.NewReader(bytes.NewReader(data))
+    if err != nil {
+        return nil, err
+    }
+    defer zr.Close()
+
+    var replay Replay
+    if err := json.NewDecoder(zr).Decode(&replay); err != nil {
+        return nil, err
+    }
+    if replay.Version != ReplayVersion {
+        return nil, fmt.Errorf("unsupported replay version %d", replay.Version)
+    }
+    return &replay, nil
+}
+
+// Duration คือเวลาตั้งแต่เริ่มเกมจนถึง action สุดท้าย
+func (r *Replay) Duration() time.Duration {
+    if len(r.Actions) == 0 {
+        return 0
+    }
+    return r.Offset(len(r.Actions) - 1)
+}
+
+// Offset คือเวลาของ action ที่ index นับจากเริ่มเกม
+func (r *Replay) Offset(index int) time.Duration {
+    return r.Actions[index].Timestamp.Sub(r.StartedAt)
+}
+
+// ReplayPlayer เดิน replay ทีละ action และกระโดดไปตำแหน่งใดก็ได้
+type ReplayPlayer struct {
+    replay   *Replay
+    game     *Game
+    position int // จำนวน action ที่เล่นไปแล้ว
+}
+
+func NewReplayPlayer(replay *Replay) *ReplayPlayer {
+    p := &ReplayPlayer{replay: replay}
+    p.Seek(0)
+    return p
+}
+
+func (p *ReplayPlayer) Replay() *Replay {
+    return p.replay
+}
+
+// Game คือสถานะเกม ณ ตำแหน่งปัจจุบัน
+func (p *ReplayPlayer) Game() *Game {
+    return p.game
+}
+
+func (p *ReplayPlayer) Position() int {
+    return p.position
+}
+
+func (p *ReplayPlayer) Done() bool {
+    return p.position >= len(p.replay.Actions)
+}
+
+// Step เล่น action ถัดไป คืน false เมื่อจบ replay แล้ว
+func (p *ReplayPlayer) Step() (GameAction, bool) {
+    if p.Done() {
+        return GameAction{}, false
+    }
+
+    action := p.replay.Actions[p.position]
+    // ไม่ต่อ action เข้า game.Actions เพราะผู้ชมได้ action ของแต่ละขั้นแยกอยู่แล้ว
+    p.game.apply(action)
+    p.game.UpdatedAt = action.Timestamp
+    p.position++
+    return action, true
+}
+
+// Seek เริ่มเกมใหม่จากสถานะตอนเริ่มแล้วเล่นไปจนครบ position action
+func (p *ReplayPlayer) Seek(position int) {
+    if position < 0 {
+        position = 0
+    }
+    if position > len(p.replay.Actions) {
+        position = len(p.replay.Actions)
+    }
+
+    p.game = p.replay.initialGame()
+    p.position = 0
+    for p.position < position {
+        p.Step()
+    }
+}
+
+// SeekTime กระโดดไปยังเวลาที่กำหนดนับจากเริ่มเกม โดยเล่นทุก action ที่เกิดขึ้นก่อนหรือตรงเวลานั้น
+func (p *ReplayPlayer) SeekTime(offset time.Duration) {
+    actions := p.replay.Actions
+    position := sort.Search(len(actions), func(i int) bool {
+        return p.replay.Offset(i) > offset
+    })
+    p.Seek(position)
+}
+
+func (r *Replay) initialGame() *Game {
+    game := NewGame(r.GameID)
+    game.Status = StatusPlaying
+    game.Mode = r.Mode
+    game.Ranked = r.Ranked
+    game.Seed = r.Seed
+    game.Settings = r.Settings
+    game.Round = 1
+    game.Phase = PhasePlanning
+    game.StartedAt = r.StartedAt
+    game.CreatedAt = r.StartedAt
+    game.UpdatedAt = r.StartedAt
+    for i := range r.Players {
+        player := r.Players[i]
+        player.Inventory = append([]Item(nil), player.Inventory...)
+        game.Players = append(game.Players, &player)
+    }
+    if len(game.Players) > 0 {
+        game.HostID = game.Players[0].ID
+    }
+    game.buildTeams()
+    return game
+}
+
+// clonePlayers คัดลอกผู้เล่นแบบไม่แชร์ inventory กับของเดิม
+func clonePlayers(players []*Player) []Player {
+    cloned := make([]Player, 0, len(players))
+    for _, p := range players {
+        copied := *p
+        copied.Inventory = append([]Item(nil), p.Inventory...)
+        cloned = append(cloned, copied)
+    }
+    return cloned
+}
+
+func clonePlayerValues(players []Player) []Player {
+    cloned := make([]Player, 0, len(players))
+    for i := range players {
+        copied := players[i]
+        copied.Inventory = append([]Item(nil), players[i].Inventory...)
+        cloned = append(cloned, copied)
+    }
+    return cloned
+}
\ No newline at end of file
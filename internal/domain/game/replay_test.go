@@ -0,0 +1,97 @@
+package game
+
+import (
+    "testing"
+    "time"
+)
+
+func TestReplay(t *testing.T) {
+    gm, ids := newTestManager(t, "alice", "bob", "carol")
+    game, err := gm.CreateMatch(ids)
+    if err != nil {
+        t.Fatalf("failed to create match: %v", err)
+    }
+
+    // เล่นให้มีทั้งการซื้อของ การโจมตี และการเปลี่ยนเฟสหลายรอบ
+    now := time.Now()
+    gm.BuyItem(game.ID, ids[0], "sword")
+    gm.BuyItem(game.ID, ids[2], "shield")
+    for i := 0; game.Status == StatusPlaying && i < 200; i++ {
+        now = now.Add(16 * time.Second)
+        gm.mu.Lock()
+        gm.advancePhases(now)
+        gm.mu.Unlock()
+
+        attacker, target := ids[i%3], ids[(i+1)%3]
+        gm.ProcessAction(game.ID, GameAction{Type: ActionAttack, PlayerID: attacker, TargetID: target, Timestamp: now})
+    }
+    if game.Status != StatusFinished {
+        t.Fatalf("expected game to finish, got %s", game.Status)
+    }
+    if game.Round < 2 {
+        t.Fatalf("expected several rounds to be played, got %d", game.Round)
+    }
+
+    replay, err := NewReplay(game)
+    if err != nil {
+        t.Fatalf("failed to create replay: %v", err)
+    }
+    data, err := replay.Encode()
+    if err != nil {
+        t.Fatalf("failed to encode replay: %v", err)
+    }
+    decoded, err := DecodeReplay(data)
+    if err != nil {
+        t.Fatalf("failed to decode replay: %v", err)
+    }
+
+    t.Run("Playback Matches Game", func(t *testing.T) {
+        player := NewReplayPlayer(decoded)
+        for {
+            if _, ok := player.Step(); !ok {
+                break
+            }
+        }
+
+        replayed := player.Game()
+        if replayed.Status != StatusFinished || replayed.Round != game.Round || replayed.Phase != game.Phase {
+            t.Errorf("expected %s round %d %s, got %s round %d %s",
+                game.Status, game.Round, game.Phase, replayed.Status, replayed.Round, replayed.Phase)
+        }
+        for i, p := range game.Players {
+            r := replayed.Players[i]
+            if r.Health != p.Health || r.Gold != p.Gold || r.Attack != p.Attack || r.Defense != p.Defense ||
+                r.Placement != p.Placement || len(r.Inventory) != len(p.Inventory) || r.DamageDealt != p.DamageDealt {
+                t.Errorf("player %s differs: expected %+v, got %+v", p.ID, *p, *r)
+            }
+        }
+    })
+
+    t.Run("Seek", func(t *testing.T) {
+        player := NewReplayPlayer(decoded)
+        player.Seek(len(decoded.Actions))
+        player.Seek(2)
+        if player.Position() != 2 || player.Game().Status != StatusPlaying {
+            t.Errorf("expected to rewind to action 2 while still playing, got %d (%s)", player.Position(), player.Game().Status)
+        }
+        // ทองของ alice ต้องเท่ากับตอนเริ่มลบค่าดาบที่ซื้อไป
+        sword := player.Game().items()["sword"]
+        if gold := player.Game().Players[0].Gold; gold != decoded.Players[0].Gold-sword.Cost {
+            t.Errorf("expected %d gold after buying a sword, got %d", decoded.Players[0].Gold-sword.Cost, gold)
+        }
+        if len(player.Game().Players[0].Inventory) != 1 || len(player.Game().Players[2].Inventory) != 1 {
+            t.Errorf("expected both purchases after seeking to 2, got %+v", player.Game().Players)
+        }
+
+        player.SeekTime(decoded.Duration())
+        if !player.Done() {
+            t.Errorf("expected seeking to the end to play every action, got %d of %d", player.Position(), len(decoded.Actions))
+        }
+    })
+
+    t.Run("Unstarted Game", func(t *testing.T) {
+        if _, err := NewReplay(NewGame("lobby")); err != ErrReplayUnavailable {
+            t.Errorf("expected %v, got %v", ErrReplayUnavailable, err)
+        }
+    })
+}
\ No newline at end of file
@@ -0,0 +1,234 @@
+package game
+
+import (
+    "fmt"
+    "sort"
+    "sync"
+)
+
+// RuleSet คือกติกาของโหมดเกม GameManager ถามทุกอย่างที่ขึ้นกับโหมดผ่าน interface นี้
+type RuleSet interface {
+    Mode() GameMode
+
+    // การตั้งค่าและการเริ่มเกม
+    DefaultSettings() GameSettings
+    ValidateSettings(settings GameSettings) error
+    CanStart(players int) error
+    Setup(game *Game)
+
+    // การต่อสู้
+    Damage(attack, defense int) int
+
+    // การเดินรอบ เงินที่คนที่ยังอยู่ได้รับเมื่อเริ่มรอบใหม่
+    RoundIncome(round int) int
+
+    // เงื่อนไขชนะ remaining คือจำนวนผู้เล่น (หรือทีม) ที่เหลือหลังมีคนตกรอบ
+    Finished(remaining int) bool
+    // Scored บอกว่าผลของโหมดนี้นับเข้าสถิติผู้เล่นหรือไม่
+    Scored() bool
+}
+
+// ruleSets เก็บกติกาที่เลือกใช้ได้ ตามโหมด อ่านและเขียนผ่าน ruleSetsMu
+var ruleSetsMu sync.RWMutex
+var ruleSets = map[GameMode]RuleSet{
+    ModeClassic:   classicRules{},
+    ModeDuel:      duelRules{},
+    ModeHyperRoll: hyperRollRules{},
+    ModeSandbox:   sandboxRules{},
+    ModeDoubleUp:  doubleUpRules{},
+}
+
+// RegisterRuleSet เพิ่มหรือแทนที่กติกาของโหมด
+func RegisterRuleSet(rules RuleSet) {
+    ruleSetsMu.Lock()
+    defer ruleSetsMu.Unlock()
+    ruleSets[rules.Mode()] = rules
+}
+
+// RuleSetFor คืนกติกาของโหมด โหมดว่างถือเป็น classic
+func RuleSetFor(mode GameMode) (RuleSet, error) {
+    if mode == "" {
+        mode = ModeClassic
+    }
+    ruleSetsMu.RLock()
+    rules, exists := ruleSets[mode]
+    ruleSetsMu.RUnlock()
+    if !exists {
+        return nil, fmt.Errorf("%w: %q", ErrUnknownMode, mode)
+    }
+    return rules, nil
+}
+
+// Modes คืนโหมดทั้งหมดที่เลือกได้ เรียงตามชื่อ
+func Modes() []GameMode {
+    ruleSetsMu.RLock()
+    defer ruleSetsMu.RUnlock()
+    modes := make([]GameMode, 0, len(ruleSets))
+    for mode := range ruleSets {
+        modes = append(modes, mode)
+    }
+    sort.Slice(modes, func(i, j int) bool { return modes[i] < modes[j] })
+    return modes
+}
+
+// rules คืนกติกาของเกมนี้ ถ้าโหมดไม่รู้จักจะใช้ classic
+func (g *Game) rules() RuleSet {
+    ruleSetsMu.RLock()
+    defer ruleSetsMu.RUnlock()
+    if rules, exists := ruleSets[g.Mode]; exists {
+        return rules
+    }
+    return classicRules{}
+}
+
+// classicRules คือกติกาเดิมของเกม ทุกคนเล่นเดี่ยวจนเหลือคนสุดท้าย
+type classicRules struct{}
+
+func (classicRules) Mode() GameMode { return ModeClassic }
+
+func (classicRules) DefaultSettings() GameSettings { return DefaultGameSettings() }
+
+func (classicRules) ValidateSettings(settings GameSettings) error { return settings.Validate() }
+
+func (classicRules) CanStart(players int) error {
+    if players < MinLobbySize {
+        return ErrNotEnoughPlayers
+    }
+    return nil
+}
+
+// Setup ตั้งค่าสถานะเริ่มต้นของทุกคนตาม Settings ของเกม
+func (classicRules) Setup(game *Game) {
+    for _, p := range game.Players {
+        p.Health = game.Settings.StartingHealth
+        p.Gold = game.Settings.StartingGold
+        p.Attack = game.Settings.StartingAttack
+        p.Defense = game.Settings.StartingDefense
+        p.Inventory = nil
+        p.GoldSpent = 0
+    }
+}
+
+func (classicRules) Damage(attack, defense int) int {
+    damage := attack - (defense / 2)
+    if damage < 1 {
+        damage = 1
+    }
+    return damage
+}
+
+func (classicRules) RoundIncome(round int) int { return 5 }
+
+func (classicRules) Finished(remaining int) bool { return remaining <= 1 }
+
+func (classicRules) Scored() bool { return true }
+
+// duelRules คือ 1v1 เลือดน้อยกว่าเพื่อให้จบเร็ว
+type duelRules struct{ classicRules }
+
+func (duelRules) Mode() GameMode { return ModeDuel }
+
+func (duelRules) DefaultSettings() GameSettings {
+    settings := DefaultGameSettings()
+    settings.StartingHealth = 60
+    settings.MaxPlayers = 2
+    return settings
+}
+
+func (r duelRules) ValidateSettings(settings GameSettings) error {
+    if settings.MaxPlayers != 2 {
+        return fmt.Errorf("%w: duel needs exactly 2 players", ErrInvalidSettings)
+    }
+    return r.classicRules.ValidateSettings(settings)
+}
+
+func (duelRules) CanStart(players int) error {
+    if players != 2 {
+        return ErrNotEnoughPlayers
+    }
+    return nil
+}
+
+// hyperRollRules รอบสั้น เงินเยอะ และตีแรงเป็นสองเท่า
+type hyperRollRules struct{ classicRules }
+
+func (hyperRollRules) Mode() GameMode { return ModeHyperRoll }
+
+func (hyperRollRules) DefaultSettings() GameSettings {
+    settings := DefaultGameSettings()
+    settings.StartingHealth = 50
+    settings.StartingGold = 150
+    settings.PlanningSeconds = 15
+    settings.CombatSeconds = 10
+    return settings
+}
+
+func (r hyperRollRules) Damage(attack, defense int) int {
+    return r.classicRules.Damage(attack, defense) * 2
+}
+
+func (hyperRollRules) RoundIncome(round int) int { return 10 + round }
+
+// sandboxRules ใช้ซ้อมเล่น เงินไม่จำกัด เริ่มคนเดียวได้ และไม่นับสถิติ
+// เกมจบเมื่อทุกคนออกหมด
+type sandboxRules struct{ classicRules }
+
+func (sandboxRules) Mode() GameMode { return ModeSandbox }
+
+func (sandboxRules) DefaultSettings() GameSettings {
+    settings := DefaultGameSettings()
+    settings.StartingGold = 1000
+    settings.PlanningSeconds = 120
+    return settings
+}
+
+func (sandboxRules) CanStart(players int) error {
+    if players < 1 {
+        return ErrNotEnoughPlayers
+    }
+    return nil
+}
+
+func (sandboxRules) RoundIncome(round int) int { return 100 }
+
+func (sandboxRules) Finished(remaining int) bool { return remaining == 0 }
+
+func (sandboxRules) Scored() bool { return false }
+
+// doubleUpRules จับคู่ผู้เล่นที่อยู่ติดกันในล็อบบี้เป็นทีมละสองคน ใช้เลือดร่วมกัน
+type doubleUpRules struct{ classicRules }
+
+const doubleUpTeamSize = 2
+
+func (doubleUpRules) Mode() GameMode { return ModeDoubleUp }
+
+func (doubleUpRules) DefaultSettings() GameSettings {
+    settings := DefaultGameSettings()
+    settings.MaxPlayers = 2 * doubleUpTeamSize
+    return settings
+}
+
+func (r doubleUpRules) ValidateSettings(settings GameSettings) error {
+    if settings.MaxPlayers < 2*doubleUpTeamSize {
+        return fmt.Errorf("%w: double up needs at least %d players", ErrInvalidSettings, 2*doubleUpTeamSize)
+    }
+    if settings.MaxPlayers%doubleUpTeamSize != 0 {
+        return ErrUnevenTeams
+    }
+    return r.classicRules.ValidateSettings(settings)
+}
+
+func (doubleUpRules) CanStart(players int) error {
+    if players < 2*doubleUpTeamSize {
+        return ErrNotEnoughPlayers
+    }
+    if players%doubleUpTeamSize != 0 {
+        return ErrUnevenTeams
+    }
+    return nil
+}
+
+func (r doubleUpRules) Setup(game *Game) {
+    r.classicRules.Setup(game)
+    game.assignTeams(doubleUpTeamSize)
+}
\ No newline at end of file
@@ -0,0 +1,147 @@
+package game
+
+import (
+    "context"
+    "errors"
+    "testing"
+)
+
+func TestRuleSets(t *testing.T) {
+    t.Run("Duel Needs Exactly Two Players", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice")
+
+        if _, err := gm.CreateGame(ids[0], LobbyOptions{Mode: ModeDuel, Settings: settingsWithMaxPlayers(3)}); !errors.Is(err, ErrInvalidSettings) {
+            t.Errorf("expected %v, got %v", ErrInvalidSettings, err)
+        }
+
+        game, err := gm.CreateGame(ids[0], LobbyOptions{Mode: ModeDuel})
+        if err != nil {
+            t.Fatalf("failed to create game: %v", err)
+        }
+        if game.Settings.StartingHealth != 60 {
+            t.Errorf("expected duel defaults, got health %d", game.Settings.StartingHealth)
+        }
+    })
+
+    t.Run("Hyper Roll Hits Harder And Pays More", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice", "bob")
+
+        game, err := gm.CreateGame(ids[0], LobbyOptions{Mode: ModeHyperRoll})
+        if err != nil {
+            t.Fatalf("failed to create game: %v", err)
+        }
+        if err := gm.JoinGame(game.ID, ids[1]); err != nil {
+            t.Fatalf("failed to join game: %v", err)
+        }
+
+        alice, bob := game.Players[0], game.Players[1]
+        if alice.Health != 50 || alice.Gold != 150 {
+            t.Errorf("expected hyper roll starting stats, got health %d gold %d", alice.Health, alice.Gold)
+        }
+
+        if err := gm.ProcessAction(game.ID, attackBy(ids[0], bob)); err != nil {
+            t.Fatalf("failed to attack: %v", err)
+        }
+        if want := 50 - 2*(classicRules{}).Damage(10, 5); bob.Health != want {
+            t.Errorf("expected bob at %d health, got %d", want, bob.Health)
+        }
+
+        game.apply(GameAction{Type: ActionPhase, Round: 2, Phase: PhasePlanning})
+        if alice.Gold != 150+12 {
+            t.Errorf("expected round 2 income of 12, got gold %d", alice.Gold)
+        }
+    })
+
+    t.Run("Sandbox Starts Solo And Skips Stats", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice")
+
+        game, err := gm.CreateGame(ids[0], LobbyOptions{Mode: ModeSandbox})
+        if err != nil {
+            t.Fatalf("failed to create game: %v", err)
+        }
+        if err := gm.StartGame(game.ID, ids[0]); err != nil {
+            t.Fatalf("failed to start solo sandbox: %v", err)
+        }
+        if err := gm.BuyItem(game.ID, ids[0], "sword"); err != nil {
+            t.Fatalf("failed to buy item: %v", err)
+        }
+
+        if err := gm.Surrender(game.ID, ids[0]); err != nil {
+            t.Fatalf("failed to surrender: %v", err)
+        }
+        if game.Status != StatusFinished {
+            t.Errorf("expected sandbox to finish once everyone left, got %s", game.Status)
+        }
+        stats, _ := gm.playerRepo.GetStats(context.Background(), ids[0])
+        if stats.Games != 0 {
+            t.Errorf("expected sandbox not to count toward stats, got %d games", stats.Games)
+        }
+    })
+
+    t.Run("Items Are Bought Only While Playing", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice")
+
+        game, err := gm.CreateGame(ids[0], LobbyOptions{Mode: ModeSandbox})
+        if err != nil {
+            t.Fatalf("failed to create game: %v", err)
+        }
+        if err := gm.BuyItem(game.ID, ids[0], "sword"); !errors.Is(err, ErrGameNotPlaying) {
+            t.Errorf("expected %v, got %v", ErrGameNotPlaying, err)
+        }
+
+        // ของที่ค้างจากก่อนเริ่มต้องถูกล้างตอน Setup
+        game.Players[0].Inventory = []Item{{ID: "sword", Attack: 10}}
+        if err := gm.StartGame(game.ID, ids[0]); err != nil {
+            t.Fatalf("failed to start game: %v", err)
+        }
+        alice := game.Players[0]
+        if len(alice.Inventory) != 0 || alice.Attack != game.Settings.StartingAttack {
+            t.Errorf("expected a fresh start, got %d items and attack %d", len(alice.Inventory), alice.Attack)
+        }
+    })
+
+    t.Run("Register While Games Are Created", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice")
+
+        // ลงทะเบียนค่าเดิมซ้ำไปเรื่อยๆ ระหว่างที่อีกฝั่งสร้างเกมและอ่าน registry
+        started, done := make(chan struct{}), make(chan struct{})
+        go func() {
+            close(started)
+            for {
+                select {
+                case <-done:
+                    return
+                default:
+                    RegisterRuleSet(classicRules{})
+                    RegisterBotStrategy(BotGreedy, greedyStrategy{})
+                    RegisterContentPack(DefaultContentPack, DefaultItems)
+                }
+            }
+        }()
+
+        <-started
+        for i := 0; i < 200; i++ {
+            game, err := gm.CreateGame(ids[0], LobbyOptions{})
+            if err != nil {
+                t.Fatalf("failed to create game: %v", err)
+            }
+            if _, err := gm.AddBot(game.ID, ids[0], BotGreedy); err != nil {
+                t.Fatalf("failed to add bot: %v", err)
+            }
+            if len(Modes()) == 0 || len(game.items()) == 0 {
+                t.Fatal("expected registered modes and items")
+            }
+            gm.LeaveGame(game.ID, ids[0])
+        }
+        close(done)
+    })
+
+    t.Run("Unknown Mode", func(t *testing.T) {
+        if _, err := RuleSetFor("chess"); !errors.Is(err, ErrUnknownMode) {
+            t.Errorf("expected %v, got %v", ErrUnknownMode, err)
+        }
+        if rules, err := RuleSetFor(""); err != nil || rules.Mode() != ModeClassic {
+            t.Errorf("expected empty mode to mean classic, got %v", err)
+        }
+    })
+}
\ No newline at end of file
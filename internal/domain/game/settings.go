@@ -0,0 +1,118 @@
+package game
+
+import (
+    "fmt"
+    "sync"
+    "time"
+)
+
+// GameSettings คือกติกาที่เลือกตอนสร้างล็อบบี้ และเก็บไว้ใน Game ให้ client แสดงได้
+type GameSettings struct {
+    StartingGold    int    `json:"starting_gold"`
+    StartingHealth  int    `json:"starting_health"`
+    StartingAttack  int    `json:"starting_attack"`
+    StartingDefense int    `json:"starting_defense"`
+    PlanningSeconds int    `json:"planning_seconds"`
+    CombatSeconds   int    `json:"combat_seconds"`
+    MaxPlayers      int    `json:"max_players"`
+    ContentPack     string `json:"content_pack"`
+}
+
+// SettingRange คือช่วงค่าที่อนุญาต
+type SettingRange struct {
+    Min int `json:"min"`
+    Max int `json:"max"`
+}
+
+var settingRanges = map[string]SettingRange{
+    "starting_gold":    {Min: 0, Max: 1000},
+    "starting_health":  {Min: 10, Max: 500},
+    "starting_attack":  {Min: 1, Max: 100},
+    "starting_defense": {Min: 0, Max: 100},
+    "planning_seconds": {Min: 5, Max: 120},
+    "combat_seconds":   {Min: 5, Max: 120},
+    "max_players":      {Min: MinLobbySize, Max: MaxLobbySize},
+}
+
+const DefaultContentPack = "default"
+
+// contentPacks เก็บชุดไอเทมที่เลือกใช้ได้ ตามชื่อ อ่านและเขียนผ่าน contentPacksMu
+var contentPacksMu sync.RWMutex
+var contentPacks = map[string]map[string]Item{
+    DefaultContentPack: DefaultItems,
+}
+
+func DefaultGameSettings() GameSettings {
+    return GameSettings{
+        StartingGold:    100,
+        StartingHealth:  100,
+        StartingAttack:  10,
+        StartingDefense: 5,
+        PlanningSeconds: 30,
+        CombatSeconds:   30,
+        MaxPlayers:      DefaultMaxPlayers,
+        ContentPack:     DefaultContentPack,
+    }
+}
+
+func (s GameSettings) Validate() error {
+    values := map[string]int{
+        "starting_gold":    s.StartingGold,
+        "starting_health":  s.StartingHealth,
+        "starting_attack":  s.StartingAttack,
+        "starting_defense": s.StartingDefense,
+        "planning_seconds": s.PlanningSeconds,
+        "combat_seconds":   s.CombatSeconds,
+        "max_players":      s.MaxPlayers,
+    }
+    for name, value := range values {
+        r := settingRanges[name]
+        if value < r.Min || value > r.Max {
+            return fmt.Errorf("%w: %s must be between %d and %d", ErrInvalidSettings, name, r.Min, r.Max)
+        }
+    }
+
+    if _, exists := ContentPackItems(s.ContentPack); !exists {
+        return fmt.Errorf("%w: unknown content pack %q", ErrInvalidSettings, s.ContentPack)
+    }
+    return nil
+}
+
+func (s GameSettings) planningDuration() time.Duration {
+    return time.Duration(s.PlanningSeconds) * time.Second
+}
+
+func (s GameSettings) combatDuration() time.Duration {
+    return time.Duration(s.CombatSeconds) * time.Second
+}
+
+// SettingRanges คืนช่วงค่าที่อนุญาตให้ client ใช้แสดงฟอร์มตั้งค่า
+func SettingRanges() map[string]SettingRange {
+    ranges := make(map[string]SettingRange, len(settingRanges))
+    for name, r := range settingRanges {
+        ranges[name] = r
+    }
+    return ranges
+}
+
+// RegisterContentPack เพิ่มชุดไอเทมใหม่ให้เลือกใช้ใน GameSettings
+func RegisterContentPack(name string, items map[string]Item) {
+    contentPacksMu.Lock()
+    defer contentPacksMu.Unlock()
+    contentPacks[name] = items
+}
+
+func ContentPackItems(name string) (map[string]Item, bool) {
+    contentPacksMu.RLock()
+    defer contentPacksMu.RUnlock()
+    items, exists := contentPacks[name]
+    return items, exists
+}
+
+// items คืนไอเทมของ content pack ที่เกมนี้ใช้
+func (g *Game) items() map[string]Item {
+    if items, exists := ContentPackItems(g.Settings.ContentPack); exists {
+        return items
+    }
+    return DefaultItems
+}
\ No newline at end of file
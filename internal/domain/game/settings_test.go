@@ -0,0 +1,67 @@
+package game
+
+import (
+    "errors"
+    "testing"
+    "time"
+)
+
+func TestGameSettings(t *testing.T) {
+    t.Run("Validate Ranges", func(t *testing.T) {
+        settings := DefaultGameSettings()
+        if err := settings.Validate(); err != nil {
+            t.Fatalf("expected default settings to be valid, got %v", err)
+        }
+
+        settings.StartingHealth = 0
+        if err := settings.Validate(); !errors.Is(err, ErrInvalidSettings) {
+            t.Errorf("expected %v, got %v", ErrInvalidSettings, err)
+        }
+
+        settings = DefaultGameSettings()
+        settings.ContentPack = "unknown"
+        if err := settings.Validate(); !errors.Is(err, ErrInvalidSettings) {
+            t.Errorf("expected %v, got %v", ErrInvalidSettings, err)
+        }
+    })
+
+    t.Run("Starting Stats And Rounds", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice", "bob")
+
+        settings := DefaultGameSettings()
+        settings.StartingGold = 0
+        settings.StartingHealth = 50
+        settings.PlanningSeconds = 10
+        settings.CombatSeconds = 10
+
+        game, err := gm.CreateGame(ids[0], LobbyOptions{Settings: settings})
+        if err != nil {
+            t.Fatalf("failed to create game: %v", err)
+        }
+        if err := gm.JoinGame(game.ID, ids[1]); err != nil {
+            t.Fatalf("failed to join game: %v", err)
+        }
+
+        alice := game.Players[0]
+        if alice.Health != 50 || alice.Gold != 0 {
+            t.Errorf("expected health 50 and gold 0, got %d and %d", alice.Health, alice.Gold)
+        }
+        if game.Round != 1 || game.Phase != PhasePlanning {
+            t.Fatalf("expected round 1 planning, got round %d %s", game.Round, game.Phase)
+        }
+
+        now := time.Now()
+        gm.tick(now.Add(11 * time.Second))
+        if game.Phase != PhaseCombat {
+            t.Errorf("expected combat phase, got %s", game.Phase)
+        }
+
+        gm.tick(now.Add(22 * time.Second))
+        if game.Round != 2 || game.Phase != PhasePlanning {
+            t.Errorf("expected round 2 planning, got round %d %s", game.Round, game.Phase)
+        }
+        if income := game.rules().RoundIncome(2); alice.Gold != income {
+            t.Errorf("expected round income %d, got %d", income, alice.Gold)
+        }
+    })
+}
\ No newline at end of file
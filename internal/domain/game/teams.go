@@ -0,0 +1,214 @@
+package game
+
+import (
+    "fmt"
+    "time"
+)
+
+// Team คือกลุ่มผู้เล่นในโหมดทีม ใช้เลือดร่วมกันและได้อันดับเดียวกัน
+type Team struct {
+    ID         string   `json:"id"`
+    Members    []string `json:"members"`
+    Health     int      `json:"health"`
+    Eliminated bool     `json:"eliminated"`
+    Placement  int      `json:"placement,omitempty"`
+}
+
+// assignTeams จับผู้เล่นที่อยู่ติดกันในล็อบบี้เป็นทีมเดียวกัน
+// ผู้เล่นที่เข้ามาด้วยกันจึงได้อยู่ทีมเดียวกัน
+func (g *Game) assignTeams(size int) {
+    for i, p := range g.Players {
+        p.TeamID = fmt.Sprintf("team_%d", i/size+1)
+    }
+    g.buildTeams()
+}
+
+// buildTeams สร้าง Teams จาก TeamID ของผู้เล่น ใช้ทั้งตอนเริ่มเกมและตอนสร้างเกมจาก replay
+func (g *Game) buildTeams() {
+    g.Teams = nil
+    for _, p := range g.Players {
+        if p.TeamID == "" {
+            continue
+        }
+        team := g.findTeam(p.TeamID)
+        if team == nil {
+            team = &Team{ID: p.TeamID, Health: p.Health}
+            g.Teams = append(g.Teams, team)
+        }
+        team.Members = append(team.Members, p.ID)
+    }
+}
+
+func (g *Game) findTeam(teamID string) *Team {
+    for _, t := range g.Teams {
+        if t.ID == teamID {
+            return t
+        }
+    }
+    return nil
+}
+
+// teamOf คืนทีมของผู้เล่น หรือ nil ถ้าเกมนี้ไม่ได้เล่นเป็นทีม
+func (g *Game) teamOf(player *Player) *Team {
+    if player.TeamID == "" {
+        return nil
+    }
+    return g.findTeam(player.TeamID)
+}
+
+// teammates คืนเพื่อนร่วมทีมที่ยังไม่ตกรอบ ไม่รวมตัวผู้เล่นเอง
+func (g *Game) teammates(player *Player) []*Player {
+    var mates []*Player
+    if player.TeamID == "" {
+        return mates
+    }
+    for _, p := range g.alivePlayers() {
+        if p != player && p.TeamID == player.TeamID {
+            mates = append(mates, p)
+        }
+    }
+    return mates
+}
+
+func (g *Game) aliveTeams() []*Team {
+    alive := make([]*Team, 0, len(g.Teams))
+    for _, t := range g.Teams {
+        if !t.Eliminated {
+            alive = append(alive, t)
+        }
+    }
+    return alive
+}
+
+// setTeamHealth เปลี่ยนเลือดของทีมและให้สมาชิกทุกคนเห็นค่าเดียวกัน
+func (g *Game) setTeamHealth(team *Team, health int) {
+    team.Health = max(health, 0)
+    for _, id := range team.Members {
+        if p := g.findPlayer(id); p != nil {
+            p.Health = team.Health
+        }
+    }
+}
+
+// reinforcement คือพลังที่เพื่อนร่วมทีมที่ยังอยู่ช่วยเสริม ครึ่งหนึ่งของค่าของเพื่อนแต่ละคน
+func (g *Game) reinforcement(player *Player) (attack, defense int) {
+    for _, mate := range g.teammates(player) {
+        attack += mate.Attack / 2
+        defense += mate.Defense / 2
+    }
+    return attack, defense
+}
+
+// attackTeam ตีทีมของเป้าหมาย ความเสียหายหักจากเลือดรวมของทีม
+func (g *Game) attackTeam(attacker, target *Player, team *Team, at time.Time) []*Player {
+    bonusAttack, _ := g.reinforcement(attacker)
+    _, bonusDefense := g.reinforcement(target)
+
+    damage := g.rules().Damage(attacker.Attack+bonusAttack, target.Defense+bonusDefense)
+    attacker.DamageDealt += min(damage, team.Health)
+    g.setTeamHealth(team, team.Health-damage)
+
+    if team.Health <= 0 {
+        return g.eliminateTeam(team, at)
+    }
+    return nil
+}
+
+// leaveTeam ตัดผู้เล่นคนเดียวออก (ยอมแพ้หรือ AFK) ทีมยังเล่นต่อได้ถ้ามีเพื่อนเหลือ
+// ผู้เล่นได้อันดับตอนที่ทีมตกรอบ
+func (g *Game) leaveTeam(player *Player, team *Team, at time.Time) []*Player {
+    player.Eliminated = true
+    player.AutoPilot = false
+    player.LastRound = g.Round
+
+    for _, id := range team.Members {
+        if p := g.findPlayer(id); p != nil && !p.Eliminated {
+            return nil
+        }
+    }
+    return g.eliminateTeam(team, at)
+}
+
+// eliminateTeam ตัดทั้งทีมออกและให้อันดับแย่สุดที่ยังเหลืออยู่กับสมาชิกทุกคน
+// ถ้าเหลือทีมเดียวจะจบเกมและให้ทีมนั้นได้ที่ 1 คืนผู้เล่นทุกคนที่ได้อันดับ
+func (g *Game) eliminateTeam(team *Team, at time.Time) []*Player {
+    if team.Eliminated {
+        return nil
+    }
+
+    alive := g.aliveTeams()
+    team.Eliminated = true
+    team.Placement = len(alive)
+    placed := g.placeTeam(team)
+
+    if g.rules().Finished(len(alive) - 1) {
+        for _, t := range alive {
+            if t != team {
+                t.Placement = 1
+                placed = append(placed, g.placeTeam(t)...)
+            }
+        }
+        g.Status = StatusFinished
+        g.FinishedAt = at
+    }
+    return placed
+}
+
+// placeTeam ให้อันดับของทีมกับสมาชิกทุกคน
+func (g *Game) placeTeam(team *Team) []*Player {
+    var placed []*Player
+    for _, id := range team.Members {
+        p := g.findPlayer(id)
+        if p == nil {
+            continue
+        }
+        if !p.Eliminated {
+            p.LastRound = g.Round
+        }
+        if team.Eliminated {
+            p.Eliminated = true
+            p.AutoPilot = false
+        }
+        p.Placement = team.Placement
+        placed = append(placed, p)
+    }
+    return placed
+}
+
+// sendItem ส่งไอเทมที่ใส่อยู่ให้เพื่อนร่วมทีม ค่าสถานะของไอเทมย้ายตามไปด้วย
+func (g *Game) sendItem(player *Player, targetID, itemID string) error {
+    target := g.findPlayer(targetID)
+    if target == nil {
+        return ErrPlayerNotFound
+    }
+    if target == player || player.TeamID == "" || target.TeamID != player.TeamID {
+        return ErrNotTeammate
+    }
+    if target.Eliminated {
+        return ErrPlayerEliminated
+    }
+
+    index := -1
+    for i, item := range player.Inventory {
+        if item.ID == itemID {
+            index = i
+            break
+        }
+    }
+    if index < 0 {
+        return ErrItemNotFound
+    }
+    item := player.Inventory[index]
+    // ยาถูกใช้ไปตอนซื้อแล้ว
+    if item.Type == ItemTypePotion {
+        return ErrItemNotTransferable
+    }
+
+    player.Inventory = append(player.Inventory[:index:index], player.Inventory[index+1:]...)
+    player.Attack -= item.Attack
+    player.Defense -= item.Defense
+    target.Inventory = append(target.Inventory, item)
+    target.Attack += item.Attack
+    target.Defense += item.Defense
+    return nil
+}
\ No newline at end of file
@@ -0,0 +1,143 @@
+package game
+
+import (
+    "context"
+    "errors"
+    "testing"
+)
+
+// startDoubleUp สร้างเกมโหมดทีมสี่คน ทีมแรกคือสองคนแรก
+func startDoubleUp(t *testing.T, gm *GameManager, ids []string) *Game {
+    t.Helper()
+
+    game, err := gm.CreateGame(ids[0], LobbyOptions{Mode: ModeDoubleUp, Settings: settingsWithMaxPlayers(len(ids))})
+    if err != nil {
+        t.Fatalf("failed to create game: %v", err)
+    }
+    for _, id := range ids[1:] {
+        if err := gm.JoinGame(game.ID, id); err != nil {
+            t.Fatalf("failed to join game: %v", err)
+        }
+    }
+    return game
+}
+
+func TestDoubleUp(t *testing.T) {
+    t.Run("Lobby Must Split Into Teams", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice")
+
+        _, err := gm.CreateGame(ids[0], LobbyOptions{Mode: ModeDoubleUp, Settings: settingsWithMaxPlayers(5)})
+        if !errors.Is(err, ErrUnevenTeams) {
+            t.Errorf("expected %v, got %v", ErrUnevenTeams, err)
+        }
+        if _, err := gm.CreateGame(ids[0], LobbyOptions{Mode: "battle_royale"}); !errors.Is(err, ErrUnknownMode) {
+            t.Errorf("expected %v, got %v", ErrUnknownMode, err)
+        }
+    })
+
+    t.Run("Teams Share Health And Reinforce", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice", "bob", "carol", "dave")
+        game := startDoubleUp(t, gm, ids)
+
+        if len(game.Teams) != 2 || game.Players[0].TeamID != game.Players[1].TeamID {
+            t.Fatalf("expected alice and bob on the same team, got %+v", game.Teams)
+        }
+        if err := gm.ProcessAction(game.ID, attackBy(ids[0], game.Players[1])); !errors.Is(err, ErrAttackTeammate) {
+            t.Errorf("expected %v, got %v", ErrAttackTeammate, err)
+        }
+
+        // alice 10+5 (bob ช่วยครึ่งหนึ่ง) ตี carol ที่มีเกราะ 5+2
+        if err := gm.ProcessAction(game.ID, attackBy(ids[0], game.Players[2])); err != nil {
+            t.Fatalf("failed to attack: %v", err)
+        }
+        carol, dave := game.Players[2], game.Players[3]
+        want := game.Settings.StartingHealth - game.rules().Damage(15, 7)
+        if carol.Health != want || dave.Health != want || game.Teams[1].Health != want {
+            t.Errorf("expected shared health %d, got carol=%d dave=%d team=%d", want, carol.Health, dave.Health, game.Teams[1].Health)
+        }
+    })
+
+    t.Run("Send Item To Teammate", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice", "bob", "carol", "dave")
+        game := startDoubleUp(t, gm, ids)
+        alice, bob := game.Players[0], game.Players[1]
+
+        if err := gm.BuyItem(game.ID, ids[0], "sword"); err != nil {
+            t.Fatalf("failed to buy item: %v", err)
+        }
+        if err := gm.SendItem(game.ID, ids[0], ids[2], "sword"); !errors.Is(err, ErrNotTeammate) {
+            t.Errorf("expected %v, got %v", ErrNotTeammate, err)
+        }
+        if err := gm.SendItem(game.ID, ids[0], ids[1], "sword"); err != nil {
+            t.Fatalf("failed to send item: %v", err)
+        }
+        if len(alice.Inventory) != 0 || len(bob.Inventory) != 1 {
+            t.Errorf("expected sword to move to bob, got %d and %d items", len(alice.Inventory), len(bob.Inventory))
+        }
+        if alice.Attack != game.Settings.StartingAttack || bob.Attack != game.Settings.StartingAttack+5 {
+            t.Errorf("expected attack bonus to move with the item, got %d and %d", alice.Attack, bob.Attack)
+        }
+    })
+
+    t.Run("First Team Out Places Last", func(t *testing.T) {
+        gm, ids := newTestManager(t, "alice", "bob", "carol", "dave", "erin", "frank")
+        game := startDoubleUp(t, gm, ids)
+
+        // alice ยอมแพ้คนเดียว bob ยังเล่นต่อได้
+        if err := gm.Surrender(game.ID, ids[0]); err != nil {
+            t.Fatalf("failed to surrender: %v", err)
+        }
+        if game.Players[0].Placement != 0 || game.Teams[0].Eliminated {
+            t.Fatalf("expected team to survive while bob plays, got placement %d", game.Players[0].Placement)
+        }
+
+        knockOut(t, gm, game, ids[2], game.Players[1])
+        if game.Players[0].Placement != 3 || game.Players[1].Placement != 3 {
+            t.Errorf("expected first team out in 3rd place, got %d and %d", game.Players[0].Placement, game.Players[1].Placement)
+        }
+
+        knockOut(t, gm, game, ids[2], game.Players[4])
+        if game.Status != StatusFinished {
+            t.Fatalf("expected status %s, got %s", StatusFinished, game.Status)
+        }
+        for i, want := range []int{3, 3, 1, 1, 2, 2} {
+            if got := game.Players[i].Placement; got != want {
+                t.Errorf("expected %s in place %d, got %d", game.Players[i].Username, want, got)
+            }
+        }
+
+        winner, _ := gm.playerRepo.GetStats(context.Background(), ids[3])
+        if winner.Wins != 1 {
+            t.Errorf("expected dave to share the win, got %d wins", winner.Wins)
+        }
+
+        // replay ต้องสร้างทีมและผลเดิมได้
+        replay, err := NewReplay(game)
+        if err != nil {
+            t.Fatalf("failed to build replay: %v", err)
+        }
+        playback := NewReplayPlayer(replay)
+        playback.Seek(len(replay.Actions))
+        for i, p := range playback.Game().Players {
+            if p.Placement != game.Players[i].Placement {
+                t.Errorf("expected replay placement %d for %s, got %d", game.Players[i].Placement, p.Username, p.Placement)
+            }
+        }
+    })
+}
+
+// knockOut ตีเป้าหมายซ้ำจนทีมของเป้าหมายตกรอบ
+func knockOut(t *testing.T, gm *GameManager, game *Game, attackerID string, target *Player) {
+    t.Helper()
+    for target.Placement == 0 {
+        if err := gm.ProcessAction(game.ID, attackBy(attackerID, target)); err != nil {
+            t.Fatalf("failed to attack: %v", err)
+        }
+    }
+}
+
+func attackBy(playerID string, target *Player) GameAction {
+    action := attackAction(target)
+    action.PlayerID = playerID
+    return action
+}
\ No newline at end of file
@@ -1,10 +1,15 @@
 package game
 
-import "time"
+import (
+    "math/rand"
+    "time"
+)
 
 type ItemType string
 type GameStatus string
 type ActionType string
+type GameMode string
+type GamePhase string
 
 const (
     StatusWaiting  GameStatus = "waiting"
@@ -14,6 +19,20 @@ const (
     ActionAttack  ActionType = "attack"
     ActionBuyItem ActionType = "buy_item"
     ActionUseItem ActionType = "use_item"
+    ActionForfeit ActionType = "forfeit"
+    ActionAutoPilot ActionType = "auto_pilot"
+    ActionSurrender ActionType = "surrender"
+    ActionPhase     ActionType = "phase" // action ของระบบ บันทึกการเปลี่ยนเฟสไว้ใน replay
+    ActionSendItem  ActionType = "send_item" // ส่งไอเทมให้เพื่อนร่วมทีม
+
+    ModeClassic   GameMode = "classic"
+    ModeDuel      GameMode = "duel"
+    ModeHyperRoll GameMode = "hyper_roll"
+    ModeSandbox   GameMode = "sandbox"
+    ModeDoubleUp  GameMode = "double_up" // ทีมละสองคน ใช้เลือดร่วมกัน
+
+    PhasePlanning GamePhase = "planning"
+    PhaseCombat   GamePhase = "combat"
 )
 
 // ลบ constants ที่ซ้ำกันออก เหลือแค่ชุดเดียว
@@ -32,6 +51,24 @@ type Player struct {
     Attack    int     `json:"attack"`
     Defense   int     `json:"defense"`
     Inventory []Item  `json:"inventory"` // เปลี่ยนจาก Items เป็น Inventory
+
+    // สถานะสำหรับตรวจจับผู้เล่น AFK
+    LastActiveAt time.Time `json:"last_active_at"`
+    AutoPilot    bool      `json:"auto_pilot"`
+    Eliminated   bool      `json:"eliminated"`
+    Ready        bool      `json:"ready"`
+
+    IsBot         bool          `json:"is_bot"`
+    BotDifficulty BotDifficulty `json:"bot_difficulty,omitempty"`
+    Placement    int       `json:"placement,omitempty"`
+    TeamID       string    `json:"team_id,omitempty"`
+
+    // สถิติระหว่างเกม เก็บไว้ใช้ในประวัติการแข่ง
+    DamageDealt     int `json:"damage_dealt"`
+    GoldSpent       int `json:"gold_spent"`
+    LastRound       int `json:"last_round,omitempty"` // รอบสุดท้ายที่ยังอยู่ในเกม
+
+    lastAutoPilotAt time.Time
 }
 
 type GameAction struct {
@@ -39,16 +76,49 @@ type GameAction struct {
     PlayerID  string     `json:"player_id"`
     TargetID  string     `json:"target_id,omitempty"`
     ItemID    string     `json:"item_id,omitempty"`
+    Round     int        `json:"round,omitempty"`
+    Phase     GamePhase  `json:"phase,omitempty"`
     Timestamp time.Time  `json:"timestamp"`
 }
 
 type Game struct {
     ID        string       `json:"id"`
+    HostID    string       `json:"host_id"`
     Players   []*Player    `json:"players"`
     Status    GameStatus   `json:"status"`
-    Actions   []GameAction `json:"actions"`
-    CreatedAt time.Time    `json:"created_at"`
-    UpdatedAt time.Time    `json:"updated_at"`
+    Mode      GameMode     `json:"mode"`
+    Teams     []*Team      `json:"teams,omitempty"`
+
+    // ตั้งค่าล็อบบี้
+    Private  bool         `json:"private"`
+    JoinCode string       `json:"join_code,omitempty"`
+    Password string       `json:"-"`
+    Settings GameSettings `json:"settings"`
+
+    // เกมจาก matchmaking queue นับแรงค์
+    Ranked bool `json:"ranked"`
+
+    // เติมบอทให้อัตโนมัติถ้ารอนานเกินไป (ใช้กับเกมที่สร้างจาก AutoMatch)
+    BotBackfill bool `json:"bot_backfill"`
+
+    // รอบและเฟสของเกม เดินตามเวลาใน Settings
+    Round       int       `json:"round"`
+    Phase       GamePhase `json:"phase,omitempty"`
+    PhaseEndsAt time.Time `json:"phase_ends_at,omitempty"`
+
+    Actions    []GameAction `json:"actions"`
+    CreatedAt  time.Time    `json:"created_at"`
+    UpdatedAt  time.Time    `json:"updated_at"`
+    StartedAt  time.Time    `json:"started_at,omitempty"`
+    FinishedAt time.Time    `json:"finished_at,omitempty"`
+
+    // seed ของการสุ่มในเกม (บอท) ใช้คู่กับประวัติ action
+    Seed int64 `json:"seed"`
+    rng  *rand.Rand
+
+    // สถานะตอนเริ่มเกมและตำแหน่งของ action แรกหลังเริ่ม ใช้สร้าง replay
+    startPlayers []Player
+    startAction  int
 }
 
 type ItemAction struct {
@@ -67,4 +137,29 @@ type Item struct {
     Health      int      `json:"health"`
     Cost        int      `json:"cost"`
     Description string   `json:"description"`
+}
+
+func NewGame(id string) *Game {
+    now := time.Now()
+    return &Game{
+        ID:        id,
+        Status:    StatusWaiting,
+        Mode:      ModeClassic,
+        Players:   []*Player{},
+        Settings:  DefaultGameSettings(),
+        Seed:      now.UnixNano(),
+        CreatedAt: now,
+        UpdatedAt: now,
+    }
+}
+
+// Duration คือเวลาที่เล่นจริงตั้งแต่เริ่มจนจบ เกมที่ยังไม่จบนับถึงปัจจุบัน
+func (g *Game) Duration() time.Duration {
+    if g.StartedAt.IsZero() {
+        return 0
+    }
+    if g.FinishedAt.IsZero() {
+        return time.Since(g.StartedAt)
+    }
+    return g.FinishedAt.Sub(g.StartedAt)
 }
\ No newline at end of file
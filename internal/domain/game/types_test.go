@@ -12,15 +12,15 @@ func TestNewGame(t *testing.T) {
         t.Errorf("expected game ID %s, got %s", gameID, game.ID)
     }
 
-    if game.State != StateWaiting {
-        t.Errorf("expected initial state %s, got %s", StateWaiting, game.State)
+    if game.Status != StatusWaiting {
+        t.Errorf("expected initial status %s, got %s", StatusWaiting, game.Status)
     }
 
-    if game.Round != 0 {
-        t.Errorf("expected initial round 0, got %d", game.Round)
+    if game.Mode != ModeClassic {
+        t.Errorf("expected initial mode %s, got %s", ModeClassic, game.Mode)
     }
 
     if len(game.Players) != 0 {
-        t.Errorf("expected empty players map, got %d players", len(game.Players))
+        t.Errorf("expected empty players, got %d players", len(game.Players))
     }
 }
\ No newline at end of file
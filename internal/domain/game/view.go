@@ -0,0 +1,137 @@
+package game
+
+// ViewerRole คือประเภทของผู้ที่ดูสถานะเกม แต่ละประเภทมี ViewPolicy ของตัวเอง
+type ViewerRole string
+
+const (
+    ViewerPlayer    ViewerRole = "player"    // ผู้เล่นในเกม เห็นข้อมูลของตัวเองและเพื่อนร่วมทีมเต็ม
+    ViewerSpectator ViewerRole = "spectator" // คนนอกเกม เห็นเฉพาะข้อมูลสาธารณะ
+    ViewerAdmin     ViewerRole = "admin"     // ผู้ดูแล เห็นทุกอย่าง
+)
+
+// Viewer คือผู้ที่จะได้รับสถานะเกม
+type Viewer struct {
+    PlayerID string
+    Role     ViewerRole
+}
+
+// ViewPolicy บอกว่าผู้ชมประเภทหนึ่งเห็นข้อมูลส่วนตัวอะไรบ้าง
+// ข้อมูลส่วนตัวคือทอง ไอเทม และทองที่ใช้ไปของผู้เล่น กับ action ซื้อ ใช้ และส่งไอเทม
+type ViewPolicy struct {
+    OtherPlayers bool // เห็นข้อมูลส่วนตัวของผู้เล่นที่ไม่ใช่ตัวเองหรือเพื่อนร่วมทีม
+    JoinCode     bool
+    Seed         bool // seed ทำให้เดาการสุ่มของบอทได้
+}
+
+var viewPolicies = map[ViewerRole]ViewPolicy{
+    ViewerPlayer:    {JoinCode: true},
+    ViewerSpectator: {},
+    ViewerAdmin:     {OtherPlayers: true, JoinCode: true, Seed: true},
+}
+
+// action ที่ทุกคนเห็นได้ ที่เหลือเห็นเฉพาะคนที่เห็นข้อมูลส่วนตัวของผู้ทำ
+var publicActions = map[ActionType]bool{
+    ActionAttack:    true,
+    ActionForfeit:   true,
+    ActionAutoPilot: true,
+    ActionSurrender: true,
+    ActionPhase:     true,
+}
+
+// ViewerOf คืน Viewer ของผู้เล่นคนหนึ่งต่อเกมนี้ คนที่ไม่ได้อยู่ในเกมเป็นผู้ชม
+func ViewerOf(g *Game, playerID string, admin bool) Viewer {
+    switch {
+    case admin:
+        return Viewer{PlayerID: playerID, Role: ViewerAdmin}
+    case g.findPlayer(playerID) != nil:
+        return Viewer{PlayerID: playerID, Role: ViewerPlayer}
+    default:
+        return Viewer{PlayerID: playerID, Role: ViewerSpectator}
+    }
+}
+
+// ViewFor คืนสำเนาของเกมที่ตัดข้อมูลที่ viewer ไม่ควรเห็นออกแล้ว สำเนาไม่แชร์ผู้เล่น ทีม และ action กับเกมจริง
+// จึงอ่านต่อได้หลังปล่อย lock แต่ตอนสร้างต้องถือ lock ของ GameManager เช่นใน callback ของ SetOnGameUpdate
+// ที่อื่นใช้ GameManager.View แทน
+func (g *Game) ViewFor(viewer Viewer) *Game {
+    policy := viewPolicies[viewer.Role]
+    view := *g
+
+    view.Players = make([]*Player, len(g.Players))
+    for i, p := range g.Players {
+        copied := *p
+        if policy.OtherPlayers || g.sharesPrivate(viewer, p.ID) {
+            copied.Inventory = append([]Item(nil), p.Inventory...)
+        } else {
+            copied.Gold = 0
+            copied.Inventory = nil
+            copied.GoldSpent = 0
+        }
+        view.Players[i] = &copied
+    }
+
+    if g.Teams != nil {
+        view.Teams = make([]*Team, len(g.Teams))
+        for i, team := range g.Teams {
+            copied := *team
+            copied.Members = append([]string(nil), team.Members...)
+            view.Teams[i] = &copied
+        }
+    }
+
+    view.Actions = make([]GameAction, 0, len(g.Actions))
+    for _, action := range g.Actions {
+        if policy.OtherPlayers || publicActions[action.Type] || g.sharesPrivate(viewer, action.PlayerID) {
+            view.Actions = append(view.Actions, action)
+        }
+    }
+
+    if !policy.JoinCode {
+        view.JoinCode = ""
+    }
+    if !policy.Seed {
+        view.Seed = 0
+    }
+    return &view
+}
+
+// View คืนเกม gameID ในมุมของผู้เล่น playerID พร้อม Viewer ที่ใช้ คนที่ไม่ได้อยู่ในเกมเป็นผู้ชม
+// สร้างขณะถือ lock เกมที่กำลังเดินอยู่จึงไม่ถูกอ่านระหว่างที่ถูกแก้
+func (m *GameManager) View(gameID, playerID string, admin bool) (*Game, Viewer, error) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    game, exists := m.games[gameID]
+    if !exists {
+        return nil, Viewer{}, ErrGameNotFound
+    }
+    viewer := ViewerOf(game, playerID, admin)
+    return game.ViewFor(viewer), viewer, nil
+}
+
+// WaitingGamesView คืนเกมที่รอผู้เล่นแบบ GetWaitingGames แต่ละเกมอยู่ในมุมของผู้เล่น playerID
+// playerID ว่างคือมุมของผู้ชม
+func (m *GameManager) WaitingGamesView(playerID string, admin bool) []*Game {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    var views []*Game
+    for _, game := range m.games {
+        if game.isOpen() {
+            views = append(views, game.ViewFor(ViewerOf(game, playerID, admin)))
+        }
+    }
+    return views
+}
+
+// sharesPrivate บอกว่า viewer เห็นข้อมูลส่วนตัวของผู้เล่น playerID หรือไม่ คือตัวเองหรือเพื่อนร่วมทีม
+func (g *Game) sharesPrivate(viewer Viewer, playerID string) bool {
+    if viewer.Role != ViewerPlayer {
+        return false
+    }
+    if playerID == viewer.PlayerID {
+        return true
+    }
+    self, other := g.findPlayer(viewer.PlayerID), g.findPlayer(playerID)
+    return self != nil && other != nil && self.TeamID != "" && self.TeamID == other.TeamID
+}
\ No newline at end of file
@@ -0,0 +1,129 @@
+package game
+
+import (
+    "encoding/json"
+    "sync"
+    "testing"
+)
+
+func TestViewFor(t *testing.T) {
+    newGame := func() *Game {
+        return &Game{
+            ID:       "game_1",
+            JoinCode: "ABC123",
+            Seed:     42,
+            Players: []*Player{
+                {ID: "alice", Gold: 30, GoldSpent: 10, Inventory: []Item{{ID: "sword"}}, TeamID: "team_1"},
+                {ID: "bob", Gold: 20, Inventory: []Item{{ID: "shield"}}, TeamID: "team_1"},
+                {ID: "carol", Gold: 50, GoldSpent: 5, Inventory: []Item{{ID: "potion"}}, TeamID: "team_2"},
+            },
+            Actions: []GameAction{
+                {Type: ActionBuyItem, PlayerID: "alice", ItemID: "sword"},
+                {Type: ActionBuyItem, PlayerID: "carol", ItemID: "potion"},
+                {Type: ActionAttack, PlayerID: "carol", TargetID: "alice"},
+            },
+        }
+    }
+
+    tests := []struct {
+        name     string
+        viewer   Viewer
+        private  map[string]bool // ผู้เล่นที่ต้องเห็นทองและไอเทม
+        actions  int
+        joinCode bool
+        seed     bool
+    }{
+        {"Player Sees Self And Teammate", Viewer{PlayerID: "alice", Role: ViewerPlayer}, map[string]bool{"alice": true, "bob": true}, 2, true, false},
+        {"Opponent Sees Only Self", Viewer{PlayerID: "carol", Role: ViewerPlayer}, map[string]bool{"carol": true}, 2, true, false},
+        {"Spectator Sees Public Only", Viewer{PlayerID: "dave", Role: ViewerSpectator}, map[string]bool{}, 1, false, false},
+        {"Admin Sees Everything", Viewer{PlayerID: "root", Role: ViewerAdmin}, map[string]bool{"alice": true, "bob": true, "carol": true}, 3, true, true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            g := newGame()
+            view := g.ViewFor(tt.viewer)
+
+            for i, p := range view.Players {
+                original := g.Players[i]
+                visible := p.Gold == original.Gold && len(p.Inventory) == len(original.Inventory) && p.GoldSpent == original.GoldSpent
+                if tt.private[p.ID] && !visible {
+                    t.Errorf("expected %s to see %s's gold and items", tt.viewer.PlayerID, p.ID)
+                }
+                if !tt.private[p.ID] && (p.Gold != 0 || p.Inventory != nil || p.GoldSpent != 0) {
+                    t.Errorf("expected %s's gold and items hidden from %s", p.ID, tt.viewer.PlayerID)
+                }
+            }
+            if len(view.Actions) != tt.actions {
+                t.Errorf("expected %d actions, got %d", tt.actions, len(view.Actions))
+            }
+            if (view.JoinCode != "") != tt.joinCode || (view.Seed != 0) != tt.seed {
+                t.Errorf("unexpected join code %q or seed %d", view.JoinCode, view.Seed)
+            }
+
+            // เกมจริงต้องไม่ถูกแก้
+            if g.Players[2].Gold != 50 || len(g.Actions) != 3 || g.Seed != 42 {
+                t.Error("expected original game to be unchanged")
+            }
+        })
+    }
+
+    t.Run("Viewer Of", func(t *testing.T) {
+        g := newGame()
+        if v := ViewerOf(g, "alice", false); v.Role != ViewerPlayer {
+            t.Errorf("expected player, got %s", v.Role)
+        }
+        if v := ViewerOf(g, "dave", false); v.Role != ViewerSpectator {
+            t.Errorf("expected spectator, got %s", v.Role)
+        }
+        if v := ViewerOf(g, "alice", true); v.Role != ViewerAdmin {
+            t.Errorf("expected admin, got %s", v.Role)
+        }
+    })
+}
+
+func TestManagerView(t *testing.T) {
+    gm, ids := newTestManager(t, "alice", "bob", "carol")
+    g := startTestGame(t, gm, ids[:2])
+
+    t.Run("Roles Follow Membership", func(t *testing.T) {
+        if _, viewer, _ := gm.View(g.ID, ids[0], false); viewer.Role != ViewerPlayer {
+            t.Errorf("expected player, got %s", viewer.Role)
+        }
+        if _, viewer, _ := gm.View(g.ID, ids[2], false); viewer.Role != ViewerSpectator {
+            t.Errorf("expected spectator, got %s", viewer.Role)
+        }
+        if _, _, err := gm.View("missing", ids[0], false); err != ErrGameNotFound {
+            t.Errorf("expected %v, got %v", ErrGameNotFound, err)
+        }
+    })
+
+    t.Run("View Does Not Share State With Running Game", func(t *testing.T) {
+        view, _, _ := gm.View(g.ID, ids[0], false)
+        gold := view.Players[0].Gold
+
+        // จำลอง goroutine ที่เดินเกมอยู่ แก้เกมขณะถือ lock ระหว่างที่อีกฝั่งอ่านสำเนา
+        var wg sync.WaitGroup
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for i := 0; i < 100; i++ {
+                gm.mu.Lock()
+                g.Players[0].Gold++
+                g.Players[0].Inventory = append(g.Players[0].Inventory, Item{ID: "sword"})
+                g.Actions = append(g.Actions, GameAction{Type: ActionBuyItem, PlayerID: ids[0]})
+                gm.mu.Unlock()
+            }
+        }()
+        for i := 0; i < 100; i++ {
+            view, _, _ := gm.View(g.ID, ids[0], false)
+            if _, err := json.Marshal(view); err != nil {
+                t.Fatalf("failed to encode view: %v", err)
+            }
+        }
+        wg.Wait()
+
+        if view.Players[0].Gold != gold {
+            t.Errorf("expected earlier view to keep gold %d, got %d", gold, view.Players[0].Gold)
+        }
+    })
+}
\ No newline at end of file
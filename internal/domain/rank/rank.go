@@ -0,0 +1,141 @@
+package rank
+
+import (
+    "fmt"
+)
+
+type Tier string
+
+const (
+    Iron        Tier = "iron"
+    Bronze      Tier = "bronze"
+    Silver      Tier = "silver"
+    Gold        Tier = "gold"
+    Platinum    Tier = "platinum"
+    Diamond     Tier = "diamond"
+    Master      Tier = "master"
+    Grandmaster Tier = "grandmaster"
+    Challenger  Tier = "challenger"
+)
+
+// tiers เรียงจากต่ำไปสูง
+var tiers = []Tier{Iron, Bronze, Silver, Gold, Platinum, Diamond, Master, Grandmaster, Challenger}
+
+const (
+    Divisions   = 4   // IV ถึง I ในแต่ละ tier ที่ต่ำกว่า Master
+    PromoteAt   = 100 // LP ที่ต้องมีเพื่อขึ้น division ถัดไป
+    DemotedLP   = 75  // LP หลังตกลงมา division ก่อนหน้า
+    MaxLPChange = 40  // LP ที่ได้/เสียสูงสุดต่อเกม
+
+    // tier ระดับ apex ไม่มี division ใช้ LP สะสมตัดสินแทน
+    grandmasterLP = 200
+    challengerLP  = 500
+)
+
+// Rank คือแรงค์ที่ผู้เล่นเห็น Division 4 คือ IV และ 1 คือ I ส่วน tier apex เป็น 0
+type Rank struct {
+    Tier     Tier `json:"tier"`
+    Division int  `json:"division,omitempty"`
+    LP       int  `json:"lp"`
+}
+
+// Starting คือแรงค์เริ่มต้นของทุกคนในแต่ละ season
+func Starting() Rank {
+    return Rank{Tier: Iron, Division: Divisions, LP: 0}
+}
+
+func (r Rank) IsApex() bool {
+    return r.Tier == Master || r.Tier == Grandmaster || r.Tier == Challenger
+}
+
+func (r Rank) String() string {
+    if r.IsApex() {
+        return fmt.Sprintf("%s %d LP", r.Tier, r.LP)
+    }
+    numerals := map[int]string{1: "I", 2: "II", 3: "III", 4: "IV"}
+    return fmt.Sprintf("%s %s %d LP", r.Tier, numerals[r.Division], r.LP)
+}
+
+// LPChange คือ LP ที่ได้จากอันดับในล็อบบี้ ครึ่งบนได้ ครึ่งล่างเสีย
+func LPChange(placement, lobbySize int) int {
+    if lobbySize < 2 || placement < 1 || placement > lobbySize {
+        return 0
+    }
+    // อันดับที่ 1 ได้ MaxLPChange อันดับสุดท้ายเสีย MaxLPChange ไล่ระดับตรงกลาง
+    numerator := MaxLPChange * (lobbySize + 1 - 2*placement)
+    denominator := lobbySize - 1
+    if numerator >= 0 {
+        return (numerator + denominator/2) / denominator
+    }
+    return -((-numerator + denominator/2) / denominator)
+}
+
+// Apply เพิ่มหรือลด LP แล้วจัดการการเลื่อนขั้นและตกชั้น
+func (r Rank) Apply(lp int) Rank {
+    r.LP += lp
+
+    if r.IsApex() {
+        return r.settleApex()
+    }
+
+    for r.LP >= PromoteAt {
+        r.LP -= PromoteAt
+        if r.Division > 1 {
+            r.Division--
+            continue
+        }
+        next := nextTier(r.Tier)
+        if next == Master {
+            return Rank{Tier: Master, LP: r.LP}.settleApex()
+        }
+        r.Tier, r.Division = next, Divisions
+    }
+
+    if r.LP < 0 {
+        switch {
+        case r.Tier == Iron && r.Division == Divisions:
+            // ต่ำสุดแล้ว ไม่ตกต่อ
+            r.LP = 0
+        case r.Division < Divisions:
+            r.Division++
+            r.LP = DemotedLP
+        default:
+            r.Tier, r.Division, r.LP = previousTier(r.Tier), 1, DemotedLP
+        }
+    }
+    return r
+}
+
+// settleApex เลือก tier apex ตาม LP สะสม ถ้าติดลบจะตกกลับไป Diamond I
+func (r Rank) settleApex() Rank {
+    switch {
+    case r.LP < 0:
+        return Rank{Tier: Diamond, Division: 1, LP: DemotedLP}
+    case r.LP >= challengerLP:
+        r.Tier = Challenger
+    case r.LP >= grandmasterLP:
+        r.Tier = Grandmaster
+    default:
+        r.Tier = Master
+    }
+    r.Division = 0
+    return r
+}
+
+func nextTier(t Tier) Tier {
+    for i, tier := range tiers {
+        if tier == t && i+1 < len(tiers) {
+            return tiers[i+1]
+        }
+    }
+    return t
+}
+
+func previousTier(t Tier) Tier {
+    for i, tier := range tiers {
+        if tier == t && i > 0 {
+            return tiers[i-1]
+        }
+    }
+    return t
+}
\ No newline at end of file
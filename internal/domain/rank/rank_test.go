@@ -0,0 +1,45 @@
+package rank
+
+import (
+    "testing"
+)
+
+func TestLPChange(t *testing.T) {
+    expected := []int{40, 29, 17, 6, -6, -17, -29, -40}
+    for i, want := range expected {
+        if got := LPChange(i+1, 8); got != want {
+            t.Errorf("placement %d of 8: expected %d LP, got %d", i+1, want, got)
+        }
+    }
+
+    if got := LPChange(1, 2); got != MaxLPChange {
+        t.Errorf("expected 1v1 winner to get %d LP, got %d", MaxLPChange, got)
+    }
+}
+
+func TestApply(t *testing.T) {
+    tests := []struct {
+        name string
+        from Rank
+        lp   int
+        want Rank
+    }{
+        {"Gain Within Division", Rank{Silver, 3, 10}, 20, Rank{Silver, 3, 30}},
+        {"Promote Division", Rank{Silver, 3, 90}, 20, Rank{Silver, 2, 10}},
+        {"Promote Tier", Rank{Iron, 1, 90}, 20, Rank{Bronze, 4, 10}},
+        {"Demote Division", Rank{Gold, 2, 5}, -20, Rank{Gold, 3, DemotedLP}},
+        {"Demote Tier", Rank{Bronze, 4, 5}, -20, Rank{Iron, 1, DemotedLP}},
+        {"Floor At Iron IV", Rank{Iron, 4, 5}, -20, Rank{Iron, 4, 0}},
+        {"Enter Master", Rank{Diamond, 1, 90}, 20, Rank{Master, 0, 10}},
+        {"Climb To Grandmaster", Rank{Master, 0, 190}, 20, Rank{Grandmaster, 0, 210}},
+        {"Fall From Master", Rank{Master, 0, 10}, -20, Rank{Diamond, 1, DemotedLP}},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := tt.from.Apply(tt.lp); got != tt.want {
+                t.Errorf("%s %+d: expected %s, got %s", tt.from, tt.lp, tt.want, got)
+            }
+        })
+    }
+}
\ No newline at end of file
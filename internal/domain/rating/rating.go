@@ -0,0 +1,185 @@
+package rating
+
+import (
+    "math"
+)
+
+// Rating คือค่าความเก่งของผู้เล่น Elo ใช้แค่ Value ส่วน Glicko-2 ใช้ครบทั้งสามค่า
+type Rating struct {
+    Value      float64 `json:"rating"`
+    Deviation  float64 `json:"deviation"`
+    Volatility float64 `json:"volatility"`
+}
+
+// Result คืออันดับของผู้เล่นหนึ่งคนในเกม พร้อม rating ก่อนเริ่มเกม
+type Result struct {
+    PlayerID  string
+    Placement int
+    Rating    Rating
+}
+
+// Rater คำนวณ rating ใหม่ของทุกคนจากอันดับในเกมเดียว
+// เกมแบบหลายคนถูกแยกเป็นคู่ๆ คนที่อันดับดีกว่าถือว่าชนะคนที่อันดับแย่กว่า
+type Rater interface {
+    Rate(results []Result) map[string]Rating
+}
+
+const (
+    DefaultDeviation  = 350
+    DefaultVolatility = 0.06
+)
+
+func NewRating(value float64) Rating {
+    return Rating{
+        Value:      value,
+        Deviation:  DefaultDeviation,
+        Volatility: DefaultVolatility,
+    }
+}
+
+// score คือผลของ a เมื่อเทียบกับ b: ชนะ 1 เสมอ 0.5 แพ้ 0
+func score(a, b Result) float64 {
+    switch {
+    case a.Placement < b.Placement:
+        return 1
+    case a.Placement > b.Placement:
+        return 0
+    default:
+        return 0.5
+    }
+}
+
+// Elo แบบหลายคน แบ่ง K ตามจำนวนคู่แข่งเพื่อให้การเปลี่ยนแปลงต่อเกมใกล้เคียงเกม 1v1
+type Elo struct {
+    K float64
+}
+
+func NewElo() *Elo {
+    return &Elo{K: 32}
+}
+
+func (e *Elo) Rate(results []Result) map[string]Rating {
+    updated := make(map[string]Rating, len(results))
+    if len(results) < 2 {
+        return updated
+    }
+
+    k := e.K / float64(len(results)-1)
+    for _, self := range results {
+        delta := 0.0
+        for _, other := range results {
+            if other.PlayerID == self.PlayerID {
+                continue
+            }
+            expected := 1 / (1 + math.Pow(10, (other.Rating.Value-self.Rating.Value)/400))
+            delta += score(self, other) - expected
+        }
+
+        r := self.Rating
+        r.Value += k * delta
+        updated[self.PlayerID] = r
+    }
+    return updated
+}
+
+// Glicko2 ตามบทความของ Glickman ถือว่าแต่ละเกมเป็นหนึ่ง rating period
+type Glicko2 struct {
+    Center float64 // rating ที่ตรงกับ mu = 0
+    Tau    float64 // ควบคุมความเร็วการเปลี่ยน volatility
+}
+
+const (
+    glickoScale       = 173.7178
+    glickoConvergence = 0.000001
+)
+
+func NewGlicko2(center float64) *Glicko2 {
+    return &Glicko2{Center: center, Tau: 0.5}
+}
+
+func (g *Glicko2) Rate(results []Result) map[string]Rating {
+    updated := make(map[string]Rating, len(results))
+    if len(results) < 2 {
+        return updated
+    }
+
+    for _, self := range results {
+        var opponents []Rating
+        var scores []float64
+        for _, other := range results {
+            if other.PlayerID == self.PlayerID {
+                continue
+            }
+            opponents = append(opponents, other.Rating)
+            scores = append(scores, score(self, other))
+        }
+        updated[self.PlayerID] = g.update(self.Rating, opponents, scores)
+    }
+    return updated
+}
+
+// update คำนวณ rating ใหม่จากผลกับคู่แข่งทุกคนใน period เดียว
+func (g *Glicko2) update(self Rating, opponents []Rating, scores []float64) Rating {
+    mu := (self.Value - g.Center) / glickoScale
+    phi := self.Deviation / glickoScale
+    sigma := self.Volatility
+
+    var vInv, sum float64
+    for i, opp := range opponents {
+        muJ := (opp.Value - g.Center) / glickoScale
+        gPhi := 1 / math.Sqrt(1+3*math.Pow(opp.Deviation/glickoScale, 2)/(math.Pi*math.Pi))
+        expected := 1 / (1 + math.Exp(-gPhi*(mu-muJ)))
+
+        vInv += gPhi * gPhi * expected * (1 - expected)
+        sum += gPhi * (scores[i] - expected)
+    }
+    v := 1 / vInv
+    delta := v * sum
+
+    sigma = g.volatility(phi, sigma, v, delta)
+
+    phiStar := math.Sqrt(phi*phi + sigma*sigma)
+    phiNew := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+    muNew := mu + phiNew*phiNew*sum
+
+    return Rating{
+        Value:      muNew*glickoScale + g.Center,
+        Deviation:  phiNew * glickoScale,
+        Volatility: sigma,
+    }
+}
+
+// volatility หา sigma ใหม่ด้วยวิธี Illinois (ขั้นตอนที่ 5 ในบทความ)
+func (g *Glicko2) volatility(phi, sigma, v, delta float64) float64 {
+    a := math.Log(sigma * sigma)
+    f := func(x float64) float64 {
+        ex := math.Exp(x)
+        d := phi*phi + v + ex
+        return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(g.Tau*g.Tau)
+    }
+
+    A := a
+    var B float64
+    if delta*delta > phi*phi+v {
+        B = math.Log(delta*delta - phi*phi - v)
+    } else {
+        k := 1.0
+        for f(a-k*g.Tau) < 0 {
+            k++
+        }
+        B = a - k*g.Tau
+    }
+
+    fA, fB := f(A), f(B)
+    for math.Abs(B-A) > glickoConvergence {
+        C := A + (A-B)*fA/(fB-fA)
+        fC := f(C)
+        if fC*fB <= 0 {
+            A, fA = B, fB
+        } else {
+            fA /= 2
+        }
+        B, fB = C, fC
+    }
+    return math.Exp(A / 2)
+}
\ No newline at end of file
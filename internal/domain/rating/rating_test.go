@@ -0,0 +1,55 @@
+package rating
+
+import (
+    "math"
+    "testing"
+)
+
+func TestGlicko2(t *testing.T) {
+    t.Run("Paper Example", func(t *testing.T) {
+        // ตัวอย่างในบทความ Glicko-2 ของ Glickman
+        g := NewGlicko2(1500)
+        self := Rating{Value: 1500, Deviation: 200, Volatility: 0.06}
+        opponents := []Rating{
+            {Value: 1400, Deviation: 30},
+            {Value: 1550, Deviation: 100},
+            {Value: 1700, Deviation: 300},
+        }
+
+        r := g.update(self, opponents, []float64{1, 0, 0})
+
+        if math.Abs(r.Value-1464.06) > 0.01 {
+            t.Errorf("expected rating 1464.06, got %.2f", r.Value)
+        }
+        if math.Abs(r.Deviation-151.52) > 0.01 {
+            t.Errorf("expected deviation 151.52, got %.2f", r.Deviation)
+        }
+        if math.Abs(r.Volatility-0.05999) > 0.00001 {
+            t.Errorf("expected volatility 0.05999, got %.5f", r.Volatility)
+        }
+    })
+
+    t.Run("Placements Order Ratings", func(t *testing.T) {
+        results := make([]Result, 8)
+        for i := range results {
+            results[i] = Result{
+                PlayerID:  string(rune('a' + i)),
+                Placement: i + 1,
+                Rating:    NewRating(1000),
+            }
+        }
+
+        for _, rater := range []Rater{NewGlicko2(1000), NewElo()} {
+            updated := rater.Rate(results)
+            for i := 1; i < len(results); i++ {
+                better, worse := updated[results[i-1].PlayerID], updated[results[i].PlayerID]
+                if better.Value <= worse.Value {
+                    t.Errorf("%T: expected place %d above place %d, got %.1f and %.1f", rater, i, i+1, better.Value, worse.Value)
+                }
+            }
+            if first := updated["a"]; first.Value <= 1000 {
+                t.Errorf("%T: expected winner to gain rating, got %.1f", rater, first.Value)
+            }
+        }
+    })
+}
\ No newline at end of file
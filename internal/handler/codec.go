@@ -0,0 +1,135 @@
+package handler
+
+import (
+    "encoding/json"
+    "fmt"
+
+    "github.com/gorilla/websocket"
+    "github.com/tem-mars/tft-game-server/pkg/protovalue"
+    "google.golang.org/protobuf/encoding/protowire"
+)
+
+// subprotocol ที่ client เลือกได้ผ่าน Sec-WebSocket-Protocol ถ้าไม่เลือกจะได้ JSON
+const (
+    SubprotocolJSON  = "tft.json.v1"
+    SubprotocolProto = "tft.proto.v1" // Envelope ใน protocol.proto เป็น binary frame
+)
+
+// codec แปลง Envelope เป็นข้อความบน WebSocket และกลับ ทุก encoding ใช้ Envelope และ payload ชุดเดียวกัน
+type codec interface {
+    encode(env Envelope) (messageType int, data []byte, err error)
+    decode(data []byte) (Envelope, error)
+}
+
+// codecFor คืน codec ของ subprotocol ที่ตกลงกันได้ตอน upgrade
+func codecFor(subprotocol string) codec {
+    if subprotocol == SubprotocolProto {
+        return protoCodec{}
+    }
+    return jsonCodec{}
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) encode(env Envelope) (int, []byte, error) {
+    data, err := json.Marshal(env)
+    return websocket.TextMessage, data, err
+}
+
+func (jsonCodec) decode(data []byte) (Envelope, error) {
+    var env Envelope
+    err := json.Unmarshal(data, &env)
+    return env, err
+}
+
+// หมายเลขฟิลด์ของ Envelope ใน protocol.proto
+const (
+    envelopeV         protowire.Number = 1
+    envelopeType      protowire.Number = 2
+    envelopeRequestID protowire.Number = 3
+    envelopePayload   protowire.Number = 4
+    envelopeKeys      protowire.Number = 5
+    envelopeTopic     protowire.Number = 6
+)
+
+// protoCodec เข้ารหัส Envelope เป็น protobuf ส่วน payload เป็น Value ที่แปลงจาก JSON payload เดิม
+// พร้อมตาราง key ของ payload นั้น
+type protoCodec struct{}
+
+func (protoCodec) encode(env Envelope) (int, []byte, error) {
+    var b []byte
+    b = protowire.AppendTag(b, envelopeV, protowire.VarintType)
+    b = protowire.AppendVarint(b, uint64(env.V))
+    b = protowire.AppendTag(b, envelopeType, protowire.BytesType)
+    b = protowire.AppendString(b, env.Type)
+    if env.RequestID != "" {
+        b = protowire.AppendTag(b, envelopeRequestID, protowire.BytesType)
+        b = protowire.AppendString(b, env.RequestID)
+    }
+    if env.Topic != "" {
+        b = protowire.AppendTag(b, envelopeTopic, protowire.BytesType)
+        b = protowire.AppendString(b, env.Topic)
+    }
+    if len(env.Payload) > 0 {
+        encoder := protovalue.NewEncoder()
+        payload, err := encoder.FromJSON(env.Payload)
+        if err != nil {
+            return 0, nil, err
+        }
+        b = protowire.AppendTag(b, envelopePayload, protowire.BytesType)
+        b = protowire.AppendBytes(b, payload)
+        for _, key := range encoder.Keys() {
+            b = protowire.AppendTag(b, envelopeKeys, protowire.BytesType)
+            b = protowire.AppendString(b, key)
+        }
+    }
+    return websocket.BinaryMessage, b, nil
+}
+
+func (protoCodec) decode(data []byte) (Envelope, error) {
+    var env Envelope
+    var payload []byte
+    var keys []string
+    for len(data) > 0 {
+        num, typ, n := protowire.ConsumeTag(data)
+        if n < 0 {
+            return Envelope{}, protowire.ParseError(n)
+        }
+        data = data[n:]
+
+        switch {
+        case num == envelopeV && typ == protowire.VarintType:
+            var v uint64
+            v, n = protowire.ConsumeVarint(data)
+            env.V = int(v)
+        case num == envelopeType && typ == protowire.BytesType:
+            env.Type, n = protowire.ConsumeString(data)
+        case num == envelopeRequestID && typ == protowire.BytesType:
+            env.RequestID, n = protowire.ConsumeString(data)
+        case num == envelopeTopic && typ == protowire.BytesType:
+            env.Topic, n = protowire.ConsumeString(data)
+        case num == envelopePayload && typ == protowire.BytesType:
+            payload, n = protowire.ConsumeBytes(data)
+        case num == envelopeKeys && typ == protowire.BytesType:
+            var key string
+            key, n = protowire.ConsumeString(data)
+            keys = append(keys, key)
+        default:
+            n = protowire.ConsumeFieldValue(num, typ, data)
+        }
+        if n < 0 {
+            return Envelope{}, protowire.ParseError(n)
+        }
+        data = data[n:]
+    }
+
+    // ตาราง key อาจมาหลัง payload จึงถอด payload เมื่ออ่านครบแล้ว
+    if payload != nil {
+        raw, err := protovalue.ToJSON(payload, keys)
+        if err != nil {
+            return Envelope{}, fmt.Errorf("payload: %w", err)
+        }
+        env.Payload = raw
+    }
+    return env, nil
+}
\ No newline at end of file
@@ -0,0 +1,119 @@
+package handler
+
+import (
+    "context"
+    "encoding/json"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/golang-jwt/jwt/v5"
+    "github.com/gorilla/websocket"
+    "github.com/tem-mars/tft-game-server/internal/middleware"
+)
+
+func TestProtoCodec(t *testing.T) {
+    env := newEnvelope(MsgError, ErrorPayload{Code: ErrCodeRejected, Message: "not your turn"})
+    env.RequestID = "r1"
+    env.Topic = "game:g1"
+
+    messageType, data, err := protoCodec{}.encode(env)
+    if err != nil || messageType != websocket.BinaryMessage {
+        t.Fatalf("expected binary message, got %d (%v)", messageType, err)
+    }
+    decoded, err := protoCodec{}.decode(data)
+    if err != nil {
+        t.Fatalf("decode failed: %v", err)
+    }
+
+    var payload ErrorPayload
+    json.Unmarshal(decoded.Payload, &payload)
+    if decoded.V != env.V || decoded.Type != env.Type || decoded.RequestID != env.RequestID || decoded.Topic != env.Topic || payload.Message != "not your turn" {
+        t.Errorf("expected %+v, got %+v with %+v", env, decoded, payload)
+    }
+
+    if _, err := (protoCodec{}).decode([]byte{0x0a, 0x05, 'a'}); err == nil {
+        t.Error("expected error for truncated envelope")
+    }
+}
+
+func TestSubprotocolNegotiation(t *testing.T) {
+    router, gameManager, playerRepo, log := setupTestRouter()
+    alice, _ := playerRepo.Create(context.Background(), "alice", "alice@example.com", "secret")
+    bob, _ := playerRepo.Create(context.Background(), "bob", "bob@example.com", "secret")
+    played, _ := gameManager.CreateMatch([]string{alice.ID, bob.ID})
+
+    handler := NewGameHandler(gameManager, log, "test-secret")
+    router.GET("/games/ws", handler.HandleWebSocket)
+    server := httptest.NewServer(router)
+    defer server.Close()
+
+    token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &middleware.Claims{PlayerID: alice.ID}).SignedString([]byte("test-secret"))
+    url := "ws" + strings.TrimPrefix(server.URL, "http") + "/games/ws?token=" + token
+
+    tests := []struct {
+        name        string
+        requested   []string
+        subprotocol string
+        frame       int
+    }{
+        {"Default Is JSON", nil, "", websocket.TextMessage},
+        {"JSON Requested", []string{SubprotocolJSON}, SubprotocolJSON, websocket.TextMessage},
+        {"Binary Requested", []string{SubprotocolProto}, SubprotocolProto, websocket.BinaryMessage},
+        {"Server Prefers Binary", []string{SubprotocolJSON, SubprotocolProto}, SubprotocolProto, websocket.BinaryMessage},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            dialer := websocket.Dialer{Subprotocols: tt.requested}
+            conn, _, err := dialer.Dial(url, nil)
+            if err != nil {
+                t.Fatalf("failed to connect: %v", err)
+            }
+            defer conn.Close()
+
+            if conn.Subprotocol() != tt.subprotocol {
+                t.Fatalf("expected subprotocol %q, got %q", tt.subprotocol, conn.Subprotocol())
+            }
+            codec := codecFor(conn.Subprotocol())
+
+            // read อ่านข้อความถัดไปที่ไม่ใช่สถานะเกม
+            read := func() Envelope {
+                t.Helper()
+                for {
+                    frame, data, err := conn.ReadMessage()
+                    if err != nil {
+                        t.Fatalf("failed to read: %v", err)
+                    }
+                    if frame != tt.frame {
+                        t.Fatalf("expected frame type %d, got %d", tt.frame, frame)
+                    }
+                    env, err := codec.decode(data)
+                    if err != nil {
+                        t.Fatalf("failed to decode: %v", err)
+                    }
+                    if env.Type != MsgGameState && env.Type != MsgGamePatch {
+                        return env
+                    }
+                }
+            }
+
+            if welcome := read(); welcome.Type != MsgWelcome {
+                t.Fatalf("expected welcome, got %s", welcome.Type)
+            }
+
+            command := newEnvelope(CmdGetGameState, GameStateCommand{GameID: played.ID})
+            command.RequestID = "r1"
+            frame, data, _ := codec.encode(command)
+            if err := conn.WriteMessage(frame, data); err != nil {
+                t.Fatalf("failed to send: %v", err)
+            }
+
+            ack := read()
+            var snapshot GameStatePayload
+            json.Unmarshal(ack.Payload, &snapshot)
+            if ack.Type != MsgAck || ack.RequestID != "r1" || snapshot.GameID != played.ID {
+                t.Errorf("expected ack for r1 with game %s, got %s %q %s", played.ID, ack.Type, ack.RequestID, snapshot.GameID)
+            }
+        })
+    }
+}
\ No newline at end of file
@@ -0,0 +1,209 @@
+package handler
+
+import (
+    "sync"
+    "time"
+
+    "github.com/gorilla/websocket"
+)
+
+// SlowConsumerPolicy คือสิ่งที่ทำกับ client ที่อ่านไม่ทันจนคิวส่งเต็ม
+type SlowConsumerPolicy string
+
+const (
+    SlowConsumerDisconnect SlowConsumerPolicy = "disconnect" // ตัดการเชื่อมต่อ ให้ client ต่อใหม่เอง
+    SlowConsumerResync     SlowConsumerPolicy = "resync"     // ทิ้งข้อความที่ค้างแล้วบอกให้ client ขอสถานะใหม่
+)
+
+// WSConfig คือค่าของการเชื่อมต่อ WebSocket แต่ละเส้น
+type WSConfig struct {
+    SendQueueSize int                // จำนวนข้อความที่รอส่งได้ต่อการเชื่อมต่อ
+    WriteTimeout  time.Duration      // เวลาสูงสุดของการเขียนหนึ่งข้อความ
+    SlowConsumer  SlowConsumerPolicy // ทำอะไรเมื่อคิวเต็ม
+
+    // heartbeat ส่ง ping ทุก PingInterval ถ้าไม่ได้ข้อความหรือ pong ภายใน PongWait ถือว่าหลุด
+    PingInterval   time.Duration
+    PongWait       time.Duration // 0 = สองเท่าของ PingInterval
+    MaxMessageSize int64         // ขนาดสูงสุดของข้อความจาก client หน่วย byte
+}
+
+func DefaultWSConfig() WSConfig {
+    return WSConfig{
+        SendQueueSize:  64,
+        WriteTimeout:   10 * time.Second,
+        SlowConsumer:   SlowConsumerResync,
+        PingInterval:   30 * time.Second,
+        PongWait:       60 * time.Second,
+        MaxMessageSize: 4096,
+    }
+}
+
+// withDefaults เติมค่าที่ไม่ได้ตั้งด้วยค่าเริ่มต้น
+func (c WSConfig) withDefaults() WSConfig {
+    defaults := DefaultWSConfig()
+    if c.SendQueueSize <= 0 {
+        c.SendQueueSize = defaults.SendQueueSize
+    }
+    if c.WriteTimeout <= 0 {
+        c.WriteTimeout = defaults.WriteTimeout
+    }
+    if c.SlowConsumer == "" {
+        c.SlowConsumer = defaults.SlowConsumer
+    }
+    if c.PingInterval <= 0 {
+        c.PingInterval = defaults.PingInterval
+    }
+    // ต้องรอ pong นานกว่ารอบของ ping ไม่งั้นจะตัดคนที่ยังอยู่
+    if c.PongWait <= c.PingInterval {
+        c.PongWait = 2 * c.PingInterval
+    }
+    if c.MaxMessageSize <= 0 {
+        c.MaxMessageSize = defaults.MaxMessageSize
+    }
+    return c
+}
+
+// ผลของการใส่ข้อความลงคิว
+type enqueueResult int
+
+const (
+    enqueued enqueueResult = iota
+    enqueueResynced // คิวเต็ม ทิ้งข้อความที่ค้างและส่ง resync แทน
+    enqueueDropped  // คิวเต็มหรือปิดไปแล้ว ข้อความไม่ถูกส่ง
+)
+
+// wsConn ห่อ websocket.Conn ให้มี goroutine เขียนเพียงตัวเดียว
+// gorilla/websocket ไม่ให้เขียนพร้อมกันหลาย goroutine ทุกข้อความจึงต้องผ่าน enqueue
+type wsConn struct {
+    ws    *websocket.Conn
+    cfg   WSConfig
+    codec codec // encoding ที่ตกลงกันตอน upgrade
+    send  chan Envelope
+    done  chan struct{}
+
+    mu        sync.Mutex // กันไม่ให้สองคนจัดการคิวเต็มพร้อมกัน
+    closeOnce sync.Once
+}
+
+func newWSConn(ws *websocket.Conn, cfg WSConfig, codec codec) *wsConn {
+    cfg = cfg.withDefaults()
+    return &wsConn{
+        ws:    ws,
+        cfg:   cfg,
+        codec: codec,
+        send:  make(chan Envelope, cfg.SendQueueSize),
+        done:  make(chan struct{}),
+    }
+}
+
+// prepareRead ตั้งขนาดข้อความสูงสุดและ read deadline ที่ถูกต่อทุกครั้งที่ได้ pong
+// ต้องเรียกก่อนเริ่มอ่าน
+func (c *wsConn) prepareRead() {
+    c.ws.SetReadLimit(c.cfg.MaxMessageSize)
+    c.ws.SetReadDeadline(time.Now().Add(c.cfg.PongWait))
+    c.ws.SetPongHandler(func(string) error {
+        return c.ws.SetReadDeadline(time.Now().Add(c.cfg.PongWait))
+    })
+}
+
+// extendRead ต่อ read deadline หลังได้ข้อความจาก client
+func (c *wsConn) extendRead() {
+    c.ws.SetReadDeadline(time.Now().Add(c.cfg.PongWait))
+}
+
+// writeLoop เขียนข้อความจากคิวทีละอันและส่ง ping ตามรอบ จนกว่าจะถูกปิดหรือเขียนไม่สำเร็จ
+// เมื่อจบจะปิด websocket ทำให้ฝั่งอ่านจบตามไปด้วย
+func (c *wsConn) writeLoop() {
+    ticker := time.NewTicker(c.cfg.PingInterval)
+    defer ticker.Stop()
+    defer c.ws.Close()
+
+    for {
+        select {
+        case <-ticker.C:
+            c.ws.SetWriteDeadline(time.Now().Add(c.cfg.WriteTimeout))
+            if err := c.ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+                c.close()
+                return
+            }
+        case <-c.done:
+            c.flush()
+            c.ws.SetWriteDeadline(time.Now().Add(c.cfg.WriteTimeout))
+            c.ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+            return
+        case env := <-c.send:
+            if err := c.write(env); err != nil {
+                c.close()
+                return
+            }
+        }
+    }
+}
+
+// enqueue ใส่ข้อความลงคิวโดยไม่บล็อกผู้เรียก ถ้าคิวเต็มจะทำตาม SlowConsumerPolicy
+func (c *wsConn) enqueue(env Envelope) enqueueResult {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if c.closed() {
+        return enqueueDropped
+    }
+    select {
+    case c.send <- env:
+        return enqueued
+    default:
+    }
+
+    if c.cfg.SlowConsumer != SlowConsumerResync {
+        c.close()
+        return enqueueDropped
+    }
+
+    // ทิ้งข้อความที่ค้างทั้งหมด client จะขอสถานะเต็มเมื่อได้ resync
+    for drained := false; !drained; {
+        select {
+        case <-c.send:
+        default:
+            drained = true
+        }
+    }
+    c.send <- newEnvelope(MsgResync, nil)
+    return enqueueResynced
+}
+
+// flush เขียนข้อความที่ยังค้างในคิวก่อนปิด เช่นเหตุผลที่ถูกปิด
+func (c *wsConn) flush() {
+    for {
+        select {
+        case env := <-c.send:
+            if err := c.write(env); err != nil {
+                return
+            }
+        default:
+            return
+        }
+    }
+}
+
+// write เข้ารหัสข้อความด้วย codec ของการเชื่อมต่อแล้วเขียนลง websocket
+func (c *wsConn) write(env Envelope) error {
+    messageType, data, err := c.codec.encode(env)
+    if err != nil {
+        return err
+    }
+    c.ws.SetWriteDeadline(time.Now().Add(c.cfg.WriteTimeout))
+    return c.ws.WriteMessage(messageType, data)
+}
+
+func (c *wsConn) close() {
+    c.closeOnce.Do(func() { close(c.done) })
+}
+
+func (c *wsConn) closed() bool {
+    select {
+    case <-c.done:
+        return true
+    default:
+        return false
+    }
+}
\ No newline at end of file
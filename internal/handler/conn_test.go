@@ -0,0 +1,191 @@
+package handler
+
+import (
+    "context"
+    "net/http/httptest"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/golang-jwt/jwt/v5"
+    "github.com/gorilla/websocket"
+    "github.com/tem-mars/tft-game-server/internal/middleware"
+)
+
+func TestSlowConsumer(t *testing.T) {
+    t.Run("Resync Replaces Backlog", func(t *testing.T) {
+        // ไม่มี writer ทำงาน คิวจึงเต็มแน่นอน
+        client := newWSConn(nil, WSConfig{SendQueueSize: 2, SlowConsumer: SlowConsumerResync}, jsonCodec{})
+        for i := 0; i < 2; i++ {
+            if got := client.enqueue(newEnvelope(MsgGameState, nil)); got != enqueued {
+                t.Fatalf("expected message %d to be queued, got %v", i, got)
+            }
+        }
+
+        if got := client.enqueue(newEnvelope(MsgGameState, nil)); got != enqueueResynced {
+            t.Fatalf("expected resync on overflow, got %v", got)
+        }
+        if len(client.send) != 1 || (<-client.send).Type != MsgResync {
+            t.Error("expected the backlog to be replaced by a single resync")
+        }
+        if client.closed() {
+            t.Error("expected connection to stay open")
+        }
+    })
+
+    t.Run("Disconnect On Overflow", func(t *testing.T) {
+        client := newWSConn(nil, WSConfig{SendQueueSize: 1, SlowConsumer: SlowConsumerDisconnect}, jsonCodec{})
+        client.enqueue(newEnvelope(MsgGameState, nil))
+
+        if got := client.enqueue(newEnvelope(MsgGameState, nil)); got != enqueueDropped {
+            t.Fatalf("expected message to be dropped, got %v", got)
+        }
+        if !client.closed() {
+            t.Error("expected slow consumer to be disconnected")
+        }
+    })
+}
+
+func TestConcurrentSends(t *testing.T) {
+    router, gameManager, playerRepo, log := setupTestRouter()
+    alice, _ := playerRepo.Create(context.Background(), "alice", "alice@example.com", "secret")
+
+    handler := NewGameHandler(gameManager, log, "test-secret")
+    router.GET("/games/ws", handler.HandleWebSocket)
+    server := httptest.NewServer(router)
+    defer server.Close()
+
+    token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &middleware.Claims{PlayerID: alice.ID}).SignedString([]byte("test-secret"))
+    conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/games/ws?token="+token, nil)
+    if err != nil {
+        t.Fatalf("failed to connect: %v", err)
+    }
+    defer conn.Close()
+
+    var welcome Envelope
+    if err := conn.ReadJSON(&welcome); err != nil {
+        t.Fatalf("failed to read welcome: %v", err)
+    }
+
+    // เขียนจากหลาย goroutine พร้อมกัน ทุกข้อความต้องผ่าน writer เดียวโดยไม่ panic
+    const senders, perSender = 8, 4
+    var wg sync.WaitGroup
+    for i := 0; i < senders; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for j := 0; j < perSender; j++ {
+                handler.SendToPlayer(alice.ID, newEnvelope(MsgQueueStatus, nil))
+            }
+        }()
+    }
+    wg.Wait()
+
+    for received := 0; received < senders*perSender; received++ {
+        var env Envelope
+        if err := conn.ReadJSON(&env); err != nil {
+            t.Fatalf("failed after %d messages: %v", received, err)
+        }
+        if env.Type != MsgQueueStatus {
+            t.Fatalf("expected %s, got %s", MsgQueueStatus, env.Type)
+        }
+    }
+}
+
+func TestHeartbeat(t *testing.T) {
+    router, gameManager, playerRepo, log := setupTestRouter()
+    alice, _ := playerRepo.Create(context.Background(), "alice", "alice@example.com", "secret")
+
+    handler := NewGameHandler(gameManager, log, "test-secret")
+    handler.SetWSConfig(WSConfig{PingInterval: 20 * time.Millisecond, PongWait: 60 * time.Millisecond, MaxMessageSize: 512})
+    router.GET("/games/ws", handler.HandleWebSocket)
+    server := httptest.NewServer(router)
+    defer server.Close()
+
+    token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &middleware.Claims{PlayerID: alice.ID}).SignedString([]byte("test-secret"))
+    url := "ws" + strings.TrimPrefix(server.URL, "http") + "/games/ws?token=" + token
+
+    connected := func() bool {
+        handler.mu.RLock()
+        defer handler.mu.RUnlock()
+        _, exists := handler.connections[alice.ID]
+        return exists
+    }
+    // waitFor รอจนเงื่อนไขเป็นจริงหรือหมดเวลา
+    waitFor := func(cond func() bool) bool {
+        deadline := time.Now().Add(time.Second)
+        for time.Now().Before(deadline) {
+            if cond() {
+                return true
+            }
+            time.Sleep(5 * time.Millisecond)
+        }
+        return false
+    }
+
+    t.Run("Live Peer Stays Connected", func(t *testing.T) {
+        conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+        if err != nil {
+            t.Fatalf("failed to connect: %v", err)
+        }
+        defer conn.Close()
+
+        // client ที่อ่านอยู่ตลอดจะตอบ pong ให้อัตโนมัติ
+        go func() {
+            for {
+                if _, _, err := conn.ReadMessage(); err != nil {
+                    return
+                }
+            }
+        }()
+
+        time.Sleep(200 * time.Millisecond)
+        if !connected() {
+            t.Error("expected responsive peer to stay connected")
+        }
+    })
+
+    t.Run("Dead Peer Is Removed", func(t *testing.T) {
+        waitFor(func() bool { return !connected() })
+
+        conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+        if err != nil {
+            t.Fatalf("failed to connect: %v", err)
+        }
+        defer conn.Close()
+
+        // ไม่อ่านอะไรเลย จึงไม่มี pong กลับไป
+        if !waitFor(connected) {
+            t.Fatal("expected connection to be registered")
+        }
+        if !waitFor(func() bool { return !connected() }) {
+            t.Error("expected silent peer to be removed after pong wait")
+        }
+    })
+
+    t.Run("Oversized Message Closes Connection", func(t *testing.T) {
+        conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+        if err != nil {
+            t.Fatalf("failed to connect: %v", err)
+        }
+        defer conn.Close()
+
+        var welcome Envelope
+        conn.ReadJSON(&welcome)
+        conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat("x", 1024)))
+
+        conn.SetReadDeadline(time.Now().Add(time.Second))
+        for {
+            if _, _, err := conn.ReadMessage(); err != nil {
+                if websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+                    return
+                }
+                if !waitFor(func() bool { return !connected() }) {
+                    t.Errorf("expected oversized message to close the connection, got %v", err)
+                }
+                return
+            }
+        }
+    })
+}
\ No newline at end of file
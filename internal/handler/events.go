@@ -0,0 +1,277 @@
+package handler
+
+import (
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/gin-contrib/sse"
+    "github.com/gin-gonic/gin"
+    "github.com/tem-mars/tft-game-server/pkg/logger"
+)
+
+const (
+    eventBacklog   = 256             // จำนวนข้อความล่าสุดที่เก็บไว้ให้ต่อด้วย Last-Event-ID
+    eventRetention = 2 * time.Minute // เก็บ backlog ต่ออีกนานเท่านี้หลัง stream สุดท้ายของผู้เล่นปิด
+    eventQueueSize = 64              // ข้อความที่รอส่งได้ต่อ stream ถ้าเต็ม stream จะถูกปิดให้ client ต่อใหม่
+)
+
+// playerEvent คือข้อความหนึ่งอันที่ส่งให้ผู้เล่นพร้อม id สำหรับ Last-Event-ID
+type playerEvent struct {
+    id  string
+    env Envelope
+}
+
+// eventLog คือข้อความล่าสุดของผู้เล่นหนึ่งคนและ stream ที่เปิดอยู่
+// id อยู่ในรูป epoch-seq epoch เปลี่ยนทุกครั้งที่ log ถูกสร้างใหม่ id จาก log เก่าจึงไม่ถูกเข้าใจผิด
+type eventLog struct {
+    epoch       string
+    seq         uint64
+    events      []playerEvent
+    subscribers map[chan playerEvent]struct{}
+    expiry      *time.Timer
+    ready       chan struct{} // ปิดเมื่อ log subscribe topic กับ hub เสร็จ
+}
+
+func (l *eventLog) id(seq uint64) string {
+    return l.epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+// since คืนข้อความหลัง lastID คืน false ถ้าต่อไม่ได้เพราะ id ไม่ใช่ของ log นี้หรือเก่าเกิน backlog
+func (l *eventLog) since(lastID string) ([]playerEvent, bool) {
+    epoch, seqText, found := strings.Cut(lastID, "-")
+    seq, err := strconv.ParseUint(seqText, 10, 64)
+    if !found || err != nil || epoch != l.epoch || seq > l.seq {
+        return nil, false
+    }
+    missed := int(l.seq - seq)
+    if missed > len(l.events) {
+        return nil, false
+    }
+    return append([]playerEvent(nil), l.events[len(l.events)-missed:]...), true
+}
+
+// eventStreams เก็บ backlog ของผู้เล่นที่ใช้ SSE เท่านั้น ผู้เล่นที่ใช้แค่ WebSocket ไม่มี log
+// log ของผู้เล่น subscribe topic ของผู้เล่นและ global ไว้ตลอดอายุของ log รวมช่วงที่ไม่มี stream เปิดอยู่
+type eventStreams struct {
+    mu   sync.Mutex
+    hub  *hub
+    logs map[string]*eventLog
+}
+
+func newEventStreams(hub *hub) *eventStreams {
+    return &eventStreams{hub: hub, logs: make(map[string]*eventLog)}
+}
+
+// eventSink คือ log หนึ่งอันของผู้เล่นในฐานะผู้รับของ hub ผูกกับ log ไม่ใช่ผู้เล่น
+// log ที่หมดอายุแล้วจึงยกเลิก topic ได้โดยไม่กระทบ log ใหม่ของผู้เล่นคนเดิม
+type eventSink struct {
+    streams  *eventStreams
+    playerID string
+    log      *eventLog
+}
+
+func (e eventSink) deliver(message Envelope) {
+    e.streams.mu.Lock()
+    defer e.streams.mu.Unlock()
+    if e.streams.logs[e.playerID] == e.log {
+        e.streams.record(e.log, message)
+    }
+}
+
+// publish เก็บข้อความลง log ของผู้เล่นและส่งให้ทุก stream ที่เปิดอยู่ ไม่บล็อกผู้เรียก
+func (s *eventStreams) publish(playerID string, env Envelope) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    if log, exists := s.logs[playerID]; exists {
+        s.record(log, env)
+    }
+}
+
+// record ต้องถือ s.mu อยู่
+func (s *eventStreams) record(log *eventLog, env Envelope) {
+    log.seq++
+    event := playerEvent{id: log.id(log.seq), env: env}
+    log.events = append(log.events, event)
+    if len(log.events) > eventBacklog {
+        log.events = log.events[len(log.events)-eventBacklog:]
+    }
+
+    for ch := range log.subscribers {
+        select {
+        case ch <- event:
+        default:
+            // อ่านไม่ทัน ปิด stream ไป client จะต่อใหม่และได้ส่วนที่ขาดจาก backlog
+            delete(log.subscribers, ch)
+            close(ch)
+        }
+    }
+}
+
+// subscribe เปิด stream ของผู้เล่น คืนข้อความที่พลาดไปหลัง lastID
+// resumed เป็น false ถ้ามี lastID แต่ต่อจากเดิมไม่ได้ client ต้องขอสถานะใหม่เอง
+func (s *eventStreams) subscribe(playerID, lastID string) (ch chan playerEvent, missed []playerEvent, resumed bool) {
+    s.mu.Lock()
+    log, exists := s.logs[playerID]
+    if !exists {
+        log = &eventLog{
+            epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+            subscribers: make(map[chan playerEvent]struct{}),
+            ready:       make(chan struct{}),
+        }
+        s.logs[playerID] = log
+    }
+    if log.expiry != nil {
+        log.expiry.Stop()
+        log.expiry = nil
+    }
+
+    ch = make(chan playerEvent, eventQueueSize)
+    log.subscribers[ch] = struct{}{}
+
+    resumed = lastID == ""
+    if !resumed {
+        missed, resumed = log.since(lastID)
+    }
+    s.mu.Unlock()
+
+    // hub อาจรอ broker ผ่านเครือข่าย และระหว่างนั้น broker ส่งข้อความเข้ามาทาง eventSink ซึ่งใช้ s.mu
+    // จึง subscribe หลังปล่อย lock log มี stream นี้อยู่แล้วจึงไม่หมดอายุระหว่างนี้
+    if !exists {
+        sink := eventSink{streams: s, playerID: playerID, log: log}
+        s.hub.subscribe(playerTopic(playerID), sink)
+        s.hub.subscribe(TopicGlobal, sink)
+        close(log.ready)
+    }
+    <-log.ready
+    return ch, missed, resumed
+}
+
+// unsubscribe ปิด stream คืน true ถ้าผู้เล่นไม่เหลือ stream แล้ว
+// backlog ยังถูกเก็บไว้อีก eventRetention เผื่อ client ต่อกลับมา
+func (s *eventStreams) unsubscribe(playerID string, ch chan playerEvent) bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    log, exists := s.logs[playerID]
+    if !exists {
+        return true
+    }
+    if _, open := log.subscribers[ch]; open {
+        delete(log.subscribers, ch)
+        close(ch)
+    }
+    if len(log.subscribers) > 0 {
+        return false
+    }
+
+    log.expiry = time.AfterFunc(eventRetention, func() {
+        s.mu.Lock()
+        current, exists := s.logs[playerID]
+        expired := exists && current == log && len(log.subscribers) == 0
+        if expired {
+            delete(s.logs, playerID)
+        }
+        s.mu.Unlock()
+
+        if expired {
+            s.hub.unsubscribeAll(eventSink{streams: s, playerID: playerID, log: log})
+        }
+    })
+    return true
+}
+
+// subscribed บอกว่าผู้เล่นมี SSE stream เปิดอยู่หรือไม่
+func (s *eventStreams) subscribed(playerID string) bool {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    log, exists := s.logs[playerID]
+    return exists && len(log.subscribers) > 0
+}
+
+// StreamEvents ส่งข้อความชุดเดียวกับ WebSocket ของเกมผ่าน Server-Sent Events
+// สำหรับ client ที่ใช้ WebSocket ไม่ได้ คำสั่งต่างๆ ยังส่งผ่าน REST ตามเดิม
+// ส่ง Last-Event-ID เพื่อรับข้อความที่พลาดไป ถ้าต่อไม่ได้จะได้ resync และควรขอสถานะใหม่ด้วย GET /games/:gameId/state
+func (h *GameHandler) StreamEvents(c *gin.Context) {
+    claims, err := h.getPlayerClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+    playerID := claims.PlayerID
+
+    lastID := c.GetHeader("Last-Event-ID")
+    ch, missed, resumed := h.events.subscribe(playerID, lastID)
+    h.gameManager.SetPlayerConnected(playerID, true)
+    defer func() {
+        if h.events.unsubscribe(playerID, ch) && len(h.sessions(playerID)) == 0 {
+            h.gameManager.SetPlayerConnected(playerID, false)
+        }
+        h.log.Info("Event stream closed", logger.String("playerID", playerID))
+    }()
+
+    h.log.Info("Event stream opened",
+        logger.String("playerID", playerID),
+        logger.String("lastEventID", lastID))
+
+    c.Header("Cache-Control", "no-cache")
+    c.Header("Connection", "keep-alive")
+    c.Header("X-Accel-Buffering", "no")
+    c.Status(http.StatusOK)
+
+    // welcome และ resync ไม่มี id เพื่อไม่ให้ Last-Event-ID ของ client เปลี่ยน
+    c.Render(-1, sse.Event{Event: MsgWelcome, Data: newEnvelope(MsgWelcome, WelcomePayload{
+        PlayerID: playerID,
+        Message:  "Connected to game server",
+    })})
+    if !resumed {
+        c.Render(-1, sse.Event{Event: MsgResync, Data: newEnvelope(MsgResync, nil)})
+    }
+    for _, event := range missed {
+        c.Render(-1, sse.Event{Id: event.id, Event: event.env.Type, Data: event.env})
+    }
+    c.Writer.Flush()
+
+    h.mu.RLock()
+    ping := time.NewTicker(h.wsConfig.PingInterval)
+    h.mu.RUnlock()
+    defer ping.Stop()
+
+    ctx := c.Request.Context()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ping.C:
+            // comment ของ SSE กันไม่ให้ proxy ตัดการเชื่อมต่อที่เงียบนาน
+            fmt.Fprint(c.Writer, ": ping\n\n")
+        case event, open := <-ch:
+            if !open {
+                return
+            }
+            c.Render(-1, sse.Event{Id: event.id, Event: event.env.Type, Data: event.env})
+        }
+        c.Writer.Flush()
+    }
+}
+
+// GetGameState คืนสถานะเต็มของเกมพร้อม seq แบบเดียวกับ get_game_state บน WebSocket
+// ใช้ตั้งต้นก่อนรับ game_patch ทาง SSE
+func (h *GameHandler) GetGameState(c *gin.Context) {
+    claims, err := h.getPlayerClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    snapshot, err := h.gameSnapshot(c.Param("gameId"), claims.PlayerID)
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+    c.JSON(http.StatusOK, snapshot)
+}
\ No newline at end of file
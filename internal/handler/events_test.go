@@ -0,0 +1,225 @@
+package handler
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/tem-mars/tft-game-server/pkg/broker"
+    "github.com/tem-mars/tft-game-server/pkg/logger"
+)
+
+func TestEventStreams(t *testing.T) {
+    streams := newEventStreams(newHub(logger.New()))
+    message := func(n int) Envelope {
+        return newEnvelope(MsgQueueStatus, map[string]int{"n": n})
+    }
+
+    t.Run("Only Subscribed Players Are Logged", func(t *testing.T) {
+        streams.publish("nobody", message(1))
+        if _, exists := streams.logs["nobody"]; exists {
+            t.Error("expected no log for a player without SSE")
+        }
+    })
+
+    ch, _, resumed := streams.subscribe("alice", "")
+    if !resumed {
+        t.Fatal("expected a fresh stream without Last-Event-ID to be resumed")
+    }
+    streams.publish("alice", message(1))
+    streams.publish("alice", message(2))
+    first := <-ch
+    <-ch
+    if !streams.unsubscribe("alice", ch) || streams.subscribed("alice") {
+        t.Fatal("expected alice to have no streams left")
+    }
+
+    // ข้อความระหว่างที่หลุดต้องถูกเก็บไว้
+    streams.publish("alice", message(3))
+    streams.publish("alice", message(4))
+
+    t.Run("Resume After Last Event ID", func(t *testing.T) {
+        ch, missed, resumed := streams.subscribe("alice", first.id)
+        defer streams.unsubscribe("alice", ch)
+        if !resumed || len(missed) != 3 {
+            t.Fatalf("expected 3 missed events, got %d (resumed %v)", len(missed), resumed)
+        }
+        var payload map[string]int
+        json.Unmarshal(missed[0].env.Payload, &payload)
+        if payload["n"] != 2 {
+            t.Errorf("expected to resume from message 2, got %d", payload["n"])
+        }
+    })
+
+    t.Run("Unknown Or Stale ID Needs Resync", func(t *testing.T) {
+        for _, id := range []string{"garbage", "otherepoch-1", streams.logs["alice"].epoch + "-99"} {
+            ch, missed, resumed := streams.subscribe("alice", id)
+            streams.unsubscribe("alice", ch)
+            if resumed || len(missed) != 0 {
+                t.Errorf("expected resync for %q, got %d events", id, len(missed))
+            }
+        }
+    })
+
+    t.Run("Backlog Overflow Needs Resync", func(t *testing.T) {
+        ch, _, _ := streams.subscribe("bob", "")
+        streams.publish("bob", message(0))
+        oldest := <-ch
+        streams.unsubscribe("bob", ch)
+        for i := 0; i < eventBacklog+1; i++ {
+            streams.publish("bob", message(i))
+        }
+        ch, _, resumed := streams.subscribe("bob", oldest.id)
+        streams.unsubscribe("bob", ch)
+        if resumed {
+            t.Error("expected resync when the backlog no longer covers Last-Event-ID")
+        }
+    })
+
+    t.Run("Slow Stream Is Closed", func(t *testing.T) {
+        ch, _, _ := streams.subscribe("carol", "")
+        for i := 0; i < eventQueueSize+1; i++ {
+            streams.publish("carol", message(i))
+        }
+        for range ch {
+        }
+        if streams.subscribed("carol") {
+            t.Error("expected slow stream to be dropped")
+        }
+    })
+
+    t.Run("Broker Delivers During Subscribe", func(t *testing.T) {
+        b := newHub(logger.New())
+        b.setBroker(&eagerBroker{Local: broker.NewLocal()})
+        streams := newEventStreams(b)
+
+        done := make(chan chan playerEvent, 1)
+        go func() {
+            ch, _, _ := streams.subscribe("dave", "")
+            done <- ch
+        }()
+        select {
+        case ch := <-done:
+            // ข้อความที่มาระหว่าง subscribe ทั้งของผู้เล่นและ global ต้องไม่หาย
+            if len(ch) != 2 {
+                t.Errorf("expected 2 events delivered during subscribe, got %d", len(ch))
+            }
+        case <-time.After(2 * time.Second):
+            t.Fatal("subscribe blocked while the broker was delivering")
+        }
+    })
+}
+
+// eagerBroker ส่งข้อความให้ handler ก่อน Subscribe คืนค่า เหมือน broker ที่ส่งข้อความเข้ามาระหว่างรอคำยืนยัน
+type eagerBroker struct {
+    *broker.Local
+}
+
+func (b *eagerBroker) Subscribe(topic string, handler broker.Handler) (func(), error) {
+    unsubscribe, err := b.Local.Subscribe(topic, handler)
+    if err != nil {
+        return nil, err
+    }
+    message := newEnvelope(MsgQueueStatus, nil)
+    message.Topic = topic
+    data, _ := json.Marshal(message)
+    b.Local.Publish(topic, data)
+    return unsubscribe, nil
+}
+
+func TestStreamEvents(t *testing.T) {
+    router, gameManager, playerRepo, log := setupTestRouter()
+    alice, _ := playerRepo.Create(context.Background(), "alice", "alice@example.com", "secret")
+
+    handler := NewGameHandler(gameManager, log, "test-secret")
+    router.GET("/games/events", withClaims(alice.ID), handler.StreamEvents)
+    server := httptest.NewServer(router)
+    defer server.Close()
+
+    // open เปิด stream และคืนตัวอ่าน event ทีละอัน
+    open := func(t *testing.T, lastID string) (func() (id, event string, env Envelope), func()) {
+        t.Helper()
+        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/games/events", nil)
+        if lastID != "" {
+            req.Header.Set("Last-Event-ID", lastID)
+        }
+        resp, err := http.DefaultClient.Do(req)
+        if err != nil {
+            cancel()
+            t.Fatalf("failed to open stream: %v", err)
+        }
+        if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+            t.Fatalf("expected event stream, got %q", resp.Header.Get("Content-Type"))
+        }
+
+        reader := bufio.NewReader(resp.Body)
+        next := func() (id, event string, env Envelope) {
+            t.Helper()
+            for {
+                line, err := reader.ReadString('\n')
+                if err != nil {
+                    t.Fatalf("failed to read stream: %v", err)
+                }
+                line = strings.TrimRight(line, "\n")
+                switch {
+                case line == "":
+                    if event != "" {
+                        return id, event, env
+                    }
+                case strings.HasPrefix(line, "id:"):
+                    id = strings.TrimPrefix(line, "id:")
+                case strings.HasPrefix(line, "event:"):
+                    event = strings.TrimPrefix(line, "event:")
+                case strings.HasPrefix(line, "data:"):
+                    json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &env)
+                }
+            }
+        }
+        return next, func() { resp.Body.Close(); cancel() }
+    }
+
+    next, closeStream := open(t, "")
+    if _, event, _ := next(); event != MsgWelcome {
+        t.Fatalf("expected welcome, got %s", event)
+    }
+
+    handler.SendToPlayer(alice.ID, newEnvelope(MsgQueueStatus, map[string]int{"position": 1}))
+    firstID, event, env := next()
+    if event != MsgQueueStatus || env.Type != MsgQueueStatus || firstID == "" {
+        t.Fatalf("expected queue_status with id, got %q %s %+v", firstID, event, env)
+    }
+    closeStream()
+
+    // รอให้ handler เห็นว่า client ปิดไปแล้ว ข้อความหลังจากนี้ต้องได้ตอนต่อใหม่
+    for deadline := time.Now().Add(2 * time.Second); handler.events.subscribed(alice.ID); {
+        if time.Now().After(deadline) {
+            t.Fatal("stream was not closed")
+        }
+        time.Sleep(10 * time.Millisecond)
+    }
+    handler.SendToPlayer(alice.ID, newEnvelope(MsgMatchFound, nil))
+
+    t.Run("Resume With Last Event ID", func(t *testing.T) {
+        next, closeStream := open(t, firstID)
+        defer closeStream()
+        next() // welcome
+        if id, event, _ := next(); event != MsgMatchFound || id == firstID {
+            t.Errorf("expected missed match_found, got %s (%s)", event, id)
+        }
+    })
+
+    t.Run("Stale ID Gets Resync", func(t *testing.T) {
+        next, closeStream := open(t, "stale-1")
+        defer closeStream()
+        next() // welcome
+        if _, event, _ := next(); event != MsgResync {
+            t.Errorf("expected resync, got %s", event)
+        }
+    })
+}
\ No newline at end of file
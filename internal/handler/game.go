@@ -1,10 +1,12 @@
 package handler
 
 import (
+    "encoding/json"
     "fmt"  
+    "io"
     "net/http"
     "sync"
-    "time" 
+    "sync/atomic"
     "strings"
     "github.com/gin-gonic/gin"
     "github.com/gorilla/websocket"
@@ -20,14 +22,34 @@ var upgrader = websocket.Upgrader{
     },
 }
 
+// gameUpgrader ใช้กับ WebSocket ของเกม เลือก encoding จาก Sec-WebSocket-Protocol ตามลำดับที่ server ชอบ
+var gameUpgrader = websocket.Upgrader{
+    CheckOrigin: func(r *http.Request) bool {
+        return true
+    },
+    Subprotocols: []string{SubprotocolProto, SubprotocolJSON},
+}
+
 
 
 type GameHandler struct {
     gameManager *game.GameManager
     log        logger.Logger
     secret     string
-    connections map[string]*websocket.Conn
+    connections map[string]sessionSet // key: playerID
+    commands   map[string]CommandFunc
+    wsConfig   WSConfig
+    sessionPolicy SessionPolicy
+    queueLockout func(playerID string) error
+    states     *stateStreams
+    events     *eventStreams
+    hub        *hub
+    topics     map[string]TopicFunc // key: ชนิดของ topic ที่ client subscribe เองได้
     mu         sync.RWMutex  // เปลี่ยนจาก sync.Mutex เป็น sync.RWMutex
+
+    lobbyMu    sync.Mutex // เรียงการส่งรายการล็อบบี้ให้ตรงกับลำดับที่อ่าน
+    lobbyDirty atomic.Bool
+    lobbyLast  []byte
 }
 
 
@@ -36,15 +58,39 @@ func NewGameHandler(gameManager *game.GameManager, log logger.Logger, secret str
         gameManager: gameManager,
         log:        log,
         secret:     secret,
-        connections: make(map[string]*websocket.Conn),
+        connections: make(map[string]sessionSet),
+        wsConfig:    DefaultWSConfig(),
+        sessionPolicy: SessionsAllowMultiple,
+        states:      newStateStreams(),
+        hub:         newHub(log),
+        commands:    make(map[string]CommandFunc),
+        topics:      make(map[string]TopicFunc),
     }
+    handler.events = newEventStreams(handler.hub)
+    handler.registerGameCommands()
+    handler.registerGameTopics()
 
     // เปลี่ยนจาก SetUpdateCallback เป็น SetOnGameUpdate
     gameManager.SetOnGameUpdate(handler.broadcastGameState)
+    gameManager.AddOnGameRemoved(handler.gameRemoved)
 
     return handler
 }
 
+// SetWSConfig เปลี่ยนค่าของการเชื่อมต่อ WebSocket ใหม่ การเชื่อมต่อเดิมใช้ค่าเดิมต่อ
+func (h *GameHandler) SetWSConfig(cfg WSConfig) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    h.wsConfig = cfg.withDefaults()
+}
+
+// SetQueueLockout ตั้งตัวตรวจโทษปฏิเสธแมตช์ให้ AutoMatch ถ้าตัวตรวจคืน error จะหาเกมให้ไม่ได้
+func (h *GameHandler) SetQueueLockout(check func(playerID string) error) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    h.queueLockout = check
+}
+
 func (h *GameHandler) CreateGame(c *gin.Context) {
     h.log.Info("Creating game...") // เพิ่ม logging
 
@@ -64,13 +110,47 @@ func (h *GameHandler) CreateGame(c *gin.Context) {
 
     h.log.Info("Creating game for player", logger.String("playerID", userClaims.PlayerID))
 
-    game, err := h.gameManager.CreateGame(userClaims.PlayerID)
+    // body ไม่บังคับ ถ้าไม่ส่งมาจะได้ล็อบบี้สาธารณะโหมด classic ตามค่าเริ่มต้น
+    // อ่านโหมดก่อนเพื่อเอาค่าเริ่มต้นของโหมดนั้น แล้วอ่าน body ทับ ฟิลด์ที่ไม่ได้ส่งมาจะใช้ค่าเดิม
+    var body []byte
+    if c.Request.ContentLength != 0 {
+        data, err := io.ReadAll(c.Request.Body)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        body = data
+    }
+
+    var opts game.LobbyOptions
+    if len(body) > 0 {
+        if err := json.Unmarshal(body, &opts); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+    }
+    rules, err := game.RuleSetFor(opts.Mode)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    opts.Settings = rules.DefaultSettings()
+    if len(body) > 0 {
+        if err := json.Unmarshal(body, &opts); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+    }
+
+    game, err := h.gameManager.CreateGame(userClaims.PlayerID, opts)
     if err != nil {
         h.log.Error("Failed to create game", logger.Error(err))
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
 
+    h.lobbyChanged()
+
     h.log.Info("Game created successfully", 
         logger.String("gameID", game.ID),
         logger.String("playerID", userClaims.PlayerID))
@@ -78,10 +158,37 @@ func (h *GameHandler) CreateGame(c *gin.Context) {
     c.JSON(http.StatusOK, gin.H{
         "status": "success",
         "message": "Game created successfully",
-        "game": game,
+        "game": h.viewFor(game.ID, userClaims),
     })
 }
 
+// GetGame คืนสถานะเกมในมุมของผู้เรียก คนนอกเกมเห็นแบบผู้ชม
+func (h *GameHandler) GetGame(c *gin.Context) {
+    claims, err := h.getPlayerClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    view, _, err := h.gameManager.View(c.Param("gameId"), claims.PlayerID, claims.IsAdmin())
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"game": view})
+}
+
+// viewFor ตัดข้อมูลในเกมที่ผู้ถือ claims ไม่ควรเห็น ทุก response ที่มีเกมต้องผ่านตรงนี้
+// คืน nil ถ้าเกมถูกลบไปแล้วระหว่างนั้น
+func (h *GameHandler) viewFor(gameID string, claims *middleware.Claims) *game.Game {
+    view, _, err := h.gameManager.View(gameID, claims.PlayerID, claims.IsAdmin())
+    if err != nil {
+        return nil
+    }
+    return view
+}
+
 func (h *GameHandler) JoinGame(c *gin.Context) {
     claims, exists := c.Get("claims")
     if !exists {
@@ -108,42 +215,264 @@ func (h *GameHandler) JoinGame(c *gin.Context) {
     c.JSON(http.StatusOK, gin.H{"message": "Successfully joined game"})
 }
 
-func (h *GameHandler) broadcastGameState(game *game.Game) {
+type JoinByCodeRequest struct {
+    Code     string `json:"code" binding:"required"`
+    Password string `json:"password"`
+}
+
+type KickPlayerRequest struct {
+    PlayerID string `json:"player_id" binding:"required"`
+}
+
+type SetSlotsRequest struct {
+    MaxPlayers int `json:"max_players" binding:"required"`
+}
+
+type SetReadyRequest struct {
+    Ready bool `json:"ready"`
+}
+
+type AddBotRequest struct {
+    Difficulty game.BotDifficulty `json:"difficulty"`
+}
+
+func (h *GameHandler) JoinByCode(c *gin.Context) {
+    claims, err := h.getPlayerClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    var req JoinByCodeRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    game, err := h.gameManager.JoinByCode(req.Code, req.Password, claims.PlayerID)
+    if err != nil {
+        h.log.Error("Failed to join game by code", logger.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": "Successfully joined game",
+        "game": h.viewFor(game.ID, claims),
+    })
+}
+
+func (h *GameHandler) KickPlayer(c *gin.Context) {
+    claims, err := h.getPlayerClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    var req KickPlayerRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    gameID := c.Param("gameId")
+    if err := h.gameManager.KickPlayer(gameID, claims.PlayerID, req.PlayerID); err != nil {
+        h.log.Error("Failed to kick player",
+            logger.String("gameID", gameID),
+            logger.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Player kicked"})
+}
+
+func (h *GameHandler) SetSlots(c *gin.Context) {
+    claims, err := h.getPlayerClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    var req SetSlotsRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    gameID := c.Param("gameId")
+    if err := h.gameManager.SetMaxPlayers(gameID, claims.PlayerID, req.MaxPlayers); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Lobby size updated"})
+}
+
+func (h *GameHandler) SetReady(c *gin.Context) {
+    claims, err := h.getPlayerClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    var req SetReadyRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    gameID := c.Param("gameId")
+    if err := h.gameManager.SetReady(gameID, claims.PlayerID, req.Ready); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Ready state updated"})
+}
+
+func (h *GameHandler) AddBot(c *gin.Context) {
+    claims, err := h.getPlayerClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    req := AddBotRequest{Difficulty: game.BotGreedy}
+    if c.Request.ContentLength != 0 {
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+    }
+
+    gameID := c.Param("gameId")
+    bot, err := h.gameManager.AddBot(gameID, claims.PlayerID, req.Difficulty)
+    if err != nil {
+        h.log.Error("Failed to add bot",
+            logger.String("gameID", gameID),
+            logger.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "message": "Bot added",
+        "bot": bot,
+    })
+}
+
+func (h *GameHandler) StartGame(c *gin.Context) {
+    claims, err := h.getPlayerClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    gameID := c.Param("gameId")
+    if err := h.gameManager.StartGame(gameID, claims.PlayerID); err != nil {
+        h.log.Error("Failed to start game",
+            logger.String("gameID", gameID),
+            logger.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Game started"})
+}
+
+func (h *GameHandler) LeaveGame(c *gin.Context) {
+    claims, err := h.getPlayerClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    gameID := c.Param("gameId")
+    if err := h.gameManager.LeaveGame(gameID, claims.PlayerID); err != nil {
+        h.log.Error("Failed to leave game",
+            logger.String("gameID", gameID),
+            logger.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Successfully left game"})
+}
+
+func (h *GameHandler) broadcastGameState(g *game.Game) {
     h.log.Info("Broadcasting game state", 
-        logger.String("gameID", game.ID),
-        logger.Int("playerCount", len(game.Players)))
-
-    message := map[string]interface{}{
-        "type": "game_state",
-        "game": game,
-    }
-
-    // ส่งข้อมูลให้ทุกคนที่เกี่ยวข้องกับเกม
-    for _, player := range game.Players {
-        h.mu.RLock()
-        conn, exists := h.connections[player.ID]
-        h.mu.RUnlock()
-
-        if exists {
-            if err := conn.WriteJSON(message); err != nil {
-                h.log.Error("Failed to send game state",
-                    logger.String("playerID", player.ID),
-                    logger.Error(err))
-            } else {
-                h.log.Info("Sent game state to player",
-                    logger.String("playerID", player.ID))
-            }
+        logger.String("gameID", g.ID),
+        logger.Int("playerCount", len(g.Players)))
+
+    // ผู้เล่นแต่ละคนได้เกมในมุมของตัวเอง และได้เฉพาะสิ่งที่เปลี่ยนจากครั้งก่อน ไม่ต้องส่ง Actions ทั้งหมดซ้ำทุกครั้ง
+    for _, player := range g.Players {
+        if player.IsBot {
+            continue
+        }
+        playerID := player.ID
+        view := g.ViewFor(game.Viewer{PlayerID: playerID, Role: game.ViewerPlayer})
+        err := h.states.publish(playerID, view, func(message Envelope) {
+            h.SendToPlayer(playerID, message)
+        })
+        if err != nil {
+            h.log.Error("Failed to encode game state",
+                logger.String("playerID", playerID),
+                logger.Error(err))
+        }
+    }
+
+    // คนนอกเกมทุกคนเห็นเหมือนกัน จึงใช้ stream เดียวกันผ่าน topic ของเกม
+    if h.hub.subscribers(gameTopic(g.ID)) > 0 {
+        view := g.ViewFor(game.Viewer{Role: game.ViewerSpectator})
+        err := h.states.publish(spectatorStream, view, func(message Envelope) {
+            h.Publish(gameTopic(g.ID), message)
+        })
+        if err != nil {
+            h.log.Error("Failed to encode spectator state",
+                logger.String("gameID", g.ID),
+                logger.Error(err))
         }
     }
+    h.lobbyChanged()
+
+    if g.Status == game.StatusFinished {
+        h.states.forget(g.ID)
+    }
 }
 
 
 
 
+// SendToPlayer ส่งข้อความไปยังทุกการเชื่อมต่อของผู้เล่นที่เปิดอยู่ ทั้ง WebSocket และ SSE ผ่าน topic ของผู้เล่น
+// ไม่บล็อกผู้เรียก ข้อความเข้าคิวของ connection แล้ว writer ของ connection นั้นเขียนให้
+func (h *GameHandler) SendToPlayer(playerID string, message Envelope) {
+    h.Publish(playerTopic(playerID), message)
+}
+
+// deliver ใส่ข้อความลงคิวของ client และ log ถ้า client อ่านไม่ทัน
+func (h *GameHandler) deliver(playerID string, client *wsConn, message Envelope) {
+    switch client.enqueue(message) {
+    case enqueueResynced:
+        h.log.Info("Send queue full, asked client to resync",
+            logger.String("playerID", playerID))
+    case enqueueDropped:
+        h.log.Error("Dropped message for slow or closed connection",
+            logger.String("playerID", playerID),
+            logger.String("messageType", message.Type))
+    }
+}
+
 func (h *GameHandler) GetWaitingGames(c *gin.Context) {
     h.log.Info("Getting waiting games...")
 
-    games := h.gameManager.GetWaitingGames()
+    claims, err := h.getPlayerClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    games := h.gameManager.WaitingGamesView(claims.PlayerID, claims.IsAdmin())
     
     h.log.Info("Found waiting games", logger.Int("count", len(games)))
 
@@ -166,18 +495,27 @@ func (h *GameHandler) AutoMatch(c *gin.Context) {
         return
     }
 
+    // ผู้เล่นที่ติดโทษจากคิวห้ามหาเกมทางนี้เช่นกัน
+    h.mu.RLock()
+    checkLockout := h.queueLockout
+    h.mu.RUnlock()
+    if checkLockout != nil {
+        if err := checkLockout(userClaims.PlayerID); err != nil {
+            c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+            return
+        }
+    }
+
     game, err := h.gameManager.AutoMatch(userClaims.PlayerID)
     if err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
 
-    // ส่ง game state ให้ทุกคนในเกม
-    h.broadcastGameState(game)
-
+    // GameManager ส่ง game state ให้ทุกคนในเกมผ่าน callback แล้ว
     c.JSON(http.StatusOK, gin.H{
         "message": "Match found",
-        "game": game,
+        "game": h.viewFor(game.ID, userClaims),
     })
 }
 
@@ -192,145 +530,129 @@ func (h *GameHandler) HandleWebSocket(c *gin.Context) {
         return
     }
 
-    // ลบ prefix "Bearer " ถ้ามี
-    token = strings.TrimPrefix(token, "Bearer ")
-    token = strings.TrimSpace(token)
-
-    h.log.Info("Parsing token", logger.String("token", token))
-
-    // สร้าง claims struct
-    claims := &middleware.Claims{}
-    
-    // parse token ด้วย Claims struct ที่สร้างไว้
-    parsedToken, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-            return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-        }
-        return []byte(h.secret), nil
-    })
-
+    claims, err := parseToken(token, h.secret)
     if err != nil {
         h.log.Error("Failed to parse token", logger.Error(err))
         c.String(http.StatusUnauthorized, "Invalid token")
         return
     }
 
-    if !parsedToken.Valid {
-        h.log.Error("Token is invalid")
-        c.String(http.StatusUnauthorized, "Invalid token")
-        return
-    }
-
     // ใช้ PlayerID จาก claims โดยตรง
     playerID := claims.PlayerID
-    if playerID == "" {
-        h.log.Error("No player ID in claims")
-        c.String(http.StatusUnauthorized, "Invalid player ID")
-        return
-    }
 
     h.log.Info("Token validated successfully", 
         logger.String("playerID", playerID))
 
     // Upgrade connection to WebSocket
-    conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+    conn, err := gameUpgrader.Upgrade(c.Writer, c.Request, nil)
     if err != nil {
         h.log.Error("Failed to upgrade connection", logger.Error(err))
         return
     }
     defer conn.Close()
 
+    h.mu.RLock()
+    client := newWSConn(conn, h.wsConfig, codecFor(conn.Subprotocol()))
+    h.mu.RUnlock()
+    client.prepareRead()
+    go client.writeLoop()
+    defer client.close()
+
     h.log.Info("WebSocket connection established", 
-        logger.String("playerID", playerID))
+        logger.String("playerID", playerID),
+        logger.String("subprotocol", conn.Subprotocol()))
+
+    // เก็บ connection ไว้ในชุดของผู้เล่น ถ้า policy ให้มีได้อันเดียว อันเก่าจะถูกเตะออก
+    for _, old := range h.addSession(playerID, client) {
+        old.enqueue(newEnvelope(MsgSessionReplaced, nil))
+        old.close()
+        h.log.Info("Closed older session", logger.String("playerID", playerID))
+    }
+    h.gameManager.SetPlayerConnected(playerID, true)
 
-    // เก็บ connection ในแมพ
-    h.mu.Lock()
-    h.connections[playerID] = conn
-    h.mu.Unlock()
+    // ทุกการเชื่อมต่อได้ข้อความส่วนตัวและประกาศของระบบ topic อื่นต้อง subscribe เอง
+    session := &wsSession{h: h, playerID: playerID, conn: client}
+    h.hub.subscribe(playerTopic(playerID), session)
+    h.hub.subscribe(TopicGlobal, session)
+    defer h.hub.unsubscribeAll(session)
 
     // ส่งข้อความต้อนรับ
-    welcome := map[string]interface{}{
-        "type": "welcome",
-        "message": "Connected to game server",
-        "playerID": playerID,
-    }
-    
-    if err := conn.WriteJSON(welcome); err != nil {
-        h.log.Error("Failed to send welcome message", logger.Error(err))
-        return
-    }
+    welcome := newEnvelope(MsgWelcome, WelcomePayload{
+        PlayerID: playerID,
+        Message:  "Connected to game server",
+    })
+
+    h.deliver(playerID, client, welcome)
 
     // Cleanup เมื่อจบการเชื่อมต่อ
     defer func() {
-        h.mu.Lock()
-        delete(h.connections, playerID)
-        h.mu.Unlock()
+        // ยังถือว่าเชื่อมต่ออยู่ถ้าผู้เล่นยังมีแท็บหรืออุปกรณ์อื่นเปิดอยู่ รวมถึง SSE
+        if h.removeSession(playerID, client) && !h.events.subscribed(playerID) {
+            h.gameManager.SetPlayerConnected(playerID, false)
+        }
         h.log.Info("Player disconnected", logger.String("playerID", playerID))
     }()
 
-    // รับข้อความจาก WebSocket
+    // รับคำสั่งจาก WebSocket ทุกคำสั่งได้ ack หรือ error กลับพร้อม request_id เดิม
     for {
-        var message map[string]interface{}
-        err := conn.ReadJSON(&message)
+        // peer ที่ตายไปจะไม่ตอบ pong จน read deadline หมด แล้วถูกลบออกใน defer ด้านบน
+        _, data, err := conn.ReadMessage()
         if err != nil {
             if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
                 h.log.Error("WebSocket read error", logger.Error(err))
             }
             return
         }
-    
-        h.log.Info("Received message from client", 
-            logger.String("playerID", playerID),
-            logger.String("messageType", fmt.Sprintf("%v", message["type"])))
-    
-        // จัดการข้อความตาม type
-        switch message["type"] {
-        case "get_game_state":
-            if gameID, ok := message["game_id"].(string); ok {
-                if game, err := h.gameManager.GetGame(gameID); err == nil {
-                    response := map[string]interface{}{
-                        "type": "game_state",
-                        "data": game,
-                    }
-                    if err := conn.WriteJSON(response); err != nil {
-                        h.log.Error("Failed to send game state", logger.Error(err))
-                    }
-                }
-            }
-        case "attack":
-            if gameID, ok := message["game_id"].(string); ok {
-                if targetID, ok := message["target_id"].(string); ok {
-                    action := game.GameAction{
-                        Type:      game.ActionAttack,
-                        PlayerID:  playerID,
-                        TargetID:  targetID,
-                        Timestamp: time.Now(),
-                    }
-                    
-                    if err := h.gameManager.ProcessAction(gameID, action); err != nil {
-                        h.log.Error("Failed to process attack", 
-                            logger.String("gameID", gameID),
-                            logger.String("playerID", playerID),
-                            logger.Error(err))
-                        
-                        errorResponse := map[string]interface{}{
-                            "type": "error",
-                            "message": err.Error(),
-                        }
-                        conn.WriteJSON(errorResponse)
-                    }
-                }
-            }
+        client.extendRead()
+
+        var reply Envelope
+        if env, err := client.codec.decode(data); err != nil {
+            reply = errorEnvelope("", ErrCodeMalformed, err.Error())
+        } else {
+            reply = h.dispatchSession(session, env)
+        }
+        if reply.Type == MsgError {
+            h.log.Error("Failed to handle message",
+                logger.String("playerID", playerID),
+                logger.String("requestID", reply.RequestID),
+                logger.String("error", string(reply.Payload)))
         }
-    }   
+        h.deliver(playerID, client, reply)
+    }
 }
 
 func (h *GameHandler) GetAvailableItems(c *gin.Context) {
-    items := h.gameManager.GetAvailableItems()
+    pack := c.DefaultQuery("pack", game.DefaultContentPack)
+    items, err := h.gameManager.GetAvailableItems(pack)
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
     c.JSON(http.StatusOK, gin.H{"items": items})
 }
 
+// GetGameSettings คืนค่าเริ่มต้นและช่วงที่อนุญาตให้ client ใช้สร้างฟอร์มตั้งค่าล็อบบี้
+// modes คือค่าเริ่มต้นของแต่ละโหมด
+func (h *GameHandler) GetGameSettings(c *gin.Context) {
+    modes := make(map[game.GameMode]game.GameSettings)
+    for _, mode := range game.Modes() {
+        rules, _ := game.RuleSetFor(mode)
+        modes[mode] = rules.DefaultSettings()
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "defaults": game.DefaultGameSettings(),
+        "ranges": game.SettingRanges(),
+        "modes": modes,
+    })
+}
+
 func (h *GameHandler) getPlayerClaims(c *gin.Context) (*middleware.Claims, error) {
+    return getClaims(c)
+}
+
+// getClaims ดึง claims ที่ AuthMiddleware ใส่ไว้ ใช้ร่วมกันทุก handler
+func getClaims(c *gin.Context) (*middleware.Claims, error) {
     claims, exists := c.Get("claims")
     if !exists {
         return nil, fmt.Errorf("no claims found")
@@ -344,6 +666,31 @@ func (h *GameHandler) getPlayerClaims(c *gin.Context) (*middleware.Claims, error
     return playerClaims, nil
 }
 
+// parseToken ตรวจ JWT ที่ส่งมาทาง query ของ WebSocket ซึ่งผ่าน AuthMiddleware ไม่ได้
+func parseToken(token, secret string) (*middleware.Claims, error) {
+    // ลบ prefix "Bearer " ถ้ามี
+    token = strings.TrimPrefix(token, "Bearer ")
+    token = strings.TrimSpace(token)
+
+    claims := &middleware.Claims{}
+    parsedToken, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+        if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+            return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+        }
+        return []byte(secret), nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    if !parsedToken.Valid {
+        return nil, fmt.Errorf("token is invalid")
+    }
+    if claims.PlayerID == "" {
+        return nil, fmt.Errorf("no player ID in claims")
+    }
+    return claims, nil
+}
+
 
 func (h *GameHandler) BuyItem(c *gin.Context) {
     claims, err := h.getPlayerClaims(c)
@@ -1,65 +1,217 @@
 package handler
 
 import (
+    "context"
     "encoding/json"
     "net/http"
     "net/http/httptest"
+    "strings"
     "testing"
+    "time"
 
     "github.com/gin-gonic/gin"
     "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/internal/middleware"
+    "github.com/tem-mars/tft-game-server/internal/repository"
+    "github.com/tem-mars/tft-game-server/internal/service"
     "github.com/tem-mars/tft-game-server/pkg/logger"
 )
 
-func setupTestRouter() (*gin.Engine, *game.GameManager, logger.Logger) {
+func setupTestRouter() (*gin.Engine, *game.GameManager, *repository.MemoryPlayerRepository, logger.Logger) {
     gin.SetMode(gin.TestMode)
     
     router := gin.New()
-    gameManager := game.NewGameManager()
+    playerRepo := repository.NewMemoryPlayerRepository()
+    gameManager := game.NewGameManager(playerRepo)
     log := logger.New()
 
-    return router, gameManager, log
+    return router, gameManager, playerRepo, log
+}
+
+// withClaims จำลอง AuthMiddleware โดยใส่ claims ของผู้เล่นที่ระบุ
+func withClaims(playerID string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if playerID != "" {
+            c.Set("claims", &middleware.Claims{PlayerID: playerID})
+        }
+        c.Next()
+    }
 }
 
 func TestCreateGame(t *testing.T) {
-    router, gameManager, log := setupTestRouter()
-    handler := NewGameHandler(gameManager, log)
+    router, gameManager, playerRepo, log := setupTestRouter()
+    handler := NewGameHandler(gameManager, log, "test-secret")
+
+    player, err := playerRepo.Create(context.Background(), "tester", "tester@example.com", "secret")
+    if err != nil {
+        t.Fatalf("failed to create player: %v", err)
+    }
 
-    router.POST("/games/:gameId", handler.CreateGame)
+    router.POST("/games", withClaims(player.ID), handler.CreateGame)
+    router.POST("/anonymous/games", withClaims(""), handler.CreateGame)
 
     t.Run("Create New Game", func(t *testing.T) {
         w := httptest.NewRecorder()
-        req, _ := http.NewRequest("POST", "/games/test-game-1", nil)
+        req, _ := http.NewRequest("POST", "/games", nil)
         router.ServeHTTP(w, req)
 
-        if w.Code != http.StatusCreated {
-            t.Errorf("expected status %d, got %d", http.StatusCreated, w.Code)
+        if w.Code != http.StatusOK {
+            t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
         }
 
-        var response game.Game
+        var response struct {
+            Game game.Game `json:"game"`
+        }
         err := json.Unmarshal(w.Body.Bytes(), &response)
         if err != nil {
             t.Fatalf("failed to unmarshal response: %v", err)
         }
 
-        if response.ID != "test-game-1" {
-            t.Errorf("expected game ID test-game-1, got %s", response.ID)
+        if len(response.Game.Players) != 1 || response.Game.Players[0].ID != player.ID {
+            t.Errorf("expected game hosted by %s, got %+v", player.ID, response.Game.Players)
+        }
+    })
+
+    t.Run("Create Game With Mode Defaults", func(t *testing.T) {
+        w := httptest.NewRecorder()
+        body := strings.NewReader(`{"mode":"hyper_roll","settings":{"max_players":4}}`)
+        req, _ := http.NewRequest("POST", "/games", body)
+        router.ServeHTTP(w, req)
+
+        if w.Code != http.StatusOK {
+            t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+        }
+
+        var response struct {
+            Game game.Game `json:"game"`
+        }
+        if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+            t.Fatalf("failed to unmarshal response: %v", err)
+        }
+
+        // ฟิลด์ที่ไม่ได้ส่งมาต้องได้ค่าเริ่มต้นของโหมด ไม่ใช่ของ classic
+        rules, _ := game.RuleSetFor(game.ModeHyperRoll)
+        want := rules.DefaultSettings()
+        want.MaxPlayers = 4
+        if response.Game.Mode != game.ModeHyperRoll || response.Game.Settings != want {
+            t.Errorf("expected hyper_roll with %+v, got %s with %+v", want, response.Game.Mode, response.Game.Settings)
+        }
+    })
+
+    t.Run("Create Game With Unknown Mode", func(t *testing.T) {
+        w := httptest.NewRecorder()
+        req, _ := http.NewRequest("POST", "/games", strings.NewReader(`{"mode":"chess"}`))
+        router.ServeHTTP(w, req)
+
+        if w.Code != http.StatusBadRequest {
+            t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+        }
+    })
+
+    t.Run("Create Game Without Claims", func(t *testing.T) {
+        w := httptest.NewRecorder()
+        req, _ := http.NewRequest("POST", "/anonymous/games", nil)
+        router.ServeHTTP(w, req)
+
+        if w.Code != http.StatusUnauthorized {
+            t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
         }
     })
+}
+
+func TestGetGameProjection(t *testing.T) {
+    router, gameManager, playerRepo, log := setupTestRouter()
+    handler := NewGameHandler(gameManager, log, "test-secret")
+
+    alice, _ := playerRepo.Create(context.Background(), "alice", "alice@example.com", "secret")
+    bob, _ := playerRepo.Create(context.Background(), "bob", "bob@example.com", "secret")
+    played, err := gameManager.CreateMatch([]string{alice.ID, bob.ID})
+    if err != nil {
+        t.Fatalf("failed to create match: %v", err)
+    }
 
-    t.Run("Create Duplicate Game", func(t *testing.T) {
-        // First request
-        w1 := httptest.NewRecorder()
-        req1, _ := http.NewRequest("POST", "/games/test-game-2", nil)
-        router.ServeHTTP(w1, req1)
+    asAdmin := func(c *gin.Context) {
+        c.Set("claims", &middleware.Claims{PlayerID: "ops", Role: middleware.RoleAdmin})
+        c.Next()
+    }
+    router.GET("/alice/games/:gameId", withClaims(alice.ID), handler.GetGame)
+    router.GET("/spectator/games/:gameId", withClaims("carol"), handler.GetGame)
+    router.GET("/admin/games/:gameId", asAdmin, handler.GetGame)
 
-        // Second request with same ID
-        w2 := httptest.NewRecorder()
-        req2, _ := http.NewRequest("POST", "/games/test-game-2", nil)
-        router.ServeHTTP(w2, req2)
+    tests := []struct {
+        name     string
+        prefix   string
+        seeAlice bool
+        seeBob   bool
+    }{
+        {"Player Sees Own Gold", "/alice", true, false},
+        {"Spectator Sees No Gold", "/spectator", false, false},
+        {"Admin Sees All Gold", "/admin", true, true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            w := httptest.NewRecorder()
+            req, _ := http.NewRequest("GET", tt.prefix+"/games/"+played.ID, nil)
+            router.ServeHTTP(w, req)
+            if w.Code != http.StatusOK {
+                t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+            }
 
-        if w2.Code != http.StatusBadRequest {
-            t.Errorf("expected status %d, got %d", http.StatusBadRequest, w2.Code)
+            var response struct {
+                Game game.Game `json:"game"`
+            }
+            json.Unmarshal(w.Body.Bytes(), &response)
+            for _, p := range response.Game.Players {
+                want := map[string]bool{alice.ID: tt.seeAlice, bob.ID: tt.seeBob}[p.ID]
+                if (p.Gold > 0) != want {
+                    t.Errorf("expected gold of %s visible=%v, got %d", p.Username, want, p.Gold)
+                }
+            }
+        })
+    }
+
+    t.Run("Unknown Game", func(t *testing.T) {
+        w := httptest.NewRecorder()
+        req, _ := http.NewRequest("GET", "/alice/games/nope", nil)
+        router.ServeHTTP(w, req)
+        if w.Code != http.StatusNotFound {
+            t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+        }
+    })
+}
+
+func TestAutoMatchLockout(t *testing.T) {
+    router, gameManager, playerRepo, log := setupTestRouter()
+    handler := NewGameHandler(gameManager, log, "test-secret")
+    matchmaking := service.NewMatchmakingService(service.DefaultMatchmakingConfig(), playerRepo, playerRepo, gameManager)
+    handler.SetQueueLockout(matchmaking.CheckLockout)
+
+    dodger, _ := playerRepo.Create(context.Background(), "dodger", "dodger@example.com", "secret")
+    player, _ := playerRepo.Create(context.Background(), "player", "player@example.com", "secret")
+    playerRepo.UpdateQueuePenalty(context.Background(), &repository.QueuePenalty{
+        PlayerID:    dodger.ID,
+        Dodges:      1,
+        LockedUntil: time.Now().Add(time.Minute),
+    })
+
+    router.POST("/dodger/games/match", withClaims(dodger.ID), handler.AutoMatch)
+    router.POST("/player/games/match", withClaims(player.ID), handler.AutoMatch)
+
+    t.Run("Locked Player Is Refused", func(t *testing.T) {
+        w := httptest.NewRecorder()
+        req, _ := http.NewRequest("POST", "/dodger/games/match", nil)
+        router.ServeHTTP(w, req)
+        if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), service.ErrQueueLocked.Error()) {
+            t.Errorf("expected status %d with lockout error, got %d: %s", http.StatusForbidden, w.Code, w.Body.String())
+        }
+    })
+
+    t.Run("Other Players Still Match", func(t *testing.T) {
+        w := httptest.NewRecorder()
+        req, _ := http.NewRequest("POST", "/player/games/match", nil)
+        router.ServeHTTP(w, req)
+        if w.Code != http.StatusOK {
+            t.Errorf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
         }
     })
 }
\ No newline at end of file
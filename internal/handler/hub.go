@@ -0,0 +1,435 @@
+package handler
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "strings"
+    "sync"
+
+    "github.com/gin-gonic/gin"
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/pkg/broker"
+    "github.com/tem-mars/tft-game-server/pkg/logger"
+)
+
+// ชนิดของ topic ชื่อเต็มคือ ชนิด:id เช่น game:abc ยกเว้น lobby และ global ที่ไม่มี id
+const (
+    TopicLobby  = "lobby"  // รายการเกมที่รอผู้เล่น
+    TopicGlobal = "global" // ประกาศจากระบบ ทุกการเชื่อมต่อได้รับอัตโนมัติ
+    TopicGame   = "game"   // สถานะเกมในมุมของผู้ชม
+    TopicQueue  = "queue"  // สถิติของคิวจับคู่
+    TopicPlayer = "player" // ข้อความส่วนตัวของผู้เล่น ทุกการเชื่อมต่อของผู้เล่นได้รับอัตโนมัติ
+)
+
+func topicOf(kind, id string) string { return kind + ":" + id }
+
+func playerTopic(playerID string) string { return topicOf(TopicPlayer, playerID) }
+
+func gameTopic(gameID string) string { return topicOf(TopicGame, gameID) }
+
+// sharedTopic บอกว่า topic นี้ต้องส่งผ่าน broker ให้ทุกเครื่อง ผู้เล่นอาจเชื่อมต่อกับเครื่องอื่นที่ไม่ใช่เครื่องของเกม
+// และประกาศต้องถึงทุกคน ส่วน lobby, queue และ game มาจากสถานะในเครื่องนี้ ผู้รับจึงอยู่ในเครื่องนี้เท่านั้น
+func sharedTopic(topic string) bool {
+    kind, _, _ := strings.Cut(topic, ":")
+    return kind == TopicPlayer || kind == TopicGlobal
+}
+
+// subscriber คือผู้รับข้อความจาก hub deliver ถูกเรียกจาก goroutine ของผู้ publish จึงต้องไม่บล็อก
+type subscriber interface {
+    deliver(message Envelope)
+}
+
+// hub กระจายข้อความของแต่ละ topic ไปยังทุกผู้รับที่ subscribe ไว้ ผู้ publish ไม่ต้องรู้ว่าใครรับอยู่
+// topic ที่ sharedTopic ผ่าน broker ผู้รับในทุกเครื่องที่ใช้ broker เดียวกันจึงได้ข้อความด้วย
+type hub struct {
+    log    logger.Logger
+    broker broker.Broker
+
+    mu            sync.RWMutex
+    topics        map[string]map[subscriber]struct{}
+    subscriptions map[subscriber]map[string]struct{} // ใช้ยกเลิกทุก topic ตอนผู้รับปิด
+    remotes       map[string]*remoteTopic             // key: shared topic ที่มีผู้รับในเครื่องนี้
+}
+
+// remoteTopic คือการ subscribe topic หนึ่งกับ broker แทนผู้รับทุกคนในเครื่องนี้
+type remoteTopic struct {
+    ready     chan struct{} // ปิดเมื่อ broker subscribe เสร็จ
+    cancel    func()        // ถือ b.mu ก่อนอ่าน
+    cancelled bool          // topic ไม่มีผู้รับแล้วก่อน broker subscribe เสร็จ
+}
+
+func newHub(log logger.Logger) *hub {
+    return &hub{
+        log:           log,
+        broker:        broker.NewLocal(),
+        topics:        make(map[string]map[subscriber]struct{}),
+        subscriptions: make(map[subscriber]map[string]struct{}),
+        remotes:       make(map[string]*remoteTopic),
+    }
+}
+
+// setBroker ต้องเรียกก่อนมีผู้รับ topic ที่ส่งผ่าน broker
+func (b *hub) setBroker(br broker.Broker) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    b.broker = br
+}
+
+// subscribe คืน false ถ้าผู้รับ subscribe topic นี้อยู่แล้ว ถ้าเป็น shared topic จะรอจน broker พร้อม
+// ข้อความที่ publish หลังจาก subscribe คืนค่าจึงไม่หาย
+func (b *hub) subscribe(topic string, sub subscriber) bool {
+    b.mu.Lock()
+    subs, exists := b.topics[topic]
+    if !exists {
+        subs = make(map[subscriber]struct{})
+        b.topics[topic] = subs
+    }
+    if _, exists := subs[sub]; exists {
+        b.mu.Unlock()
+        return false
+    }
+    subs[sub] = struct{}{}
+
+    topics, exists := b.subscriptions[sub]
+    if !exists {
+        topics = make(map[string]struct{})
+        b.subscriptions[sub] = topics
+    }
+    topics[topic] = struct{}{}
+
+    if !sharedTopic(topic) {
+        b.mu.Unlock()
+        return true
+    }
+    remote, exists := b.remotes[topic]
+    if exists {
+        b.mu.Unlock()
+        <-remote.ready
+        return true
+    }
+    remote = &remoteTopic{ready: make(chan struct{})}
+    b.remotes[topic] = remote
+    br := b.broker
+    b.mu.Unlock()
+
+    // broker อาจใช้เวลารอเครือข่าย จึงไม่ถือ lock ระหว่างนี้
+    cancel, err := br.Subscribe(topic, func(data []byte) { b.receive(topic, remote, data) })
+    if err != nil {
+        b.log.Error("Failed to subscribe to broker", logger.String("topic", topic), logger.Error(err))
+        cancel = func() {}
+    }
+    b.mu.Lock()
+    remote.cancel = cancel
+    cancelled := remote.cancelled
+    b.mu.Unlock()
+    close(remote.ready)
+
+    if cancelled {
+        cancel()
+    }
+    return true
+}
+
+func (b *hub) unsubscribe(topic string, sub subscriber) {
+    b.mu.Lock()
+    cancels := b.remove(nil, topic, sub)
+    b.mu.Unlock()
+    runAll(cancels)
+}
+
+// unsubscribeAll ยกเลิกทุก topic ของผู้รับ
+func (b *hub) unsubscribeAll(sub subscriber) {
+    var cancels []func()
+    b.mu.Lock()
+    for topic := range b.subscriptions[sub] {
+        cancels = b.remove(cancels, topic, sub)
+    }
+    b.mu.Unlock()
+    runAll(cancels)
+}
+
+// closeTopic ยกเลิกผู้รับทุกคนของ topic ในเครื่องนี้ที่จะไม่มีข้อความอีก เช่นเกมที่ถูกลบไปแล้ว
+func (b *hub) closeTopic(topic string) {
+    var cancels []func()
+    b.mu.Lock()
+    for sub := range b.topics[topic] {
+        cancels = b.remove(cancels, topic, sub)
+    }
+    b.mu.Unlock()
+    runAll(cancels)
+}
+
+// remove ต้องถือ b.mu อยู่ ถ้า topic ไม่มีผู้รับเหลือ จะเพิ่มการยกเลิกกับ broker ลงใน cancels ให้ผู้เรียกทำหลังปล่อย lock
+func (b *hub) remove(cancels []func(), topic string, sub subscriber) []func() {
+    if subs, exists := b.topics[topic]; exists {
+        delete(subs, sub)
+        if len(subs) == 0 {
+            delete(b.topics, topic)
+            if remote, exists := b.remotes[topic]; exists {
+                delete(b.remotes, topic)
+                if remote.cancel != nil {
+                    cancels = append(cancels, remote.cancel)
+                } else {
+                    remote.cancelled = true
+                }
+            }
+        }
+    }
+    if topics, exists := b.subscriptions[sub]; exists {
+        delete(topics, topic)
+        if len(topics) == 0 {
+            delete(b.subscriptions, sub)
+        }
+    }
+    return cancels
+}
+
+func runAll(fns []func()) {
+    for _, fn := range fns {
+        fn()
+    }
+}
+
+// subscribers คืนจำนวนผู้รับของ topic ใช้ข้ามงานที่ไม่มีใครรอรับ
+func (b *hub) subscribers(topic string) int {
+    b.mu.RLock()
+    defer b.mu.RUnlock()
+    return len(b.topics[topic])
+}
+
+// publish ส่งข้อความให้ทุกผู้รับของ topic ตามลำดับที่ถูกเรียก ไม่ถือ lock ขณะส่ง
+// shared topic ส่งผ่าน broker แล้วกลับมาถึงผู้รับในเครื่องนี้ทาง receive เหมือนเครื่องอื่น
+func (b *hub) publish(topic string, message Envelope) {
+    message.Topic = topic
+    if !sharedTopic(topic) {
+        b.fanOut(topic, message)
+        return
+    }
+
+    data, err := json.Marshal(message)
+    if err != nil {
+        b.log.Error("Failed to encode message for broker", logger.String("topic", topic), logger.Error(err))
+        return
+    }
+    b.mu.RLock()
+    br := b.broker
+    b.mu.RUnlock()
+    if err := br.Publish(topic, data); err != nil {
+        b.log.Error("Failed to publish to broker",
+            logger.String("topic", topic),
+            logger.String("messageType", message.Type),
+            logger.Error(err))
+    }
+}
+
+// receive รับข้อความจาก broker ข้อความของ remote ที่ถูกแทนที่แล้วไม่ส่งต่อ เพื่อไม่ให้ผู้รับได้ซ้ำ
+func (b *hub) receive(topic string, remote *remoteTopic, data []byte) {
+    b.mu.RLock()
+    current := b.remotes[topic] == remote
+    b.mu.RUnlock()
+    if !current {
+        return
+    }
+
+    var message Envelope
+    if err := json.Unmarshal(data, &message); err != nil {
+        b.log.Error("Failed to decode message from broker", logger.String("topic", topic), logger.Error(err))
+        return
+    }
+    b.fanOut(topic, message)
+}
+
+// fanOut ส่งข้อความให้ผู้รับของ topic ในเครื่องนี้
+func (b *hub) fanOut(topic string, message Envelope) {
+    b.mu.RLock()
+    subs := make([]subscriber, 0, len(b.topics[topic]))
+    for sub := range b.topics[topic] {
+        subs = append(subs, sub)
+    }
+    b.mu.RUnlock()
+
+    for _, sub := range subs {
+        sub.deliver(message)
+    }
+}
+
+// wsSession คือการเชื่อมต่อ WebSocket หนึ่งอันในฐานะผู้รับของ hub
+type wsSession struct {
+    h        *GameHandler
+    playerID string
+    conn     *wsConn
+}
+
+func (s *wsSession) deliver(message Envelope) {
+    s.h.deliver(s.playerID, s.conn, message)
+}
+
+// TopicFunc ตรวจว่าผู้เล่นดู topic ชนิดนี้ที่ id นี้ได้หรือไม่ และคืนสถานะปัจจุบันของ topic ที่ตอบไปใน ack ของ subscribe
+// หลังจากนั้นผู้รับจะได้เฉพาะข้อความที่ถูก publish ไปยัง topic
+type TopicFunc func(playerID, id string) (interface{}, error)
+
+// TopicPublisher ส่งข้อความไปยังทุกผู้รับของ topic ปกติคือ GameHandler.Publish
+type TopicPublisher func(topic string, message Envelope)
+
+type TopicCommand struct {
+    Topic string `json:"topic"`
+}
+
+func (c TopicCommand) validate() error { return requireFields("topic", c.Topic) }
+
+type LobbyListPayload struct {
+    Games []*game.Game `json:"games"`
+}
+
+type AnnouncementPayload struct {
+    Message string `json:"message"`
+}
+
+type AnnounceRequest struct {
+    Message string `json:"message" binding:"required"`
+}
+
+// RegisterTopic เพิ่มชนิดของ topic ที่ client subscribe เองได้ผ่านคำสั่ง subscribe
+func (h *GameHandler) RegisterTopic(kind string, fn TopicFunc) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    h.topics[kind] = fn
+}
+
+// registerGameTopics ลงทะเบียน topic ของเกมและล็อบบี้
+func (h *GameHandler) registerGameTopics() {
+    // รายการใน ack คือรายการล่าสุดที่ผู้รับ lobby ได้ lobbyChanged จึงเทียบกับรายการนี้ได้
+    h.topics[TopicLobby] = func(playerID, id string) (interface{}, error) {
+        h.lobbyMu.Lock()
+        defer h.lobbyMu.Unlock()
+        raw, err := json.Marshal(LobbyListPayload{Games: h.waitingGames()})
+        if err != nil {
+            return nil, err
+        }
+        h.lobbyLast = raw
+        return json.RawMessage(raw), nil
+    }
+
+    // ผู้เล่นในเกมได้สถานะในมุมของตัวเองอยู่แล้ว topic นี้จึงเป็นของผู้ชมเท่านั้น
+    h.topics[TopicGame] = func(playerID, id string) (interface{}, error) {
+        _, viewer, err := h.gameManager.View(id, playerID, false)
+        if err != nil {
+            return nil, err
+        }
+        if viewer.Role != game.ViewerSpectator {
+            return nil, fmt.Errorf("players of game %s already receive its updates", id)
+        }
+        return h.gameSnapshot(id, playerID)
+    }
+}
+
+// Publish ส่งข้อความไปยังทุกการเชื่อมต่อที่ subscribe topic ไว้
+func (h *GameHandler) Publish(topic string, message Envelope) {
+    h.hub.publish(topic, message)
+}
+
+// SetBroker ส่งข้อความถึงผู้เล่นและประกาศผ่าน broker ผู้เล่นที่เชื่อมต่อกับเครื่องอื่นจึงได้ข้อความของเกมในเครื่องนี้
+// ต้องเรียกก่อนเปิดรับการเชื่อมต่อ ถ้าไม่เรียกจะใช้ broker ภายใน process
+// คำสั่งที่อ่านสถานะเกม เช่น get_game_state และ topic game ยังใช้ได้เฉพาะกับเกมในเครื่องที่เชื่อมต่ออยู่
+func (h *GameHandler) SetBroker(br broker.Broker) {
+    h.hub.setBroker(br)
+}
+
+// dispatchSession จัดการคำสั่ง subscribe และ unsubscribe ที่ผูกกับการเชื่อมต่อ คำสั่งอื่นส่งต่อให้ dispatch
+func (h *GameHandler) dispatchSession(session *wsSession, env Envelope) Envelope {
+    if env.V != ProtocolVersion || (env.Type != CmdSubscribe && env.Type != CmdUnsubscribe) {
+        return h.dispatch(session.playerID, env)
+    }
+
+    var cmd TopicCommand
+    if err := decodePayload(env.Payload, &cmd); err != nil {
+        return errorEnvelope(env.RequestID, commandErrorCode(err), err.Error())
+    }
+
+    kind, id, _ := strings.Cut(cmd.Topic, ":")
+    h.mu.RLock()
+    fn, exists := h.topics[kind]
+    h.mu.RUnlock()
+    if !exists || (kind == TopicLobby) != (id == "") {
+        return errorEnvelope(env.RequestID, ErrCodeRejected, fmt.Sprintf("cannot subscribe to topic %q", cmd.Topic))
+    }
+
+    var result interface{}
+    if env.Type == CmdSubscribe {
+        // subscribe ก่อนอ่านสถานะ ข้อความที่เกิดระหว่างนี้จะไม่หาย อย่างมากได้ซ้ำกับสถานะใน ack
+        h.hub.subscribe(cmd.Topic, session)
+        state, err := fn(session.playerID, id)
+        if err != nil {
+            h.hub.unsubscribe(cmd.Topic, session)
+            return errorEnvelope(env.RequestID, commandErrorCode(err), err.Error())
+        }
+        result = state
+    } else {
+        h.hub.unsubscribe(cmd.Topic, session)
+    }
+
+    ack := newEnvelope(MsgAck, result)
+    ack.RequestID = env.RequestID
+    return ack
+}
+
+// waitingGames คืนเกมที่รอผู้เล่นในมุมของผู้ชม ซึ่งเป็นมุมที่ทุกคนใน topic lobby เห็นเหมือนกัน
+func (h *GameHandler) waitingGames() []*game.Game {
+    return h.gameManager.WaitingGamesView("", false)
+}
+
+// lobbyChanged ขอให้ส่งรายการเกมที่รอผู้เล่นใหม่ เรียกได้ขณะที่ GameManager ถือ lock อยู่
+// เพราะรายการถูกอ่านใน goroutine แยก การเรียกหลายครั้งติดกันรวมเป็นการส่งครั้งเดียว
+func (h *GameHandler) lobbyChanged() {
+    if h.hub.subscribers(TopicLobby) == 0 || !h.lobbyDirty.CompareAndSwap(false, true) {
+        return
+    }
+    go func() {
+        h.lobbyMu.Lock()
+        defer h.lobbyMu.Unlock()
+        h.lobbyDirty.Store(false)
+
+        raw, err := json.Marshal(LobbyListPayload{Games: h.waitingGames()})
+        if err != nil {
+            h.log.Error("Failed to encode lobby list", logger.Error(err))
+            return
+        }
+        // การเปลี่ยนแปลงส่วนใหญ่เป็นของเกมที่เล่นอยู่ ไม่ต้องส่งรายการเดิมซ้ำ
+        if string(raw) == string(h.lobbyLast) {
+            return
+        }
+        h.lobbyLast = raw
+        h.Publish(TopicLobby, Envelope{V: ProtocolVersion, Type: MsgLobbyList, Payload: raw})
+    }()
+}
+
+// gameRemoved ลบทุกอย่างที่ผูกกับเกมที่ GameManager ลบไปแล้ว
+func (h *GameHandler) gameRemoved(g *game.Game) {
+    h.states.forget(g.ID)
+    h.hub.closeTopic(gameTopic(g.ID))
+    h.lobbyChanged()
+}
+
+// Announce ส่งประกาศไปยังทุกการเชื่อมต่อผ่าน topic global ใช้ได้เฉพาะ admin
+func (h *GameHandler) Announce(c *gin.Context) {
+    claims, err := h.getPlayerClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+    if !claims.IsAdmin() {
+        c.JSON(http.StatusForbidden, gin.H{"error": "admin only"})
+        return
+    }
+
+    var req AnnounceRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    h.Publish(TopicGlobal, newEnvelope(MsgAnnouncement, AnnouncementPayload{Message: req.Message}))
+    h.log.Info("Announcement sent", logger.String("playerID", claims.PlayerID))
+    c.JSON(http.StatusOK, gin.H{"message": "Announcement sent"})
+}
\ No newline at end of file
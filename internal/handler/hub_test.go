@@ -0,0 +1,325 @@
+package handler
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/golang-jwt/jwt/v5"
+    "github.com/gorilla/websocket"
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/internal/middleware"
+    "github.com/tem-mars/tft-game-server/internal/repository"
+    "github.com/tem-mars/tft-game-server/pkg/broker"
+    "github.com/tem-mars/tft-game-server/pkg/broker/brokertest"
+    "github.com/tem-mars/tft-game-server/pkg/logger"
+)
+
+// recorder เก็บทุกข้อความที่ได้รับจาก hub
+type recorder struct {
+    received []Envelope
+}
+
+func (r *recorder) deliver(message Envelope) { r.received = append(r.received, message) }
+
+func TestHub(t *testing.T) {
+    b := newHub(logger.New())
+    alice, bob := &recorder{}, &recorder{}
+    b.subscribe(TopicLobby, alice)
+    b.subscribe(TopicLobby, bob)
+    b.subscribe(gameTopic("g1"), alice)
+
+    t.Run("Fan Out To Every Subscriber", func(t *testing.T) {
+        b.publish(TopicLobby, newEnvelope(MsgLobbyList, nil))
+        if len(alice.received) != 1 || len(bob.received) != 1 {
+            t.Fatalf("expected one message each, got %d and %d", len(alice.received), len(bob.received))
+        }
+        if alice.received[0].Topic != TopicLobby {
+            t.Errorf("expected topic %q on message, got %q", TopicLobby, alice.received[0].Topic)
+        }
+    })
+
+    t.Run("Duplicate Subscribe Is Ignored", func(t *testing.T) {
+        if b.subscribe(TopicLobby, alice) {
+            t.Error("expected second subscribe to report existing subscription")
+        }
+        if b.subscribers(TopicLobby) != 2 {
+            t.Errorf("expected 2 subscribers, got %d", b.subscribers(TopicLobby))
+        }
+    })
+
+    t.Run("Unsubscribe All", func(t *testing.T) {
+        b.unsubscribeAll(alice)
+        b.publish(gameTopic("g1"), newEnvelope(MsgGameState, nil))
+        if len(alice.received) != 1 || b.subscribers(TopicLobby) != 1 {
+            t.Errorf("expected alice to be gone from every topic, got %d messages", len(alice.received))
+        }
+        if _, exists := b.subscriptions[alice]; exists {
+            t.Error("expected no subscriptions left for alice")
+        }
+    })
+
+    t.Run("Close Topic", func(t *testing.T) {
+        b.closeTopic(TopicLobby)
+        if b.subscribers(TopicLobby) != 0 || len(b.topics) != 0 || len(b.subscriptions) != 0 {
+            t.Errorf("expected hub to be empty, got %d topics", len(b.topics))
+        }
+    })
+
+    t.Run("Shared Topics Cross Nodes", func(t *testing.T) {
+        shared := broker.NewLocal()
+        nodeA, nodeB := newHub(logger.New()), newHub(logger.New())
+        nodeA.setBroker(shared)
+        nodeB.setBroker(shared)
+        carol := &recorder{}
+        nodeB.subscribe(playerTopic("carol"), carol)
+        nodeB.subscribe(TopicLobby, carol)
+
+        nodeA.publish(playerTopic("carol"), newEnvelope(MsgGameState, nil))
+        nodeA.publish(TopicGlobal, newEnvelope(MsgAnnouncement, nil))
+        nodeA.publish(TopicLobby, newEnvelope(MsgLobbyList, nil))
+        if len(carol.received) != 1 || carol.received[0].Topic != playerTopic("carol") {
+            t.Fatalf("expected only the player message from the other node, got %+v", carol.received)
+        }
+
+        nodeB.unsubscribeAll(carol)
+        if len(nodeB.remotes) != 0 {
+            t.Errorf("expected broker subscriptions to be cancelled, got %d", len(nodeB.remotes))
+        }
+        nodeA.publish(playerTopic("carol"), newEnvelope(MsgGameState, nil))
+        if len(carol.received) != 1 {
+            t.Errorf("expected no message after unsubscribe, got %d", len(carol.received))
+        }
+    })
+}
+
+// TestBrokerAcrossNodes จำลอง server สองเครื่องที่ใช้ broker เดียวกัน เกมอยู่เครื่อง A แต่ผู้เล่นเชื่อมต่อกับเครื่อง B
+func TestBrokerAcrossNodes(t *testing.T) {
+    redis, err := brokertest.NewServer("")
+    if err != nil {
+        t.Fatalf("failed to start broker: %v", err)
+    }
+    defer redis.Close()
+
+    gin.SetMode(gin.TestMode)
+    log := logger.New()
+    playerRepo := repository.NewMemoryPlayerRepository()
+    alice, _ := playerRepo.Create(context.Background(), "alice", "alice@example.com", "secret")
+    bob, _ := playerRepo.Create(context.Background(), "bob", "bob@example.com", "secret")
+
+    newNode := func() (*GameHandler, *game.GameManager) {
+        gameManager := game.NewGameManager(playerRepo)
+        handler := NewGameHandler(gameManager, log, "test-secret")
+        br, err := broker.NewRedis(broker.RedisConfig{Addr: redis.Addr(), Prefix: "tft:"}, log)
+        if err != nil {
+            t.Fatalf("failed to connect to broker: %v", err)
+        }
+        t.Cleanup(func() { br.Close() })
+        handler.SetBroker(br)
+        return handler, gameManager
+    }
+    handlerA, gameManagerA := newNode()
+    handlerB, _ := newNode()
+
+    router := gin.New()
+    router.GET("/games/ws", handlerB.HandleWebSocket)
+    server := httptest.NewServer(router)
+    defer server.Close()
+
+    token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &middleware.Claims{PlayerID: alice.ID}).SignedString([]byte("test-secret"))
+    conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/games/ws?token="+token, nil)
+    if err != nil {
+        t.Fatalf("failed to connect: %v", err)
+    }
+    defer conn.Close()
+
+    next := func(msgType string) (Envelope, bool) {
+        conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+        for {
+            var env Envelope
+            if err := conn.ReadJSON(&env); err != nil {
+                return Envelope{}, false
+            }
+            if env.Type == msgType {
+                return env, true
+            }
+        }
+    }
+    if _, ok := next(MsgWelcome); !ok {
+        t.Fatal("expected welcome")
+    }
+
+    t.Run("Game Updates Reach Other Node", func(t *testing.T) {
+        g, err := gameManagerA.CreateGame(bob.ID, game.LobbyOptions{})
+        if err != nil {
+            t.Fatalf("failed to create game: %v", err)
+        }
+        if err := gameManagerA.JoinGame(g.ID, alice.ID); err != nil {
+            t.Fatalf("failed to join: %v", err)
+        }
+
+        state, ok := next(MsgGameState)
+        var payload GameStatePayload
+        json.Unmarshal(state.Payload, &payload)
+        if !ok || payload.GameID != g.ID || state.Topic != playerTopic(alice.ID) {
+            t.Fatalf("expected game state from node A, got %+v", state)
+        }
+    })
+
+    t.Run("Announcements Reach Other Node", func(t *testing.T) {
+        handlerA.Publish(TopicGlobal, newEnvelope(MsgAnnouncement, AnnouncementPayload{Message: "hello"}))
+        announcement, ok := next(MsgAnnouncement)
+        var payload AnnouncementPayload
+        json.Unmarshal(announcement.Payload, &payload)
+        if !ok || payload.Message != "hello" {
+            t.Errorf("expected announcement from node A, got %+v", announcement)
+        }
+    })
+}
+
+func TestTopicSubscriptions(t *testing.T) {
+    router, gameManager, playerRepo, log := setupTestRouter()
+    alice, _ := playerRepo.Create(context.Background(), "alice", "alice@example.com", "secret")
+    bob, _ := playerRepo.Create(context.Background(), "bob", "bob@example.com", "secret")
+    carol, _ := playerRepo.Create(context.Background(), "carol", "carol@example.com", "secret")
+
+    handler := NewGameHandler(gameManager, log, "test-secret")
+    router.GET("/games/ws", handler.HandleWebSocket)
+    router.POST("/games", withClaims(bob.ID), handler.CreateGame)
+    router.POST("/announcements", func(c *gin.Context) {
+        c.Set("claims", &middleware.Claims{PlayerID: alice.ID, Role: middleware.RoleAdmin})
+    }, handler.Announce)
+    server := httptest.NewServer(router)
+    defer server.Close()
+
+    token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &middleware.Claims{PlayerID: carol.ID}).SignedString([]byte("test-secret"))
+    conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/games/ws?token="+token, nil)
+    if err != nil {
+        t.Fatalf("failed to connect: %v", err)
+    }
+    defer conn.Close()
+
+    // next อ่านข้อความถัดไปที่ match ยอมรับ ข้ามข้อความอื่น คืน false ถ้าไม่มีภายใน wait
+    next := func(match func(Envelope) bool, wait time.Duration) (Envelope, bool) {
+        deadline := time.Now().Add(wait)
+        for {
+            var env Envelope
+            conn.SetReadDeadline(deadline)
+            if err := conn.ReadJSON(&env); err != nil {
+                return Envelope{}, false
+            }
+            if match(env) {
+                return env, true
+            }
+        }
+    }
+    ofType := func(msgType string) func(Envelope) bool {
+        return func(env Envelope) bool { return env.Type == msgType }
+    }
+    send := func(t *testing.T, cmdType, requestID, topic string) Envelope {
+        t.Helper()
+        command := newEnvelope(cmdType, TopicCommand{Topic: topic})
+        command.RequestID = requestID
+        if err := conn.WriteJSON(command); err != nil {
+            t.Fatalf("failed to send: %v", err)
+        }
+        reply, ok := next(func(env Envelope) bool { return env.RequestID == requestID }, time.Second)
+        if !ok {
+            t.Fatalf("no reply to %s", requestID)
+        }
+        return reply
+    }
+
+    if _, ok := next(ofType(MsgWelcome), time.Second); !ok {
+        t.Fatal("expected welcome")
+    }
+
+    var gameID string
+    t.Run("Lobby Updates Live", func(t *testing.T) {
+        ack := send(t, CmdSubscribe, "r1", TopicLobby)
+        var initial LobbyListPayload
+        json.Unmarshal(ack.Payload, &initial)
+        if ack.Type != MsgAck || len(initial.Games) != 0 {
+            t.Fatalf("expected ack with empty lobby, got %s %s", ack.Type, ack.Payload)
+        }
+
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/games", nil))
+        if resp.Code != http.StatusOK {
+            t.Fatalf("failed to create game: %s", resp.Body)
+        }
+
+        update, ok := next(ofType(MsgLobbyList), time.Second)
+        var lobby LobbyListPayload
+        json.Unmarshal(update.Payload, &lobby)
+        if !ok || update.Topic != TopicLobby || len(lobby.Games) != 1 {
+            t.Fatalf("expected lobby list with the new game, got %+v", update)
+        }
+        gameID = lobby.Games[0].ID
+        if lobby.Games[0].JoinCode != "" {
+            t.Error("expected lobby list to use the spectator view")
+        }
+    })
+
+    t.Run("Spectators Follow Game Topic", func(t *testing.T) {
+        ack := send(t, CmdSubscribe, "r2", gameTopic(gameID))
+        var snapshot GameStatePayload
+        json.Unmarshal(ack.Payload, &snapshot)
+        if ack.Type != MsgAck || snapshot.GameID != gameID {
+            t.Fatalf("expected ack with game snapshot, got %s %s", ack.Type, ack.Payload)
+        }
+
+        if err := gameManager.JoinGame(gameID, alice.ID); err != nil {
+            t.Fatalf("failed to join: %v", err)
+        }
+        state, ok := next(ofType(MsgGameState), time.Second)
+        if !ok || state.Topic != gameTopic(gameID) {
+            t.Fatalf("expected game state on %s, got %+v", gameTopic(gameID), state)
+        }
+    })
+
+    t.Run("Players Cannot Subscribe Their Own Game", func(t *testing.T) {
+        if _, err := handler.topics[TopicGame](alice.ID, gameID); err == nil {
+            t.Error("expected players of the game to be rejected")
+        }
+    })
+
+    t.Run("Unknown Topics Are Rejected", func(t *testing.T) {
+        for _, topic := range []string{playerTopic(bob.ID), TopicGlobal, "game:", "lobby:x", "chat:1"} {
+            if reply := send(t, CmdSubscribe, "r5", topic); reply.Type != MsgError {
+                t.Errorf("expected error for %q, got %s", topic, reply.Type)
+            }
+        }
+    })
+
+    t.Run("Announcements Reach Everyone", func(t *testing.T) {
+        body := strings.NewReader(`{"message":"maintenance soon"}`)
+        resp := httptest.NewRecorder()
+        router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/announcements", body))
+        if resp.Code != http.StatusOK {
+            t.Fatalf("failed to announce: %s", resp.Body)
+        }
+
+        announcement, ok := next(ofType(MsgAnnouncement), time.Second)
+        var payload AnnouncementPayload
+        json.Unmarshal(announcement.Payload, &payload)
+        if !ok || announcement.Topic != TopicGlobal || payload.Message != "maintenance soon" {
+            t.Errorf("expected announcement on global, got %+v", announcement)
+        }
+    })
+
+    t.Run("Unsubscribed Topics Go Quiet", func(t *testing.T) {
+        send(t, CmdUnsubscribe, "r6", TopicLobby)
+        dave, _ := playerRepo.Create(context.Background(), "dave", "dave@example.com", "secret")
+        gameManager.CreateGame(dave.ID, game.LobbyOptions{})
+        handler.lobbyChanged()
+        if update, ok := next(ofType(MsgLobbyList), 200*time.Millisecond); ok {
+            t.Errorf("expected no lobby list after unsubscribe, got %+v", update)
+        }
+    })
+}
\ No newline at end of file
@@ -0,0 +1,118 @@
+package handler
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "github.com/tem-mars/tft-game-server/internal/service"
+    "github.com/tem-mars/tft-game-server/pkg/logger"
+)
+
+const (
+    defaultLeaderboardLimit = 50
+    maxLeaderboardLimit     = 100
+)
+
+type LeaderboardService interface {
+    Page(board string, offset, limit int) ([]service.LeaderboardEntry, int, error)
+    Position(board, playerID string) (service.LeaderboardEntry, error)
+    Among(board string, playerIDs []string) ([]service.LeaderboardEntry, error)
+}
+
+type LeaderboardHandler struct {
+    leaderboards LeaderboardService
+    log          logger.Logger
+}
+
+func NewLeaderboardHandler(leaderboards LeaderboardService, log logger.Logger) *LeaderboardHandler {
+    return &LeaderboardHandler{
+        leaderboards: leaderboards,
+        log:          log,
+    }
+}
+
+// GetLeaderboard คืนอันดับแบบแบ่งหน้า ?offset=0&limit=50 พร้อมตำแหน่งของผู้เรียกถ้าอยู่ในกระดาน
+func (h *LeaderboardHandler) GetLeaderboard(c *gin.Context) {
+    claims, err := getClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+    limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLeaderboardLimit)))
+    if limit <= 0 || limit > maxLeaderboardLimit {
+        limit = defaultLeaderboardLimit
+    }
+
+    board := c.Param("board")
+    entries, total, err := h.leaderboards.Page(board, offset, limit)
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    response := gin.H{
+        "board":   board,
+        "entries": entries,
+        "total":   total,
+        "offset":  offset,
+        "limit":   limit,
+    }
+    if me, err := h.leaderboards.Position(board, claims.PlayerID); err == nil {
+        response["me"] = me
+    }
+    c.JSON(http.StatusOK, response)
+}
+
+func (h *LeaderboardHandler) GetMyPosition(c *gin.Context) {
+    claims, err := getClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    entry, err := h.leaderboards.Position(c.Param("board"), claims.PlayerID)
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"entry": entry})
+}
+
+// GetFriendsLeaderboard คืนอันดับเฉพาะผู้เล่นใน ?players=id1,id2 รวมตัวผู้เรียกด้วย
+// ยังไม่มีระบบเพื่อนในเซิร์ฟเวอร์ client จึงเป็นคนส่งรายชื่อมา
+func (h *LeaderboardHandler) GetFriendsLeaderboard(c *gin.Context) {
+    claims, err := getClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    playerIDs := []string{claims.PlayerID}
+    for _, id := range strings.Split(c.Query("players"), ",") {
+        if id = strings.TrimSpace(id); id != "" && id != claims.PlayerID {
+            playerIDs = append(playerIDs, id)
+        }
+    }
+    if len(playerIDs) > maxLeaderboardLimit {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "too many players"})
+        return
+    }
+
+    entries, err := h.leaderboards.Among(c.Param("board"), playerIDs)
+    if err != nil {
+        code := http.StatusBadRequest
+        if errors.Is(err, service.ErrUnknownBoard) {
+            code = http.StatusNotFound
+        }
+        c.JSON(code, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"board": c.Param("board"), "entries": entries})
+}
\ No newline at end of file
@@ -0,0 +1,85 @@
+package handler
+
+import (
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/tem-mars/tft-game-server/internal/repository"
+    "github.com/tem-mars/tft-game-server/pkg/logger"
+)
+
+const (
+    defaultMatchesLimit = 20
+    maxMatchesLimit     = 100
+)
+
+type MatchHistoryService interface {
+    GetMatch(matchID string) (*repository.MatchRecord, error)
+    ListPlayerMatches(playerID string, offset, limit int) ([]*repository.MatchRecord, int, error)
+    SetOnRecorded(callback func(*repository.MatchRecord))
+}
+
+type MatchHandler struct {
+    matches MatchHistoryService
+    notify  PlayerNotifier
+    log     logger.Logger
+}
+
+func NewMatchHandler(matches MatchHistoryService, notify PlayerNotifier, log logger.Logger) *MatchHandler {
+    handler := &MatchHandler{
+        matches: matches,
+        notify:  notify,
+        log:     log,
+    }
+
+    // ส่งสรุปหลังเกมให้ผู้เล่นทุกคนผ่าน WebSocket เมื่อเกมจบ
+    matches.SetOnRecorded(handler.pushSummary)
+
+    return handler
+}
+
+func (h *MatchHandler) pushSummary(match *repository.MatchRecord) {
+    for _, p := range match.Participants {
+        if p.IsBot {
+            continue
+        }
+        h.notify(p.PlayerID, newEnvelope(MsgGameSummary, GameSummaryPayload{Match: match, You: p}))
+    }
+}
+
+// GetPlayerMatches คืนประวัติการแข่งแบบแบ่งหน้า ?offset=0&limit=20 จากใหม่ไปเก่า
+func (h *MatchHandler) GetPlayerMatches(c *gin.Context) {
+    offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+    limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultMatchesLimit)))
+    if limit <= 0 || limit > maxMatchesLimit {
+        limit = defaultMatchesLimit
+    }
+
+    playerID := c.Param("id")
+    matches, total, err := h.matches.ListPlayerMatches(playerID, offset, limit)
+    if err != nil {
+        h.log.Error("Failed to list matches",
+            logger.String("playerID", playerID),
+            logger.Error(err))
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "matches": matches,
+        "total":   total,
+        "offset":  offset,
+        "limit":   limit,
+    })
+}
+
+func (h *MatchHandler) GetMatch(c *gin.Context) {
+    match, err := h.matches.GetMatch(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"match": match})
+}
\ No newline at end of file
@@ -0,0 +1,189 @@
+package handler
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "sync"
+    "sync/atomic"
+
+    "github.com/gin-gonic/gin"
+    "github.com/tem-mars/tft-game-server/internal/service"
+    "github.com/tem-mars/tft-game-server/pkg/logger"
+)
+
+type MatchmakingService interface {
+    Enqueue(playerID string) (service.QueueStatus, error)
+    Cancel(playerID string) error
+    Status(playerID string) (service.QueueStatus, error)
+    Accept(playerID string) error
+    Decline(playerID string) error
+    Stats() service.QueueStats
+    SetOnStatus(callback func(service.QueueStatus))
+}
+
+// QueueRanked คือ id ของคิวจับคู่ใน topic queue:<id> ตอนนี้มีคิวเดียว
+const QueueRanked = "ranked"
+
+type QueueStatsPayload struct {
+    Queue string `json:"queue"`
+    service.QueueStats
+}
+
+// PlayerNotifier ส่งข้อความไปยัง WebSocket ของผู้เล่น ปกติคือ GameHandler.SendToPlayer
+type PlayerNotifier func(playerID string, message Envelope)
+
+type MatchmakingHandler struct {
+    matchmaking MatchmakingService
+    parties     PartyService
+    notify      PlayerNotifier
+    publish     TopicPublisher
+    log         logger.Logger
+
+    statsMu    sync.Mutex // เรียงการส่งสถิติให้ตรงกับลำดับที่อ่าน
+    statsDirty atomic.Bool
+    statsLast  service.QueueStats
+}
+
+func NewMatchmakingHandler(matchmaking MatchmakingService, parties PartyService, notify PlayerNotifier, publish TopicPublisher, log logger.Logger) *MatchmakingHandler {
+    handler := &MatchmakingHandler{
+        matchmaking: matchmaking,
+        parties:     parties,
+        notify:      notify,
+        publish:     publish,
+        log:         log,
+    }
+
+    // ส่งสถานะคิวให้ผู้เล่นผ่าน WebSocket ทุกครั้งที่เปลี่ยน
+    matchmaking.SetOnStatus(handler.pushQueueStatus)
+
+    return handler
+}
+
+func (h *MatchmakingHandler) pushQueueStatus(status service.QueueStatus) {
+    msgType := MsgQueueStatus
+    if status.State == service.QueueStateMatchFound {
+        msgType = MsgMatchFound
+    }
+
+    h.notify(status.PlayerID, newEnvelope(msgType, status))
+    h.queueChanged()
+}
+
+// QueueTopic คือ TopicFunc ของ topic queue:<id> ack มีสถิติล่าสุดของคิว
+func (h *MatchmakingHandler) QueueTopic(playerID, id string) (interface{}, error) {
+    if id != QueueRanked {
+        return nil, fmt.Errorf("unknown queue %q", id)
+    }
+
+    h.statsMu.Lock()
+    defer h.statsMu.Unlock()
+    h.statsLast = h.matchmaking.Stats()
+    return QueueStatsPayload{Queue: id, QueueStats: h.statsLast}, nil
+}
+
+// queueChanged ขอให้ส่งสถิติของคิวใหม่ถ้าเปลี่ยน สถานะคิวถูกแจ้งขณะที่ MatchmakingService ถือ lock อยู่
+// สถิติจึงถูกอ่านใน goroutine แยก การเรียกหลายครั้งติดกันรวมเป็นการอ่านครั้งเดียว
+func (h *MatchmakingHandler) queueChanged() {
+    if !h.statsDirty.CompareAndSwap(false, true) {
+        return
+    }
+    go func() {
+        h.statsMu.Lock()
+        defer h.statsMu.Unlock()
+        h.statsDirty.Store(false)
+
+        stats := h.matchmaking.Stats()
+        if stats == h.statsLast {
+            return
+        }
+        h.statsLast = stats
+        h.publish(topicOf(TopicQueue, QueueRanked), newEnvelope(MsgQueueStats, QueueStatsPayload{Queue: QueueRanked, QueueStats: stats}))
+    }()
+}
+
+func (h *MatchmakingHandler) JoinQueue(c *gin.Context) {
+    claims, err := getClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    // เข้าคิวผ่านปาร์ตี้ เพื่อให้หัวหน้าพาทั้งปาร์ตี้เข้าคิวพร้อมกัน
+    status, err := h.parties.Queue(claims.PlayerID)
+    if err != nil {
+        h.log.Error("Failed to join queue",
+            logger.String("playerID", claims.PlayerID),
+            logger.Error(err))
+        code := http.StatusBadRequest
+        if errors.Is(err, service.ErrQueueLocked) {
+            code = http.StatusForbidden
+        }
+        c.JSON(code, gin.H{"error": err.Error()})
+        return
+    }
+
+    h.queueChanged()
+    c.JSON(http.StatusOK, gin.H{"status": status})
+}
+
+func (h *MatchmakingHandler) LeaveQueue(c *gin.Context) {
+    claims, err := getClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.matchmaking.Cancel(claims.PlayerID); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Left queue"})
+}
+
+func (h *MatchmakingHandler) GetQueueStatus(c *gin.Context) {
+    claims, err := getClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    status, err := h.matchmaking.Status(claims.PlayerID)
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"status": status})
+}
+
+func (h *MatchmakingHandler) AcceptMatch(c *gin.Context) {
+    claims, err := getClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.matchmaking.Accept(claims.PlayerID); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Match accepted"})
+}
+
+func (h *MatchmakingHandler) DeclineMatch(c *gin.Context) {
+    claims, err := getClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.matchmaking.Decline(claims.PlayerID); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Match declined"})
+}
\ No newline at end of file
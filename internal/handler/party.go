@@ -0,0 +1,158 @@
+package handler
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "github.com/tem-mars/tft-game-server/internal/service"
+    "github.com/tem-mars/tft-game-server/pkg/logger"
+)
+
+type PartyService interface {
+    Get(playerID string) (service.Party, error)
+    Invite(leaderID, targetID string) (service.Party, error)
+    AcceptInvite(playerID, partyID string) (service.Party, error)
+    DeclineInvite(playerID, partyID string) error
+    Leave(playerID string) error
+    Kick(leaderID, targetID string) error
+    Queue(playerID string) (service.QueueStatus, error)
+    SetOnEvent(callback func(recipients []string, event service.PartyEvent))
+}
+
+type PartyInviteRequest struct {
+    PlayerID string `json:"player_id" binding:"required"`
+}
+
+type PartyHandler struct {
+    parties PartyService
+    notify  PlayerNotifier
+    log     logger.Logger
+}
+
+func NewPartyHandler(parties PartyService, notify PlayerNotifier, log logger.Logger) *PartyHandler {
+    handler := &PartyHandler{
+        parties: parties,
+        notify:  notify,
+        log:     log,
+    }
+
+    // ส่งสถานะปาร์ตี้ให้สมาชิกและคนที่ถูกเชิญผ่าน WebSocket
+    parties.SetOnEvent(handler.pushPartyEvent)
+
+    return handler
+}
+
+func (h *PartyHandler) pushPartyEvent(recipients []string, event service.PartyEvent) {
+    for _, playerID := range recipients {
+        h.notify(playerID, newEnvelope(MsgPartyUpdate, event))
+    }
+}
+
+func (h *PartyHandler) GetParty(c *gin.Context) {
+    claims, err := getClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    party, err := h.parties.Get(claims.PlayerID)
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"party": party})
+}
+
+func (h *PartyHandler) Invite(c *gin.Context) {
+    claims, err := getClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    var req PartyInviteRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    party, err := h.parties.Invite(claims.PlayerID, req.PlayerID)
+    if err != nil {
+        h.log.Error("Failed to invite player",
+            logger.String("playerID", claims.PlayerID),
+            logger.String("targetID", req.PlayerID),
+            logger.Error(err))
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"party": party})
+}
+
+func (h *PartyHandler) AcceptInvite(c *gin.Context) {
+    claims, err := getClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    party, err := h.parties.AcceptInvite(claims.PlayerID, c.Param("partyId"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"party": party})
+}
+
+func (h *PartyHandler) DeclineInvite(c *gin.Context) {
+    claims, err := getClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.parties.DeclineInvite(claims.PlayerID, c.Param("partyId")); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Invite declined"})
+}
+
+func (h *PartyHandler) Leave(c *gin.Context) {
+    claims, err := getClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.parties.Leave(claims.PlayerID); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Left party"})
+}
+
+func (h *PartyHandler) Kick(c *gin.Context) {
+    claims, err := getClaims(c)
+    if err != nil {
+        c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+        return
+    }
+
+    var req KickPlayerRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    if err := h.parties.Kick(claims.PlayerID, req.PlayerID); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"message": "Player kicked"})
+}
\ No newline at end of file
@@ -0,0 +1,95 @@
+package handler
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "github.com/tem-mars/tft-game-server/internal/domain/rank"
+    "github.com/tem-mars/tft-game-server/internal/repository"
+    "github.com/tem-mars/tft-game-server/internal/service"
+    "github.com/tem-mars/tft-game-server/pkg/logger"
+)
+
+type RatingService interface {
+    GetRating(playerID string) (*repository.Rating, error)
+    GetHistory(playerID string) ([]repository.RatingChange, error)
+}
+
+type RankService interface {
+    GetRank(playerID string) (*repository.Rank, error)
+    GetSeasonHistory(playerID string) ([]repository.SeasonResult, error)
+    CurrentSeason() service.Season
+}
+
+type PlayerHandler struct {
+    ratings RatingService
+    ranks   RankService
+    log     logger.Logger
+}
+
+func NewPlayerHandler(ratings RatingService, ranks RankService, log logger.Logger) *PlayerHandler {
+    return &PlayerHandler{
+        ratings: ratings,
+        ranks:   ranks,
+        log:     log,
+    }
+}
+
+func (h *PlayerHandler) GetRating(c *gin.Context) {
+    playerID := c.Param("id")
+
+    rating, err := h.ratings.GetRating(playerID)
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"rating": rating})
+}
+
+func (h *PlayerHandler) GetRatingHistory(c *gin.Context) {
+    playerID := c.Param("id")
+
+    history, err := h.ratings.GetHistory(playerID)
+    if err != nil {
+        h.log.Error("Failed to get rating history",
+            logger.String("playerID", playerID),
+            logger.Error(err))
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"history": history})
+}
+func (h *PlayerHandler) GetRank(c *gin.Context) {
+    playerID := c.Param("id")
+
+    r, err := h.ranks.GetRank(playerID)
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    display := rank.Rank{Tier: rank.Tier(r.Tier), Division: r.Division, LP: r.LP}
+    c.JSON(http.StatusOK, gin.H{
+        "rank": r,
+        "display": display.String(),
+        "season": h.ranks.CurrentSeason(),
+    })
+}
+
+func (h *PlayerHandler) GetSeasonHistory(c *gin.Context) {
+    playerID := c.Param("id")
+
+    seasons, err := h.ranks.GetSeasonHistory(playerID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"seasons": seasons})
+}
+
+func (h *PlayerHandler) GetCurrentSeason(c *gin.Context) {
+    c.JSON(http.StatusOK, gin.H{"season": h.ranks.CurrentSeason()})
+}
\ No newline at end of file
@@ -0,0 +1,252 @@
+package handler
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/internal/repository"
+)
+
+// ProtocolVersion คือเวอร์ชันของรูปแบบข้อความบน WebSocket ของเกม
+const ProtocolVersion = 1
+
+// Envelope คือรูปแบบของทุกข้อความบน WebSocket ทั้งคำสั่งจาก client และข้อความจาก server
+// RequestID ที่ client ส่งมาจะถูกส่งกลับใน ack หรือ error ของคำสั่งนั้น
+type Envelope struct {
+    V         int             `json:"v"`
+    Type      string          `json:"type"`
+    RequestID string          `json:"request_id,omitempty"`
+    Topic     string          `json:"topic,omitempty"` // topic ที่ข้อความนี้ถูก publish มา ข้อความที่ตอบคำสั่งไม่มี
+    Payload   json.RawMessage `json:"payload,omitempty"`
+}
+
+// ชนิดของข้อความที่ server ส่ง
+const (
+    MsgWelcome         = "welcome"
+    MsgAck             = "ack"
+    MsgError           = "error"
+    MsgGameState       = "game_state"
+    MsgGamePatch       = "game_patch" // การเปลี่ยนแปลงของสถานะเกมต่อจาก seq ก่อนหน้า
+    MsgQueueStatus     = "queue_status"
+    MsgMatchFound      = "match_found"
+    MsgPartyUpdate     = "party_update"
+    MsgGameSummary     = "game_summary"
+    MsgResync          = "resync"           // ข้อความตกหล่นเพราะอ่านไม่ทัน client ควรขอสถานะใหม่ด้วย get_game_state
+    MsgSessionReplaced = "session_replaced" // มีการเชื่อมต่อใหม่ของผู้เล่นคนเดียวกัน อันนี้จะถูกปิด
+    MsgLobbyList       = "lobby_list"       // รายการเกมที่รอผู้เล่นทั้งหมด ส่งใน topic lobby ทุกครั้งที่เปลี่ยน
+    MsgQueueStats      = "queue_stats"
+    MsgAnnouncement    = "announcement"
+)
+
+// ชนิดของคำสั่งที่ client ส่งได้
+const (
+    CmdGetGameState = "get_game_state"
+    CmdAttack       = "attack"
+    CmdSurrender    = "surrender"
+    CmdSendItem     = "send_item"
+    CmdSubscribe    = "subscribe"   // payload คือ TopicCommand ack มีสถานะปัจจุบันของ topic
+    CmdUnsubscribe  = "unsubscribe"
+)
+
+// รหัสของ error ที่ตอบกลับใน ErrorPayload
+const (
+    ErrCodeMalformed      = "malformed"
+    ErrCodeVersion        = "unsupported_version"
+    ErrCodeUnknownType    = "unknown_type"
+    ErrCodeInvalidPayload = "invalid_payload"
+    ErrCodeNotFound       = "not_found"
+    ErrCodeRejected       = "rejected"
+)
+
+type WelcomePayload struct {
+    PlayerID string `json:"player_id"`
+    Message  string `json:"message"`
+}
+
+type ErrorPayload struct {
+    Code    string `json:"code"`
+    Message string `json:"message"`
+}
+
+type GameSummaryPayload struct {
+    Match *repository.MatchRecord      `json:"match"`
+    You   repository.MatchParticipant `json:"you"`
+}
+
+// command คือ payload ของคำสั่ง validate ตรวจฟิลด์ที่จำเป็น
+type command interface {
+    validate() error
+}
+
+type GameStateCommand struct {
+    GameID string `json:"game_id"`
+}
+
+func (c GameStateCommand) validate() error { return requireFields("game_id", c.GameID) }
+
+type AttackCommand struct {
+    GameID   string `json:"game_id"`
+    TargetID string `json:"target_id"`
+}
+
+func (c AttackCommand) validate() error {
+    return requireFields("game_id", c.GameID, "target_id", c.TargetID)
+}
+
+type SurrenderCommand struct {
+    GameID string `json:"game_id"`
+}
+
+func (c SurrenderCommand) validate() error { return requireFields("game_id", c.GameID) }
+
+type SendItemCommand struct {
+    GameID   string `json:"game_id"`
+    TargetID string `json:"target_id"`
+    ItemID   string `json:"item_id"`
+}
+
+func (c SendItemCommand) validate() error {
+    return requireFields("game_id", c.GameID, "target_id", c.TargetID, "item_id", c.ItemID)
+}
+
+// CommandFunc จัดการคำสั่งหนึ่งชนิด ค่าที่คืนจะเป็น payload ของ ack
+type CommandFunc func(playerID string, payload json.RawMessage) (interface{}, error)
+
+// payloadError คือ payload ที่อ่านไม่ได้หรือขาดฟิลด์ที่จำเป็น
+type payloadError struct {
+    err error
+}
+
+func (e payloadError) Error() string { return e.err.Error() }
+
+// newEnvelope สร้างข้อความจาก server พร้อม payload
+func newEnvelope(msgType string, payload interface{}) Envelope {
+    env := Envelope{V: ProtocolVersion, Type: msgType}
+    if payload == nil {
+        return env
+    }
+    data, err := json.Marshal(payload)
+    if err != nil {
+        return errorEnvelope("", ErrCodeMalformed, err.Error())
+    }
+    env.Payload = data
+    return env
+}
+
+func errorEnvelope(requestID, code, message string) Envelope {
+    env := newEnvelope(MsgError, ErrorPayload{Code: code, Message: message})
+    env.RequestID = requestID
+    return env
+}
+
+// decodePayload อ่าน payload เข้า cmd แบบไม่ยอมรับฟิลด์ที่ไม่รู้จัก แล้วตรวจฟิลด์ที่จำเป็น
+func decodePayload(raw json.RawMessage, cmd command) error {
+    if len(raw) == 0 {
+        raw = json.RawMessage("{}")
+    }
+    decoder := json.NewDecoder(bytes.NewReader(raw))
+    decoder.DisallowUnknownFields()
+    if err := decoder.Decode(cmd); err != nil {
+        return payloadError{err}
+    }
+    if err := cmd.validate(); err != nil {
+        return payloadError{err}
+    }
+    return nil
+}
+
+// requireFields รับคู่ ชื่อ, ค่า และคืน error ของฟิลด์แรกที่ว่าง
+func requireFields(pairs ...string) error {
+    for i := 0; i+1 < len(pairs); i += 2 {
+        if pairs[i+1] == "" {
+            return fmt.Errorf("%s is required", pairs[i])
+        }
+    }
+    return nil
+}
+
+// RegisterCommand เพิ่มหรือแทนที่ตัวจัดการคำสั่งบน WebSocket
+func (h *GameHandler) RegisterCommand(msgType string, fn CommandFunc) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    h.commands[msgType] = fn
+}
+
+// registerGameCommands ลงทะเบียนคำสั่งของเกมที่ client ส่งมาได้
+func (h *GameHandler) registerGameCommands() {
+    h.commands[CmdGetGameState] = func(playerID string, raw json.RawMessage) (interface{}, error) {
+        var cmd GameStateCommand
+        if err := decodePayload(raw, &cmd); err != nil {
+            return nil, err
+        }
+        return h.gameSnapshot(cmd.GameID, playerID)
+    }
+
+    h.commands[CmdAttack] = func(playerID string, raw json.RawMessage) (interface{}, error) {
+        var cmd AttackCommand
+        if err := decodePayload(raw, &cmd); err != nil {
+            return nil, err
+        }
+        return nil, h.gameManager.ProcessAction(cmd.GameID, game.GameAction{
+            Type:      game.ActionAttack,
+            PlayerID:  playerID,
+            TargetID:  cmd.TargetID,
+            Timestamp: time.Now(),
+        })
+    }
+
+    h.commands[CmdSurrender] = func(playerID string, raw json.RawMessage) (interface{}, error) {
+        var cmd SurrenderCommand
+        if err := decodePayload(raw, &cmd); err != nil {
+            return nil, err
+        }
+        return nil, h.gameManager.Surrender(cmd.GameID, playerID)
+    }
+
+    h.commands[CmdSendItem] = func(playerID string, raw json.RawMessage) (interface{}, error) {
+        var cmd SendItemCommand
+        if err := decodePayload(raw, &cmd); err != nil {
+            return nil, err
+        }
+        return nil, h.gameManager.SendItem(cmd.GameID, playerID, cmd.TargetID, cmd.ItemID)
+    }
+}
+
+// dispatch ส่งคำสั่งที่ถอดรหัสแล้วให้ตัวจัดการตาม type และคืน ack หรือ error ที่ผูกกับ request_id
+func (h *GameHandler) dispatch(playerID string, env Envelope) Envelope {
+    if env.V != ProtocolVersion {
+        return errorEnvelope(env.RequestID, ErrCodeVersion, fmt.Sprintf("protocol version %d is not supported, use %d", env.V, ProtocolVersion))
+    }
+
+    h.mu.RLock()
+    fn, exists := h.commands[env.Type]
+    h.mu.RUnlock()
+    if !exists {
+        return errorEnvelope(env.RequestID, ErrCodeUnknownType, fmt.Sprintf("unknown message type %q", env.Type))
+    }
+
+    result, err := fn(playerID, env.Payload)
+    if err != nil {
+        return errorEnvelope(env.RequestID, commandErrorCode(err), err.Error())
+    }
+
+    ack := newEnvelope(MsgAck, result)
+    ack.RequestID = env.RequestID
+    return ack
+}
+
+func commandErrorCode(err error) string {
+    var perr payloadError
+    switch {
+    case errors.As(err, &perr):
+        return ErrCodeInvalidPayload
+    case errors.Is(err, game.ErrGameNotFound), errors.Is(err, game.ErrPlayerNotFound):
+        return ErrCodeNotFound
+    default:
+        return ErrCodeRejected
+    }
+}
\ No newline at end of file
@@ -0,0 +1,106 @@
+package handler
+
+import (
+    "context"
+    "encoding/json"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/golang-jwt/jwt/v5"
+    "github.com/gorilla/websocket"
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/internal/middleware"
+)
+
+func TestWebSocketProtocol(t *testing.T) {
+    router, gameManager, playerRepo, log := setupTestRouter()
+    alice, _ := playerRepo.Create(context.Background(), "alice", "alice@example.com", "secret")
+    bob, _ := playerRepo.Create(context.Background(), "bob", "bob@example.com", "secret")
+    played, _ := gameManager.CreateMatch([]string{alice.ID, bob.ID})
+
+    handler := NewGameHandler(gameManager, log, "test-secret")
+    router.GET("/games/ws", handler.HandleWebSocket)
+    server := httptest.NewServer(router)
+    defer server.Close()
+
+    token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &middleware.Claims{PlayerID: alice.ID}).SignedString([]byte("test-secret"))
+    url := "ws" + strings.TrimPrefix(server.URL, "http") + "/games/ws?token=" + token
+
+    conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+    if err != nil {
+        t.Fatalf("failed to connect: %v", err)
+    }
+    defer conn.Close()
+
+    var welcome Envelope
+    if err := conn.ReadJSON(&welcome); err != nil || welcome.Type != MsgWelcome || welcome.V != ProtocolVersion {
+        t.Fatalf("expected welcome envelope, got %+v (%v)", welcome, err)
+    }
+
+    // roundTrip ส่งข้อความดิบแล้วอ่านคำตอบถัดไปที่ไม่ใช่ game_state หรือ game_patch
+    roundTrip := func(t *testing.T, raw string) (Envelope, ErrorPayload) {
+        t.Helper()
+        if err := conn.WriteMessage(websocket.TextMessage, []byte(raw)); err != nil {
+            t.Fatalf("failed to send: %v", err)
+        }
+        for {
+            var reply Envelope
+            if err := conn.ReadJSON(&reply); err != nil {
+                t.Fatalf("failed to read reply: %v", err)
+            }
+            if reply.Type == MsgGameState || reply.Type == MsgGamePatch {
+                continue
+            }
+            var payload ErrorPayload
+            if reply.Type == MsgError {
+                json.Unmarshal(reply.Payload, &payload)
+            }
+            return reply, payload
+        }
+    }
+
+    tests := []struct {
+        name      string
+        raw       string
+        wantType  string
+        wantCode  string
+        requestID string
+    }{
+        {"Malformed JSON", `{"v":1,`, MsgError, ErrCodeMalformed, ""},
+        {"Wrong Version", `{"v":99,"type":"attack","request_id":"r1"}`, MsgError, ErrCodeVersion, "r1"},
+        {"Unknown Type", `{"v":1,"type":"dance","request_id":"r2"}`, MsgError, ErrCodeUnknownType, "r2"},
+        {"Missing Field", `{"v":1,"type":"attack","request_id":"r3","payload":{"game_id":"` + played.ID + `"}}`, MsgError, ErrCodeInvalidPayload, "r3"},
+        {"Unknown Field", `{"v":1,"type":"surrender","request_id":"r4","payload":{"game":"x"}}`, MsgError, ErrCodeInvalidPayload, "r4"},
+        {"Game Not Found", `{"v":1,"type":"surrender","request_id":"r5","payload":{"game_id":"nope"}}`, MsgError, ErrCodeNotFound, "r5"},
+        {"Attack Acked", `{"v":1,"type":"attack","request_id":"r6","payload":{"game_id":"` + played.ID + `","target_id":"` + bob.ID + `"}}`, MsgAck, "", "r6"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            reply, payload := roundTrip(t, tt.raw)
+            if reply.Type != tt.wantType || payload.Code != tt.wantCode || reply.RequestID != tt.requestID {
+                t.Errorf("expected %s %q for %q, got %s %q for %q", tt.wantType, tt.wantCode, tt.requestID, reply.Type, payload.Code, reply.RequestID)
+            }
+        })
+    }
+
+    t.Run("Game State Returned In Ack", func(t *testing.T) {
+        reply, _ := roundTrip(t, `{"v":1,"type":"get_game_state","request_id":"r7","payload":{"game_id":"`+played.ID+`"}}`)
+        var snapshot GameStatePayload
+        json.Unmarshal(reply.Payload, &snapshot)
+        var state game.Game
+        if err := json.Unmarshal(snapshot.Game, &state); err != nil || state.ID != played.ID {
+            t.Errorf("expected ack with game %s, got %s (%v)", played.ID, reply.Payload, err)
+        }
+        // การโจมตีก่อนหน้าถูกส่งไปแล้ว snapshot ต้องบอก seq ที่ patch ถัดไปจะต่อจาก
+        if snapshot.Seq == 0 {
+            t.Errorf("expected snapshot seq of the published state, got 0")
+        }
+        // alice เห็นเกมในมุมของตัวเอง ทองของ bob ถูกซ่อน
+        for _, p := range state.Players {
+            if p.ID == bob.ID && p.Gold != 0 {
+                t.Errorf("expected bob's gold hidden from alice, got %d", p.Gold)
+            }
+        }
+    })
+}
\ No newline at end of file
@@ -0,0 +1,198 @@
+package handler
+
+import (
+    "fmt"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/gorilla/websocket"
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/pkg/logger"
+)
+
+const (
+    minReplaySpeed = 0.25
+    maxReplaySpeed = 16
+)
+
+type ReplayService interface {
+    Get(gameID string) (*game.Replay, error)
+    Export(gameID string) ([]byte, error)
+}
+
+// replayControl คือคำสั่งจากผู้ชมระหว่างเล่น replay
+// pause | resume | speed (value) | seek (index หรือ offset_ms)
+type replayControl struct {
+    Type     string  `json:"type"`
+    Value    float64 `json:"value,omitempty"`
+    Index    *int    `json:"index,omitempty"`
+    OffsetMS *int64  `json:"offset_ms,omitempty"`
+}
+
+type ReplayHandler struct {
+    replays ReplayService
+    log     logger.Logger
+    secret  string
+}
+
+func NewReplayHandler(replays ReplayService, log logger.Logger, secret string) *ReplayHandler {
+    return &ReplayHandler{
+        replays: replays,
+        log:     log,
+        secret:  secret,
+    }
+}
+
+// ExportReplay ส่งไฟล์ replay (JSON บีบอัดด้วย gzip) ให้ดาวน์โหลด
+func (h *ReplayHandler) ExportReplay(c *gin.Context) {
+    gameID := c.Param("id")
+    data, err := h.replays.Export(gameID)
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.replay.json.gz"`, gameID))
+    c.Data(http.StatusOK, "application/gzip", data)
+}
+
+// StreamReplay เล่น replay ผ่าน WebSocket ตามเวลาจริงคูณ ?speed= ผู้ชมส่งคำสั่งหยุด ปรับความเร็ว หรือกระโดดได้
+func (h *ReplayHandler) StreamReplay(c *gin.Context) {
+    if _, err := parseToken(c.Query("token"), h.secret); err != nil {
+        c.String(http.StatusUnauthorized, "Invalid token")
+        return
+    }
+
+    replay, err := h.replays.Get(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+
+    speed := 1.0
+    if raw := c.Query("speed"); raw != "" {
+        if parsed, err := strconv.ParseFloat(raw, 64); err == nil {
+            speed = clampSpeed(parsed)
+        }
+    }
+
+    conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+    if err != nil {
+        h.log.Error("Failed to upgrade connection", logger.Error(err))
+        return
+    }
+    defer conn.Close()
+
+    // อ่านคำสั่งใน goroutine แยก ส่วนการเขียนทั้งหมดอยู่ใน loop ด้านล่างที่เดียว
+    controls := make(chan replayControl)
+    done := make(chan struct{})
+    defer close(done)
+    go func() {
+        defer close(controls)
+        for {
+            var msg replayControl
+            if err := conn.ReadJSON(&msg); err != nil {
+                return
+            }
+            select {
+            case controls <- msg:
+            case <-done:
+                return
+            }
+        }
+    }()
+
+    player := game.NewReplayPlayer(replay)
+    if err := conn.WriteJSON(map[string]interface{}{
+        "type":        "replay_start",
+        "game_id":     replay.GameID,
+        "actions":     len(replay.Actions),
+        "duration_ms": replay.Duration().Milliseconds(),
+        "speed":       speed,
+        "state":       player.Game(),
+    }); err != nil {
+        return
+    }
+
+    var clock time.Duration // เวลาในเกม ณ ตำแหน่งปัจจุบัน
+    paused := false
+    ended := false
+
+    for {
+        var timer <-chan time.Time
+        if !paused && !player.Done() {
+            next := replay.Offset(player.Position())
+            timer = time.After(time.Duration(float64(next-clock) / speed))
+        }
+
+        select {
+        case <-timer:
+            action, _ := player.Step()
+            clock = replay.Offset(player.Position() - 1)
+            if err := sendReplayFrame(conn, player, &action, clock); err != nil {
+                return
+            }
+
+        case msg, ok := <-controls:
+            if !ok {
+                return
+            }
+            switch msg.Type {
+            case "pause":
+                paused = true
+            case "resume":
+                paused = false
+            case "speed":
+                speed = clampSpeed(msg.Value)
+            case "seek":
+                switch {
+                case msg.Index != nil:
+                    player.Seek(*msg.Index)
+                    clock = 0
+                    if player.Position() > 0 {
+                        clock = replay.Offset(player.Position() - 1)
+                    }
+                case msg.OffsetMS != nil:
+                    clock = time.Duration(*msg.OffsetMS) * time.Millisecond
+                    player.SeekTime(clock)
+                default:
+                    continue
+                }
+                ended = false
+                if err := sendReplayFrame(conn, player, nil, clock); err != nil {
+                    return
+                }
+            }
+        }
+
+        if player.Done() && !ended {
+            ended = true
+            if err := conn.WriteJSON(map[string]interface{}{"type": "replay_end"}); err != nil {
+                return
+            }
+        }
+    }
+}
+
+// sendReplayFrame ส่งสถานะเกม ณ ตำแหน่งปัจจุบัน action เป็น nil เมื่อเป็นผลจากการกระโดด
+func sendReplayFrame(conn *websocket.Conn, player *game.ReplayPlayer, action *game.GameAction, clock time.Duration) error {
+    return conn.WriteJSON(map[string]interface{}{
+        "type":      "replay_frame",
+        "index":     player.Position(),
+        "offset_ms": clock.Milliseconds(),
+        "action":    action,
+        "state":     player.Game(),
+    })
+}
+
+func clampSpeed(speed float64) float64 {
+    if speed < minReplaySpeed {
+        return minReplaySpeed
+    }
+    if speed > maxReplaySpeed {
+        return maxReplaySpeed
+    }
+    return speed
+}
\ No newline at end of file
@@ -0,0 +1,100 @@
+package handler
+
+import (
+    "context"
+    "errors"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/golang-jwt/jwt/v5"
+    "github.com/gorilla/websocket"
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/internal/middleware"
+)
+
+// fakeReplays คืน replay ที่เตรียมไว้ตาม ID
+type fakeReplays map[string]*game.Replay
+
+func (f fakeReplays) Get(gameID string) (*game.Replay, error) {
+    if replay, ok := f[gameID]; ok {
+        return replay, nil
+    }
+    return nil, errors.New("replay not found")
+}
+
+func (f fakeReplays) Export(gameID string) ([]byte, error) {
+    replay, err := f.Get(gameID)
+    if err != nil {
+        return nil, err
+    }
+    return replay.Encode()
+}
+
+func TestStreamReplay(t *testing.T) {
+    router, gameManager, playerRepo, log := setupTestRouter()
+    alice, _ := playerRepo.Create(context.Background(), "alice", "alice@example.com", "secret")
+    bob, _ := playerRepo.Create(context.Background(), "bob", "bob@example.com", "secret")
+
+    played, _ := gameManager.CreateMatch([]string{alice.ID, bob.ID})
+    gameManager.BuyItem(played.ID, alice.ID, "sword")
+    gameManager.Surrender(played.ID, bob.ID)
+    replay, err := game.NewReplay(played)
+    if err != nil {
+        t.Fatalf("failed to create replay: %v", err)
+    }
+
+    handler := NewReplayHandler(fakeReplays{played.ID: replay}, log, "test-secret")
+    router.GET("/replays/:id/ws", handler.StreamReplay)
+    server := httptest.NewServer(router)
+    defer server.Close()
+
+    token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &middleware.Claims{PlayerID: alice.ID}).SignedString([]byte("test-secret"))
+    url := "ws" + strings.TrimPrefix(server.URL, "http") + "/replays/" + played.ID + "/ws?speed=16&token=" + token
+
+    conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+    if err != nil {
+        t.Fatalf("failed to connect: %v", err)
+    }
+    defer conn.Close()
+    conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+    read := func() map[string]interface{} {
+        t.Helper()
+        var msg map[string]interface{}
+        if err := conn.ReadJSON(&msg); err != nil {
+            t.Fatalf("failed to read message: %v", err)
+        }
+        return msg
+    }
+
+    if msg := read(); msg["type"] != "replay_start" || msg["actions"] != float64(2) {
+        t.Fatalf("expected replay_start with 2 actions, got %v", msg)
+    }
+
+    t.Run("Plays Every Action Then Ends", func(t *testing.T) {
+        for i := 1; i <= 2; i++ {
+            msg := read()
+            if msg["type"] != "replay_frame" || msg["index"] != float64(i) {
+                t.Fatalf("expected frame %d, got %v", i, msg)
+            }
+        }
+        if msg := read(); msg["type"] != "replay_end" {
+            t.Fatalf("expected replay_end, got %v", msg)
+        }
+    })
+
+    t.Run("Seek Rewinds State", func(t *testing.T) {
+        conn.WriteJSON(map[string]interface{}{"type": "seek", "index": 1})
+
+        msg := read()
+        if msg["type"] != "replay_frame" || msg["index"] != float64(1) || msg["action"] != nil {
+            t.Fatalf("expected seek frame at 1, got %v", msg)
+        }
+        state := msg["state"].(map[string]interface{})
+        if state["status"] != string(game.StatusPlaying) {
+            t.Errorf("expected game to be playing again after seek, got %v", state["status"])
+        }
+    })
+}
\ No newline at end of file
@@ -0,0 +1,73 @@
+package handler
+
+// SessionPolicy คือสิ่งที่ทำเมื่อผู้เล่นเปิดการเชื่อมต่อใหม่ขณะที่ยังมีอันเดิมอยู่
+type SessionPolicy string
+
+const (
+    SessionsAllowMultiple SessionPolicy = "multiple"    // ทุกแท็บ/อุปกรณ์ได้อัพเดทพร้อมกัน
+    SessionsKickOlder     SessionPolicy = "kick_older" // เหลือแค่การเชื่อมต่อล่าสุด
+)
+
+// sessionSet คือการเชื่อมต่อทั้งหมดของผู้เล่นหนึ่งคน
+type sessionSet map[*wsConn]struct{}
+
+// addSession เพิ่มการเชื่อมต่อของผู้เล่น คืนการเชื่อมต่อเก่าที่ต้องถูกเตะออกตาม SessionPolicy
+func (h *GameHandler) addSession(playerID string, client *wsConn) []*wsConn {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    sessions, exists := h.connections[playerID]
+    if !exists {
+        sessions = make(sessionSet)
+        h.connections[playerID] = sessions
+    }
+
+    var kicked []*wsConn
+    if h.sessionPolicy == SessionsKickOlder {
+        for old := range sessions {
+            kicked = append(kicked, old)
+            delete(sessions, old)
+        }
+    }
+    sessions[client] = struct{}{}
+    return kicked
+}
+
+// removeSession ลบการเชื่อมต่อออก คืน true ถ้าผู้เล่นไม่เหลือการเชื่อมต่อแล้ว
+func (h *GameHandler) removeSession(playerID string, client *wsConn) bool {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+
+    sessions, exists := h.connections[playerID]
+    if !exists {
+        return true
+    }
+    delete(sessions, client)
+    if len(sessions) > 0 {
+        return false
+    }
+    delete(h.connections, playerID)
+    return true
+}
+
+// sessions คืนสำเนาของการเชื่อมต่อทั้งหมดของผู้เล่น
+func (h *GameHandler) sessions(playerID string) []*wsConn {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+
+    clients := make([]*wsConn, 0, len(h.connections[playerID]))
+    for client := range h.connections[playerID] {
+        clients = append(clients, client)
+    }
+    return clients
+}
+
+// SetSessionPolicy เลือกว่าผู้เล่นเปิดหลายการเชื่อมต่อพร้อมกันได้หรือไม่
+func (h *GameHandler) SetSessionPolicy(policy SessionPolicy) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    if policy == "" {
+        policy = SessionsAllowMultiple
+    }
+    h.sessionPolicy = policy
+}
\ No newline at end of file
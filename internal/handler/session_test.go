@@ -0,0 +1,98 @@
+package handler
+
+import (
+    "context"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/golang-jwt/jwt/v5"
+    "github.com/gorilla/websocket"
+    "github.com/tem-mars/tft-game-server/internal/middleware"
+)
+
+func TestSessions(t *testing.T) {
+    router, gameManager, playerRepo, log := setupTestRouter()
+    alice, _ := playerRepo.Create(context.Background(), "alice", "alice@example.com", "secret")
+
+    handler := NewGameHandler(gameManager, log, "test-secret")
+    router.GET("/games/ws", handler.HandleWebSocket)
+    server := httptest.NewServer(router)
+    defer server.Close()
+
+    token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, &middleware.Claims{PlayerID: alice.ID}).SignedString([]byte("test-secret"))
+    url := "ws" + strings.TrimPrefix(server.URL, "http") + "/games/ws?token=" + token
+
+    // open เชื่อมต่อหนึ่งแท็บและอ่าน welcome ทิ้ง
+    open := func(t *testing.T) *websocket.Conn {
+        t.Helper()
+        conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+        if err != nil {
+            t.Fatalf("failed to connect: %v", err)
+        }
+        var welcome Envelope
+        if err := conn.ReadJSON(&welcome); err != nil || welcome.Type != MsgWelcome {
+            t.Fatalf("expected welcome, got %+v (%v)", welcome, err)
+        }
+        return conn
+    }
+    read := func(conn *websocket.Conn) (Envelope, error) {
+        var env Envelope
+        conn.SetReadDeadline(time.Now().Add(time.Second))
+        err := conn.ReadJSON(&env)
+        return env, err
+    }
+    sessionCount := func() int {
+        return len(handler.sessions(alice.ID))
+    }
+
+    t.Run("Every Tab Gets Updates", func(t *testing.T) {
+        first, second := open(t), open(t)
+
+        handler.SendToPlayer(alice.ID, newEnvelope(MsgQueueStatus, nil))
+        for i, conn := range []*websocket.Conn{first, second} {
+            if env, err := read(conn); err != nil || env.Type != MsgQueueStatus {
+                t.Errorf("expected tab %d to get the update, got %+v (%v)", i+1, env, err)
+            }
+        }
+
+        // ปิดแท็บแรก ผู้เล่นยังเชื่อมต่ออยู่ผ่านแท็บที่สอง
+        first.Close()
+        for deadline := time.Now().Add(time.Second); sessionCount() != 1 && time.Now().Before(deadline); {
+            time.Sleep(5 * time.Millisecond)
+        }
+        if sessionCount() != 1 {
+            t.Errorf("expected 1 session left, got %d", sessionCount())
+        }
+        second.Close()
+        for deadline := time.Now().Add(time.Second); sessionCount() != 0 && time.Now().Before(deadline); {
+            time.Sleep(5 * time.Millisecond)
+        }
+    })
+
+    t.Run("Kick Older Sessions", func(t *testing.T) {
+        handler.SetSessionPolicy(SessionsKickOlder)
+        defer handler.SetSessionPolicy(SessionsAllowMultiple)
+
+        first := open(t)
+        defer first.Close()
+        second := open(t)
+        defer second.Close()
+
+        if env, err := read(first); err != nil || env.Type != MsgSessionReplaced {
+            t.Fatalf("expected older tab to be told it was replaced, got %+v (%v)", env, err)
+        }
+        if _, err := read(first); err == nil {
+            t.Error("expected older tab to be closed")
+        }
+        if sessionCount() != 1 {
+            t.Errorf("expected only the newest session, got %d", sessionCount())
+        }
+
+        handler.SendToPlayer(alice.ID, newEnvelope(MsgQueueStatus, nil))
+        if env, err := read(second); err != nil || env.Type != MsgQueueStatus {
+            t.Errorf("expected newest tab to get updates, got %+v (%v)", env, err)
+        }
+    })
+}
\ No newline at end of file
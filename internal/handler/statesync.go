@@ -0,0 +1,126 @@
+package handler
+
+import (
+    "encoding/json"
+    "sync"
+
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/pkg/jsonpatch"
+)
+
+// GameStatePayload คือสถานะเต็มของเกม ณ seq ใช้ทั้งใน game_state และ ack ของ get_game_state
+type GameStatePayload struct {
+    GameID string          `json:"game_id"`
+    Seq    uint64          `json:"seq"`
+    Game   json.RawMessage `json:"game"`
+}
+
+// GamePatchPayload คือ JSON Patch (RFC 6902) ที่เปลี่ยนสถานะ seq-1 ให้เป็น seq
+// client ที่ seq ไม่ต่อกันต้องขอสถานะเต็มใหม่ด้วย get_game_state
+type GamePatchPayload struct {
+    GameID string                `json:"game_id"`
+    Seq    uint64                `json:"seq"`
+    Ops    []jsonpatch.Operation `json:"ops"`
+}
+
+// stateStream คือสถานะล่าสุดที่ส่งออกไปแล้วของเกมหนึ่งเกม
+type stateStream struct {
+    seq uint64
+    doc interface{}     // ใช้คำนวณ patch ครั้งถัดไป
+    raw json.RawMessage // ใช้ตอบ get_game_state โดยไม่ต้อง marshal ใหม่
+}
+
+// spectatorStream คือ key ของ stream ที่คนนอกเกมทุกคนใช้ร่วมกัน ไม่ชนกับ playerID
+const spectatorStream = "*spectator"
+
+// stateStreams เก็บเวอร์ชันของสถานะทุกเกมแยกตามผู้รับ เพราะแต่ละคนเห็นเกมไม่เหมือนกัน
+// patch จึงมีขนาดตามสิ่งที่เปลี่ยน ไม่ใช่ตามความยาวของเกม
+type stateStreams struct {
+    mu    sync.Mutex
+    games map[string]map[string]*stateStream // key: gameID แล้ว playerID ของผู้รับ
+}
+
+func newStateStreams() *stateStreams {
+    return &stateStreams{games: make(map[string]map[string]*stateStream)}
+}
+
+// publish บันทึกสถานะใหม่ที่ viewerID เห็นและส่งข้อความผ่าน send
+// ครั้งแรกส่ง game_state เต็ม ครั้งต่อไปส่ง game_patch และไม่ส่งอะไรถ้าไม่มีอะไรเปลี่ยน
+// send ถูกเรียกขณะถือ lock เพื่อให้ผู้รับได้ seq ตามลำดับ จึงต้องไม่บล็อก
+func (s *stateStreams) publish(viewerID string, view *game.Game, send func(Envelope)) error {
+    raw, err := json.Marshal(view)
+    if err != nil {
+        return err
+    }
+    var doc interface{}
+    if err := json.Unmarshal(raw, &doc); err != nil {
+        return err
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    viewers, exists := s.games[view.ID]
+    if !exists {
+        viewers = make(map[string]*stateStream)
+        s.games[view.ID] = viewers
+    }
+    stream, exists := viewers[viewerID]
+    if !exists {
+        stream = &stateStream{seq: 1, doc: doc, raw: raw}
+        viewers[viewerID] = stream
+        send(newEnvelope(MsgGameState, GameStatePayload{GameID: view.ID, Seq: stream.seq, Game: raw}))
+        return nil
+    }
+
+    ops := jsonpatch.Diff(stream.doc, doc)
+    if len(ops) == 0 {
+        return nil
+    }
+    stream.seq++
+    stream.doc = doc
+    stream.raw = raw
+    send(newEnvelope(MsgGamePatch, GamePatchPayload{GameID: view.ID, Seq: stream.seq, Ops: ops}))
+    return nil
+}
+
+// snapshot คืนสถานะเต็มล่าสุดที่ส่งให้ viewerID ตรงกับ seq ที่จะได้ patch ต่อจากนี้
+func (s *stateStreams) snapshot(gameID, viewerID string) (GameStatePayload, bool) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    stream, exists := s.games[gameID][viewerID]
+    if !exists {
+        return GameStatePayload{}, false
+    }
+    return GameStatePayload{GameID: gameID, Seq: stream.seq, Game: stream.raw}, true
+}
+
+// forget ลบเวอร์ชันของเกมที่จบแล้วของผู้รับทุกคน
+func (s *stateStreams) forget(gameID string) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    delete(s.games, gameID)
+}
+
+// gameSnapshot คืนสถานะเต็มที่ playerID เห็นสำหรับ get_game_state คนนอกเกมได้ seq ของ topic game:<id>
+// เกมที่ยังไม่เคยถูกส่งหรือจบไปแล้วจะได้ seq 0 ข้อความถัดไปของเกมนั้นจะเป็น game_state เต็ม
+func (h *GameHandler) gameSnapshot(gameID, playerID string) (GameStatePayload, error) {
+    if snapshot, exists := h.states.snapshot(gameID, playerID); exists {
+        return snapshot, nil
+    }
+    view, viewer, err := h.gameManager.View(gameID, playerID, false)
+    if err != nil {
+        return GameStatePayload{}, err
+    }
+    if viewer.Role == game.ViewerSpectator {
+        if snapshot, exists := h.states.snapshot(gameID, spectatorStream); exists {
+            return snapshot, nil
+        }
+    }
+    raw, err := json.Marshal(view)
+    if err != nil {
+        return GameStatePayload{}, err
+    }
+    return GameStatePayload{GameID: gameID, Game: raw}, nil
+}
\ No newline at end of file
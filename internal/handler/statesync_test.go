@@ -0,0 +1,126 @@
+package handler
+
+import (
+    "encoding/json"
+    "reflect"
+    "testing"
+    "time"
+
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/pkg/jsonpatch"
+)
+
+func TestStateStreams(t *testing.T) {
+    streams := newStateStreams()
+    g := &game.Game{
+        ID:     "game_1",
+        Status: game.StatusPlaying,
+        Players: []*game.Player{
+            {ID: "p1", Health: 100},
+            {ID: "p2", Health: 100},
+        },
+    }
+
+    // publishOne เรียก publish แล้วคืนข้อความที่ถูกส่ง (ถ้ามี)
+    publishOne := func(t *testing.T) (Envelope, bool) {
+        t.Helper()
+        var sent []Envelope
+        if err := streams.publish("p1", g, func(env Envelope) { sent = append(sent, env) }); err != nil {
+            t.Fatalf("publish failed: %v", err)
+        }
+        if len(sent) == 0 {
+            return Envelope{}, false
+        }
+        return sent[0], true
+    }
+
+    // attack จำลองหนึ่ง action ที่ทำให้ Actions ยาวขึ้น
+    attack := func() {
+        g.Players[1].Health -= 5
+        g.Actions = append(g.Actions, game.GameAction{Type: game.ActionAttack, PlayerID: "p1", TargetID: "p2", Timestamp: time.Unix(int64(len(g.Actions)), 0).UTC()})
+    }
+
+    first, _ := publishOne(t)
+    var snapshot GameStatePayload
+    json.Unmarshal(first.Payload, &snapshot)
+    if first.Type != MsgGameState || snapshot.Seq != 1 {
+        t.Fatalf("expected full game_state at seq 1, got %s seq %d", first.Type, snapshot.Seq)
+    }
+
+    var client interface{}
+    json.Unmarshal(snapshot.Game, &client)
+
+    t.Run("Unchanged Game Sends Nothing", func(t *testing.T) {
+        if env, sent := publishOne(t); sent {
+            t.Errorf("expected nothing to be sent, got %s", env.Type)
+        }
+    })
+
+    t.Run("Patches Are Sequential And Flat", func(t *testing.T) {
+        var firstSize int
+        for i := 0; i < 50; i++ {
+            attack()
+            env, sent := publishOne(t)
+            if !sent || env.Type != MsgGamePatch {
+                t.Fatalf("expected game_patch, got %+v", env)
+            }
+            var patch GamePatchPayload
+            json.Unmarshal(env.Payload, &patch)
+            if patch.Seq != uint64(i+2) {
+                t.Fatalf("expected seq %d, got %d", i+2, patch.Seq)
+            }
+
+            var err error
+            if client, err = jsonpatch.Apply(client, patch.Ops); err != nil {
+                t.Fatalf("failed to apply patch %d: %v", patch.Seq, err)
+            }
+
+            // ขนาดของ patch ต้องไม่โตตามจำนวน action ในเกม
+            if i == 0 {
+                firstSize = len(env.Payload)
+            } else if len(env.Payload) > firstSize+16 {
+                t.Fatalf("patch grew from %d to %d bytes after %d actions", firstSize, len(env.Payload), i+1)
+            }
+        }
+
+        want, _ := jsonpatch.Decode(g)
+        if !reflect.DeepEqual(client, want) {
+            t.Errorf("patched client state does not match the game")
+        }
+    })
+
+    t.Run("Snapshot Matches Latest Seq", func(t *testing.T) {
+        latest, exists := streams.snapshot(g.ID, "p1")
+        if !exists || latest.Seq != 51 {
+            t.Fatalf("expected snapshot at seq 51, got %d (%v)", latest.Seq, exists)
+        }
+        var state game.Game
+        if err := json.Unmarshal(latest.Game, &state); err != nil || len(state.Actions) != 50 {
+            t.Errorf("expected snapshot with 50 actions, got %d (%v)", len(state.Actions), err)
+        }
+    })
+
+    t.Run("Viewers Have Separate Streams", func(t *testing.T) {
+        var sent []Envelope
+        streams.publish("p2", g, func(env Envelope) { sent = append(sent, env) })
+        if len(sent) != 1 || sent[0].Type != MsgGameState {
+            t.Fatalf("expected a new viewer to start with full game_state, got %+v", sent)
+        }
+        if latest, _ := streams.snapshot(g.ID, "p1"); latest.Seq != 51 {
+            t.Errorf("expected p1 stream to stay at seq 51, got %d", latest.Seq)
+        }
+    })
+
+    t.Run("Forget Restarts With Full State", func(t *testing.T) {
+        streams.forget(g.ID)
+        _, p1 := streams.snapshot(g.ID, "p1")
+        _, p2 := streams.snapshot(g.ID, "p2")
+        if p1 || p2 {
+            t.Fatal("expected no snapshot after forget")
+        }
+        env, _ := publishOne(t)
+        if env.Type != MsgGameState {
+            t.Errorf("expected full game_state after forget, got %s", env.Type)
+        }
+    })
+}
\ No newline at end of file
@@ -8,12 +8,20 @@ import (
     "github.com/golang-jwt/jwt/v5"
 )
 
+// RoleAdmin คือ role ของ token ผู้ดูแล เห็นสถานะเกมทั้งหมดโดยไม่ถูกตัดข้อมูล
+const RoleAdmin = "admin"
+
 // Claims struct สำหรับเก็บข้อมูล JWT
 type Claims struct {
     PlayerID string `json:"player_id"`
+    Role     string `json:"role,omitempty"`
     jwt.RegisteredClaims
 }
 
+func (c *Claims) IsAdmin() bool {
+    return c.Role == RoleAdmin
+}
+
 func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
     return func(c *gin.Context) {
         authHeader := c.GetHeader("Authorization")
@@ -0,0 +1,105 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "time"
+)
+
+// MatchRecord คือข้อมูลของเกมที่จบแล้ว เก็บไว้หลังเกมถูกลบออกจาก GameManager
+type MatchRecord struct {
+    ID              string             `json:"id"`
+    Mode            string             `json:"mode"`
+    Ranked          bool               `json:"ranked"`
+    Rounds          int                `json:"rounds"`
+    StartedAt       time.Time          `json:"started_at"`
+    EndedAt         time.Time          `json:"ended_at"`
+    DurationSeconds int                `json:"duration_seconds"`
+    Participants    []MatchParticipant `json:"participants"` // เรียงตามอันดับ
+}
+
+// MatchParticipant คือผลของผู้เล่นหนึ่งคนในเกม พร้อมกระดานสุดท้าย
+type MatchParticipant struct {
+    PlayerID    string   `json:"player_id"`
+    Username    string   `json:"username"`
+    IsBot       bool     `json:"is_bot"`
+    Placement   int      `json:"placement"`
+    LastRound   int      `json:"last_round"`
+    Health      int      `json:"health"`
+    Level       int      `json:"level"`
+    Attack      int      `json:"attack"`
+    Defense     int      `json:"defense"`
+    Gold        int      `json:"gold"`
+    Items       []string `json:"items"`
+    GoldSpent   int      `json:"gold_spent"`
+    DamageDealt int      `json:"damage_dealt"`
+}
+
+type MatchRepository interface {
+    SaveMatch(ctx context.Context, match *MatchRecord) error
+    GetMatch(ctx context.Context, matchID string) (*MatchRecord, error)
+    ListPlayerMatches(ctx context.Context, playerID string, offset, limit int) ([]*MatchRecord, int, error)
+}
+
+func (r *MemoryPlayerRepository) SaveMatch(ctx context.Context, match *MatchRecord) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if _, exists := r.matches[match.ID]; exists {
+        return fmt.Errorf("match already exists")
+    }
+
+    copied := *match
+    r.matches[match.ID] = &copied
+    for _, p := range match.Participants {
+        if !p.IsBot {
+            r.playerMatches[p.PlayerID] = append(r.playerMatches[p.PlayerID], match.ID)
+        }
+    }
+    return nil
+}
+
+func (r *MemoryPlayerRepository) GetMatch(ctx context.Context, matchID string) (*MatchRecord, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    match, exists := r.matches[matchID]
+    if !exists {
+        return nil, fmt.Errorf("match not found")
+    }
+
+    copied := *match
+    return &copied, nil
+}
+
+// ListPlayerMatches คืนเกมของผู้เล่นจากใหม่ไปเก่า พร้อมจำนวนทั้งหมด
+func (r *MemoryPlayerRepository) ListPlayerMatches(ctx context.Context, playerID string, offset, limit int) ([]*MatchRecord, int, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    // เกมถูกบันทึกตอนจบ จึงไล่จากท้ายก็ได้เกมล่าสุดก่อน
+    ids := r.playerMatches[playerID]
+    matches := make([]*MatchRecord, 0, len(ids))
+    for i := len(ids) - 1; i >= 0; i-- {
+        matches = append(matches, r.matches[ids[i]])
+    }
+
+    total := len(matches)
+    if offset < 0 {
+        offset = 0
+    }
+    if offset > total {
+        offset = total
+    }
+    end := offset + limit
+    if limit <= 0 || end > total {
+        end = total
+    }
+
+    result := make([]*MatchRecord, 0, end-offset)
+    for _, match := range matches[offset:end] {
+        copied := *match
+        result = append(result, &copied)
+    }
+    return result, total, nil
+}
\ No newline at end of file
@@ -12,30 +12,81 @@ type Player struct {
     Username  string    `json:"username"`
     Email     string    `json:"email"`
     Password  string    `json:"-"` // ไม่แสดงใน JSON
+    MMR       int       `json:"mmr"`
     CreatedAt time.Time `json:"created_at"`
     UpdatedAt time.Time `json:"updated_at"`
     Stats     *Stats    `json:"stats"`
 }
 
 type Stats struct {
-    PlayerID  string    `json:"player_id"`
-    Wins      int       `json:"wins"`
-    Losses    int       `json:"losses"`
-    Gold      int       `json:"gold"`
-    Level     int       `json:"level"`
-    UpdatedAt time.Time `json:"updated_at"`
+    PlayerID       string    `json:"player_id"`
+    Wins           int       `json:"wins"`
+    Losses         int       `json:"losses"`
+    Gold           int       `json:"gold"`
+    Level          int       `json:"level"`
+    Games          int       `json:"games"`
+    PlacementTotal int       `json:"placement_total"` // ผลรวมอันดับทุกเกม ใช้หาอันดับเฉลี่ย
+    UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// clone คืนสำเนาของผู้เล่นรวม Stats ที่ส่งออกนอก lock ได้ UpdateRating และ RecordPlacement ยังแก้ตัวจริงอยู่
+func (p *Player) clone() *Player {
+    copied := *p
+    if p.Stats != nil {
+        stats := *p.Stats
+        copied.Stats = &stats
+    }
+    return &copied
 }
 
+func (s *Stats) AveragePlacement() float64 {
+    if s.Games == 0 {
+        return 0
+    }
+    return float64(s.PlacementTotal) / float64(s.Games)
+}
+
+// MMR เริ่มต้นของผู้เล่นใหม่
+const DefaultMMR = 1000
+
 type MemoryPlayerRepository struct {
     mu      sync.RWMutex
     players map[string]*Player // key: username
+    byID    map[string]*Player // key: playerID
     stats   map[string]*Stats // key: playerID
+
+    ratings       map[string]*Rating        // key: playerID
+    ratingHistory map[string][]RatingChange // key: playerID
+
+    ranks         map[string]*Rank          // key: playerID
+    seasonHistory map[string][]SeasonResult // key: playerID
+
+    queuePenalties map[string]*QueuePenalty // key: playerID
+
+    matches       map[string]*MatchRecord // key: matchID
+    playerMatches map[string][]string     // key: playerID, value: matchID ตามลำดับที่บันทึก
+
+    replays map[string][]byte // key: gameID
 }
 
 func NewMemoryPlayerRepository() *MemoryPlayerRepository {
     return &MemoryPlayerRepository{
         players: make(map[string]*Player),
+        byID:    make(map[string]*Player),
         stats:   make(map[string]*Stats),
+
+        ratings:       make(map[string]*Rating),
+        ratingHistory: make(map[string][]RatingChange),
+
+        ranks:         make(map[string]*Rank),
+        seasonHistory: make(map[string][]SeasonResult),
+
+        queuePenalties: make(map[string]*QueuePenalty),
+
+        matches:       make(map[string]*MatchRecord),
+        playerMatches: make(map[string][]string),
+
+        replays: make(map[string][]byte),
     }
 }
 
@@ -48,6 +99,7 @@ type PlayerRepository interface {
     Update(ctx context.Context, player *Player) error
     UpdateStats(ctx context.Context, stats *Stats) error
     GetStats(ctx context.Context, playerID string) (*Stats, error)
+    RecordPlacement(ctx context.Context, playerID string, placement int) error
 }
 
 // Implementation
@@ -65,6 +117,7 @@ func (r *MemoryPlayerRepository) Create(ctx context.Context, username, email, pa
         Username:  username,
         Email:     email,
         Password:  password,
+        MMR:       DefaultMMR,
         CreatedAt: now,
         UpdatedAt: now,
         Stats: &Stats{
@@ -75,10 +128,11 @@ func (r *MemoryPlayerRepository) Create(ctx context.Context, username, email, pa
     }
 
     r.players[username] = player
+    r.byID[player.ID] = player
     player.Stats.PlayerID = player.ID
     r.stats[player.ID] = player.Stats
 
-    return player, nil
+    return player.clone(), nil
 }
 
 func (r *MemoryPlayerRepository) GetByUsername(username string) (*Player, error) {
@@ -90,20 +144,32 @@ func (r *MemoryPlayerRepository) GetByUsername(username string) (*Player, error)
         return nil, fmt.Errorf("player not found")
     }
 
-    return player, nil
+    return player.clone(), nil
 }
 
 func (r *MemoryPlayerRepository) GetByID(ctx context.Context, id string) (*Player, error) {
     r.mu.RLock()
     defer r.mu.RUnlock()
 
-    for _, player := range r.players {
-        if player.ID == id {
-            return player, nil
-        }
+    player, exists := r.byID[id]
+    if !exists {
+        return nil, fmt.Errorf("player not found")
     }
 
-    return nil, fmt.Errorf("player not found")
+    return player.clone(), nil
+}
+
+// List คืนสำเนาของผู้เล่นทั้งหมด ใช้สำหรับงานที่ต้องไล่ทุกคน เช่นสร้าง leaderboard
+// เป็นสำเนาเพราะผู้เรียกอ่านนอก lock ขณะที่ MMR และ Stats ยังถูกอัพเดทอยู่
+func (r *MemoryPlayerRepository) List(ctx context.Context) ([]*Player, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    players := make([]*Player, 0, len(r.byID))
+    for _, player := range r.byID {
+        players = append(players, player.clone())
+    }
+    return players, nil
 }
 
 func (r *MemoryPlayerRepository) GetByEmail(ctx context.Context, email string) (*Player, error) {
@@ -112,7 +178,7 @@ func (r *MemoryPlayerRepository) GetByEmail(ctx context.Context, email string) (
 
     for _, player := range r.players {
         if player.Email == email {
-            return player, nil
+            return player.clone(), nil
         }
     }
 
@@ -128,23 +194,54 @@ func (r *MemoryPlayerRepository) Update(ctx context.Context, player *Player) err
         return fmt.Errorf("player not found")
     }
 
+    // เก็บสำเนา ผู้เรียกจึงแก้ player ต่อได้โดยไม่ชนกับ UpdateRating
+    // Stats ของตัวจริงต้องเป็นอันเดียวกับใน r.stats จึงเขียนทับค่าแทนการเปลี่ยน pointer
     player.UpdatedAt = time.Now()
-    r.players[player.Username] = player
-
+    stored := player.clone()
+    stored.Stats = r.stats[player.ID]
     if player.Stats != nil {
         player.Stats.UpdatedAt = time.Now()
-        r.stats[player.ID] = player.Stats
+        *stored.Stats = *player.Stats
     }
+    r.players[player.Username] = stored
+    r.byID[player.ID] = stored
 
     return nil
 }
 
+// UpdateStats เขียนทับ Stats ที่มีอยู่ Player.Stats จึงยังชี้ไปที่ค่าเดียวกัน
 func (r *MemoryPlayerRepository) UpdateStats(ctx context.Context, stats *Stats) error {
     r.mu.Lock()
     defer r.mu.Unlock()
 
     stats.UpdatedAt = time.Now()
-    r.stats[stats.PlayerID] = stats
+    if existing, exists := r.stats[stats.PlayerID]; exists {
+        *existing = *stats
+        return nil
+    }
+    copied := *stats
+    r.stats[stats.PlayerID] = &copied
+    return nil
+}
+
+// RecordPlacement นับผลของเกมหนึ่งเกมลง Stats ภายใต้ lock เกมที่จบพร้อมกันจึงไม่ทับกัน อันดับ 1 นับเป็นชนะ
+func (r *MemoryPlayerRepository) RecordPlacement(ctx context.Context, playerID string, placement int) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    stats, exists := r.stats[playerID]
+    if !exists {
+        return fmt.Errorf("stats not found")
+    }
+
+    if placement == 1 {
+        stats.Wins++
+    } else {
+        stats.Losses++
+    }
+    stats.Games++
+    stats.PlacementTotal += placement
+    stats.UpdatedAt = time.Now()
     return nil
 }
 
@@ -157,5 +254,6 @@ func (r *MemoryPlayerRepository) GetStats(ctx context.Context, playerID string)
         return nil, fmt.Errorf("stats not found")
     }
 
-    return stats, nil
+    copied := *stats
+    return &copied, nil
 }
\ No newline at end of file
@@ -0,0 +1,42 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "time"
+)
+
+// QueuePenalty คือประวัติการปฏิเสธแมตช์ของผู้เล่น ใช้คำนวณเวลาห้ามเข้าคิว
+type QueuePenalty struct {
+    PlayerID    string    `json:"player_id"`
+    Dodges      int       `json:"dodges"`
+    LastDodgeAt time.Time `json:"last_dodge_at"`
+    LockedUntil time.Time `json:"locked_until"`
+}
+
+type QueuePenaltyRepository interface {
+    GetQueuePenalty(ctx context.Context, playerID string) (*QueuePenalty, error)
+    UpdateQueuePenalty(ctx context.Context, penalty *QueuePenalty) error
+}
+
+func (r *MemoryPlayerRepository) GetQueuePenalty(ctx context.Context, playerID string) (*QueuePenalty, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    penalty, exists := r.queuePenalties[playerID]
+    if !exists {
+        return nil, fmt.Errorf("queue penalty not found")
+    }
+
+    copied := *penalty
+    return &copied, nil
+}
+
+func (r *MemoryPlayerRepository) UpdateQueuePenalty(ctx context.Context, penalty *QueuePenalty) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    copied := *penalty
+    r.queuePenalties[penalty.PlayerID] = &copied
+    return nil
+}
\ No newline at end of file
@@ -0,0 +1,95 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "time"
+)
+
+// Rank คือแรงค์ของผู้เล่นใน season หนึ่ง
+type Rank struct {
+    PlayerID  string    `json:"player_id"`
+    Season    int       `json:"season"`
+    Tier      string    `json:"tier"`
+    Division  int       `json:"division,omitempty"`
+    LP        int       `json:"lp"`
+    Games     int       `json:"games"`
+    Wins      int       `json:"wins"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SeasonResult คือผลปลาย season ที่เก็บไว้ดูย้อนหลัง
+type SeasonResult struct {
+    Season   int       `json:"season"`
+    Tier     string    `json:"tier"`
+    Division int       `json:"division,omitempty"`
+    LP       int       `json:"lp"`
+    Rating   float64   `json:"rating"`
+    Games    int       `json:"games"`
+    Wins     int       `json:"wins"`
+    EndedAt  time.Time `json:"ended_at"`
+}
+
+type RankRepository interface {
+    GetRank(ctx context.Context, playerID string) (*Rank, error)
+    UpdateRank(ctx context.Context, rank *Rank) error
+    ListRanks(ctx context.Context) ([]*Rank, error)
+    ArchiveSeason(ctx context.Context, playerID string, result SeasonResult) error
+    GetSeasonHistory(ctx context.Context, playerID string) ([]SeasonResult, error)
+}
+
+func (r *MemoryPlayerRepository) GetRank(ctx context.Context, playerID string) (*Rank, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    rank, exists := r.ranks[playerID]
+    if !exists {
+        return nil, fmt.Errorf("rank not found")
+    }
+
+    copied := *rank
+    return &copied, nil
+}
+
+func (r *MemoryPlayerRepository) UpdateRank(ctx context.Context, rank *Rank) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    rank.UpdatedAt = time.Now()
+    copied := *rank
+    r.ranks[rank.PlayerID] = &copied
+    return nil
+}
+
+func (r *MemoryPlayerRepository) ListRanks(ctx context.Context) ([]*Rank, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    ranks := make([]*Rank, 0, len(r.ranks))
+    for _, rank := range r.ranks {
+        copied := *rank
+        ranks = append(ranks, &copied)
+    }
+    return ranks, nil
+}
+
+func (r *MemoryPlayerRepository) ArchiveSeason(ctx context.Context, playerID string, result SeasonResult) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    r.seasonHistory[playerID] = append(r.seasonHistory[playerID], result)
+    return nil
+}
+
+// GetSeasonHistory คืนผลแต่ละ season เรียงจากล่าสุดก่อน
+func (r *MemoryPlayerRepository) GetSeasonHistory(ctx context.Context, playerID string) ([]SeasonResult, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    history := r.seasonHistory[playerID]
+    result := make([]SeasonResult, 0, len(history))
+    for i := len(history) - 1; i >= 0; i-- {
+        result = append(result, history[i])
+    }
+    return result, nil
+}
\ No newline at end of file
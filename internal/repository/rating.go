@@ -0,0 +1,94 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "time"
+)
+
+type Rating struct {
+    PlayerID   string    `json:"player_id"`
+    Rating     float64   `json:"rating"`
+    Deviation  float64   `json:"deviation"`
+    Volatility float64   `json:"volatility"`
+    GamesRated int       `json:"games_rated"`
+    UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// RatingChange คือประวัติการเปลี่ยน rating หนึ่งครั้งต่อหนึ่งเกม
+type RatingChange struct {
+    GameID    string    `json:"game_id"`
+    Placement int       `json:"placement"`
+    Before    float64   `json:"before"`
+    After     float64   `json:"after"`
+    Deviation float64   `json:"deviation"`
+    Reason    string    `json:"reason,omitempty"` // ว่างคือมาจากเกม เช่น season_reset
+    CreatedAt time.Time `json:"created_at"`
+}
+
+type RatingRepository interface {
+    GetRating(ctx context.Context, playerID string) (*Rating, error)
+    UpdateRating(ctx context.Context, rating *Rating, change RatingChange) error
+    GetRatingHistory(ctx context.Context, playerID string) ([]RatingChange, error)
+    ListRatings(ctx context.Context) ([]*Rating, error)
+}
+
+func (r *MemoryPlayerRepository) GetRating(ctx context.Context, playerID string) (*Rating, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    rating, exists := r.ratings[playerID]
+    if !exists {
+        return nil, fmt.Errorf("rating not found")
+    }
+
+    copied := *rating
+    return &copied, nil
+}
+
+// UpdateRating บันทึก rating ใหม่ เพิ่มประวัติ และอัพเดท MMR ของผู้เล่นให้ตรงกัน
+func (r *MemoryPlayerRepository) UpdateRating(ctx context.Context, rating *Rating, change RatingChange) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    now := time.Now()
+    rating.UpdatedAt = now
+    if change.CreatedAt.IsZero() {
+        change.CreatedAt = now
+    }
+
+    copied := *rating
+    r.ratings[rating.PlayerID] = &copied
+    r.ratingHistory[rating.PlayerID] = append(r.ratingHistory[rating.PlayerID], change)
+
+    if player, ok := r.byID[rating.PlayerID]; ok {
+        player.MMR = int(rating.Rating + 0.5)
+    }
+
+    return nil
+}
+
+func (r *MemoryPlayerRepository) ListRatings(ctx context.Context) ([]*Rating, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    ratings := make([]*Rating, 0, len(r.ratings))
+    for _, rating := range r.ratings {
+        copied := *rating
+        ratings = append(ratings, &copied)
+    }
+    return ratings, nil
+}
+
+// GetRatingHistory คืนประวัติเรียงจากเกมล่าสุดก่อน
+func (r *MemoryPlayerRepository) GetRatingHistory(ctx context.Context, playerID string) ([]RatingChange, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    history := r.ratingHistory[playerID]
+    result := make([]RatingChange, 0, len(history))
+    for i := len(history) - 1; i >= 0; i-- {
+        result = append(result, history[i])
+    }
+    return result, nil
+}
\ No newline at end of file
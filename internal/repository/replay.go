@@ -0,0 +1,31 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+)
+
+// ReplayRepository เก็บไฟล์ replay ที่บีบอัดแล้วของแต่ละเกม
+type ReplayRepository interface {
+    SaveReplay(ctx context.Context, gameID string, data []byte) error
+    GetReplay(ctx context.Context, gameID string) ([]byte, error)
+}
+
+func (r *MemoryPlayerRepository) SaveReplay(ctx context.Context, gameID string, data []byte) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    r.replays[gameID] = append([]byte(nil), data...)
+    return nil
+}
+
+func (r *MemoryPlayerRepository) GetReplay(ctx context.Context, gameID string) ([]byte, error) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    data, exists := r.replays[gameID]
+    if !exists {
+        return nil, fmt.Errorf("replay not found")
+    }
+    return data, nil
+}
\ No newline at end of file
@@ -3,6 +3,7 @@ package service
 import (
     "context"  // เพิ่ม import
     "fmt"
+    "sync"
     "time"
     "github.com/golang-jwt/jwt/v5"
     "github.com/tem-mars/tft-game-server/internal/repository"
@@ -20,17 +21,37 @@ type PlayerRepository interface {
 }
 
 type AuthService struct {
+    mu         sync.RWMutex
     playerRepo PlayerRepository
     jwtSecret  string
+    admins     map[string]bool // key: playerID ของบัญชีที่ได้ role admin
 }
 
 func NewAuthService(playerRepo PlayerRepository, jwtSecret string) *AuthService {
     return &AuthService{
         playerRepo: playerRepo,
         jwtSecret: jwtSecret,
+        admins:    make(map[string]bool),
     }
 }
 
+// SeedAdmin สร้างบัญชีผู้ดูแลถ้ายังไม่มี แล้วให้ token ของบัญชีนี้มี role admin
+// เรียกตอนเริ่มระบบก่อนเปิดรับสมัคร คนอื่นจึงจองชื่อนี้ไปก่อนไม่ได้
+func (s *AuthService) SeedAdmin(username, email, password string) error {
+    player, err := s.playerRepo.GetByUsername(username)
+    if err != nil {
+        player, err = s.playerRepo.Create(context.Background(), username, email, password)
+        if err != nil {
+            return err
+        }
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.admins[player.ID] = true
+    return nil
+}
+
 func (s *AuthService) Register(username, password, email string) error {
     _, err := s.playerRepo.Create(context.Background(), username, email, password)
     return err
@@ -46,8 +67,16 @@ func (s *AuthService) Login(username, password string) (string, error) {
         return "", fmt.Errorf("invalid credentials")
     }
 
+    s.mu.RLock()
+    role := ""
+    if s.admins[player.ID] {
+        role = middleware.RoleAdmin
+    }
+    s.mu.RUnlock()
+
     claims := &middleware.Claims{
         PlayerID: player.ID,
+        Role:     role,
         RegisteredClaims: jwt.RegisteredClaims{
             Subject:   player.ID,
             IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -0,0 +1,56 @@
+package service
+
+import (
+    "testing"
+
+    "github.com/golang-jwt/jwt/v5"
+    "github.com/tem-mars/tft-game-server/internal/middleware"
+    "github.com/tem-mars/tft-game-server/internal/repository"
+)
+
+func TestAuthService(t *testing.T) {
+    auth := NewAuthService(repository.NewMemoryPlayerRepository(), "test-secret")
+
+    // login แล้วอ่าน claims ออกจาก token
+    login := func(t *testing.T, username, password string) *middleware.Claims {
+        t.Helper()
+        token, err := auth.Login(username, password)
+        if err != nil {
+            t.Fatalf("failed to login: %v", err)
+        }
+        claims := &middleware.Claims{}
+        if _, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+            return []byte("test-secret"), nil
+        }); err != nil {
+            t.Fatalf("failed to parse token: %v", err)
+        }
+        return claims
+    }
+
+    t.Run("Seeded Admin Gets Admin Role", func(t *testing.T) {
+        if err := auth.SeedAdmin("root", "root@example.com", "secret"); err != nil {
+            t.Fatalf("failed to seed admin: %v", err)
+        }
+        if claims := login(t, "root", "secret"); !claims.IsAdmin() {
+            t.Errorf("expected admin role, got %q", claims.Role)
+        }
+    })
+
+    t.Run("Players Are Not Admins", func(t *testing.T) {
+        if err := auth.Register("alice", "secret", "alice@example.com"); err != nil {
+            t.Fatalf("failed to register: %v", err)
+        }
+        if claims := login(t, "alice", "secret"); claims.IsAdmin() {
+            t.Errorf("expected no role, got %q", claims.Role)
+        }
+    })
+
+    t.Run("Seeding Keeps Existing Account", func(t *testing.T) {
+        if err := auth.SeedAdmin("alice", "other@example.com", "other"); err != nil {
+            t.Fatalf("failed to seed admin: %v", err)
+        }
+        if claims := login(t, "alice", "secret"); !claims.IsAdmin() {
+            t.Errorf("expected alice to be promoted with the original password, got %q", claims.Role)
+        }
+    })
+}
\ No newline at end of file
@@ -0,0 +1,238 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/internal/repository"
+)
+
+var (
+    ErrUnknownBoard = errors.New("unknown leaderboard")
+    ErrNotOnBoard   = errors.New("player is not on this leaderboard")
+)
+
+const (
+    BoardRating    = "rating"
+    BoardWins      = "wins"
+    BoardPlacement = "placement"
+
+    DefaultLeaderboardRefresh = 30 * time.Second
+)
+
+type LeaderboardRepository interface {
+    List(ctx context.Context) ([]*repository.Player, error)
+    ListRatings(ctx context.Context) ([]*repository.Rating, error)
+}
+
+type LeaderboardEntry struct {
+    Position         int     `json:"position"`
+    PlayerID         string  `json:"player_id"`
+    Username         string  `json:"username"`
+    Rating           float64 `json:"rating"`
+    Wins             int     `json:"wins"`
+    Games            int     `json:"games"`
+    AveragePlacement float64 `json:"average_placement"`
+}
+
+// leaderboard คือผลที่เรียงไว้แล้ว พร้อม index สำหรับหาตำแหน่งของผู้เล่น
+type leaderboard struct {
+    entries  []LeaderboardEntry
+    position map[string]int // key: playerID, value: index ใน entries
+}
+
+// LeaderboardService สร้างอันดับจาก Stats และ rating เป็นระยะ
+// คำขอจึงอ่านจากผลที่เรียงไว้แล้วโดยไม่ต้องไล่ผู้เล่นทุกคน
+type LeaderboardService struct {
+    mu       sync.RWMutex
+    repo     LeaderboardRepository
+    interval time.Duration
+    boards   map[string]*leaderboard
+    dirty    bool
+}
+
+func NewLeaderboardService(repo LeaderboardRepository, interval time.Duration) *LeaderboardService {
+    if interval <= 0 {
+        interval = DefaultLeaderboardRefresh
+    }
+    s := &LeaderboardService{
+        repo:     repo,
+        interval: interval,
+    }
+    s.Refresh()
+    return s
+}
+
+// MarkDirty ใช้เป็น callback ของ GameManager.AddOnGameFinished ให้รอบถัดไปสร้างอันดับใหม่
+func (s *LeaderboardService) MarkDirty(*game.Game) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.dirty = true
+}
+
+// Run สร้างอันดับใหม่ทุก interval ถ้ามีเกมจบตั้งแต่รอบที่แล้ว
+func (s *LeaderboardService) Run(ctx context.Context) {
+    ticker := time.NewTicker(s.interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            s.mu.RLock()
+            dirty := s.dirty
+            s.mu.RUnlock()
+            if dirty {
+                s.Refresh()
+            }
+        }
+    }
+}
+
+// Refresh อ่านผู้เล่นทั้งหมดจาก repository แล้วเรียงอันดับทุกกระดานใหม่
+func (s *LeaderboardService) Refresh() {
+    // ล้าง dirty ก่อนอ่าน MarkDirty ที่มาระหว่างอ่านจะได้ไม่หาย
+    s.mu.Lock()
+    s.dirty = false
+    s.mu.Unlock()
+
+    ctx := context.Background()
+
+    ratings := make(map[string]*repository.Rating)
+    if list, err := s.repo.ListRatings(ctx); err == nil {
+        for _, r := range list {
+            ratings[r.PlayerID] = r
+        }
+    }
+
+    players, _ := s.repo.List(ctx)
+    var rated, played []LeaderboardEntry
+    for _, p := range players {
+        entry := LeaderboardEntry{
+            PlayerID: p.ID,
+            Username: p.Username,
+            Rating:   float64(p.MMR),
+        }
+        if p.Stats != nil {
+            entry.Wins = p.Stats.Wins
+            entry.Games = p.Stats.Games
+            entry.AveragePlacement = p.Stats.AveragePlacement()
+        }
+
+        if r, ok := ratings[p.ID]; ok && r.GamesRated > 0 {
+            entry.Rating = r.Rating
+            rated = append(rated, entry)
+        }
+        if entry.Games > 0 {
+            played = append(played, entry)
+        }
+    }
+
+    boards := map[string]*leaderboard{
+        BoardRating: newLeaderboard(rated, func(a, b LeaderboardEntry) bool {
+            return a.Rating > b.Rating
+        }),
+        BoardWins: newLeaderboard(played, func(a, b LeaderboardEntry) bool {
+            if a.Wins != b.Wins {
+                return a.Wins > b.Wins
+            }
+            return a.Games < b.Games
+        }),
+        BoardPlacement: newLeaderboard(played, func(a, b LeaderboardEntry) bool {
+            if a.AveragePlacement != b.AveragePlacement {
+                return a.AveragePlacement < b.AveragePlacement
+            }
+            return a.Games > b.Games
+        }),
+    }
+
+    s.mu.Lock()
+    s.boards = boards
+    s.mu.Unlock()
+}
+
+// Page คืนอันดับตั้งแต่ offset จำนวนไม่เกิน limit พร้อมจำนวนผู้เล่นทั้งหมดในกระดาน
+func (s *LeaderboardService) Page(board string, offset, limit int) ([]LeaderboardEntry, int, error) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    b, ok := s.boards[board]
+    if !ok {
+        return nil, 0, ErrUnknownBoard
+    }
+
+    total := len(b.entries)
+    if offset < 0 {
+        offset = 0
+    }
+    if offset > total {
+        offset = total
+    }
+    end := offset + limit
+    if limit <= 0 || end > total {
+        end = total
+    }
+    return append([]LeaderboardEntry(nil), b.entries[offset:end]...), total, nil
+}
+
+func (s *LeaderboardService) Position(board, playerID string) (LeaderboardEntry, error) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    b, ok := s.boards[board]
+    if !ok {
+        return LeaderboardEntry{}, ErrUnknownBoard
+    }
+    i, ok := b.position[playerID]
+    if !ok {
+        return LeaderboardEntry{}, ErrNotOnBoard
+    }
+    return b.entries[i], nil
+}
+
+// Among คืนอันดับเฉพาะผู้เล่นที่ระบุ เช่นรายชื่อเพื่อน โดยคงตำแหน่งจากกระดานรวมไว้
+func (s *LeaderboardService) Among(board string, playerIDs []string) ([]LeaderboardEntry, error) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    b, ok := s.boards[board]
+    if !ok {
+        return nil, ErrUnknownBoard
+    }
+
+    entries := make([]LeaderboardEntry, 0, len(playerIDs))
+    for _, playerID := range playerIDs {
+        if i, ok := b.position[playerID]; ok {
+            entries = append(entries, b.entries[i])
+        }
+    }
+    sort.Slice(entries, func(i, j int) bool {
+        return entries[i].Position < entries[j].Position
+    })
+    return entries, nil
+}
+
+func newLeaderboard(entries []LeaderboardEntry, less func(a, b LeaderboardEntry) bool) *leaderboard {
+    sorted := append([]LeaderboardEntry(nil), entries...)
+    sort.SliceStable(sorted, func(i, j int) bool {
+        if less(sorted[i], sorted[j]) {
+            return true
+        }
+        if less(sorted[j], sorted[i]) {
+            return false
+        }
+        return sorted[i].PlayerID < sorted[j].PlayerID
+    })
+
+    position := make(map[string]int, len(sorted))
+    for i := range sorted {
+        sorted[i].Position = i + 1
+        position[sorted[i].PlayerID] = i
+    }
+    return &leaderboard{entries: sorted, position: position}
+}
\ No newline at end of file
@@ -0,0 +1,151 @@
+package service
+
+import (
+    "context"
+    "sync"
+    "testing"
+
+    "github.com/tem-mars/tft-game-server/internal/repository"
+)
+
+// listHookRepository เรียก onList ระหว่างที่ Refresh กำลังอ่านผู้เล่น
+type listHookRepository struct {
+    LeaderboardRepository
+    onList func()
+}
+
+func (r *listHookRepository) List(ctx context.Context) ([]*repository.Player, error) {
+    r.onList()
+    return r.LeaderboardRepository.List(ctx)
+}
+
+func TestLeaderboardService(t *testing.T) {
+    ctx := context.Background()
+    repo := repository.NewMemoryPlayerRepository()
+
+    // username, wins, games, ผลรวมอันดับ, rating
+    seed := []struct {
+        username       string
+        wins, games    int
+        placementTotal int
+        rating         float64
+    }{
+        {"alice", 3, 4, 6, 1300},
+        {"bob", 1, 4, 14, 1100},
+        {"carol", 3, 6, 12, 1200},
+        {"dave", 0, 0, 0, 0},
+    }
+
+    ids := make(map[string]string)
+    for _, p := range seed {
+        player, _ := repo.Create(ctx, p.username, p.username+"@example.com", "secret")
+        ids[p.username] = player.ID
+
+        stats, _ := repo.GetStats(ctx, player.ID)
+        stats.Wins = p.wins
+        stats.Games = p.games
+        stats.PlacementTotal = p.placementTotal
+        repo.UpdateStats(ctx, stats)
+
+        if p.rating > 0 {
+            repo.UpdateRating(ctx, &repository.Rating{PlayerID: player.ID, Rating: p.rating, GamesRated: p.games}, repository.RatingChange{})
+        }
+    }
+
+    boards := NewLeaderboardService(repo, 0)
+
+    t.Run("Boards Are Sorted", func(t *testing.T) {
+        expected := map[string][]string{
+            BoardRating:    {"alice", "carol", "bob"},
+            BoardWins:      {"alice", "carol", "bob"}, // เสมอกันที่ 3 ชนะ คนที่เล่นน้อยกว่าอยู่ก่อน
+            BoardPlacement: {"alice", "carol", "bob"},
+        }
+        for board, usernames := range expected {
+            entries, total, err := boards.Page(board, 0, 10)
+            if err != nil {
+                t.Fatalf("%s: %v", board, err)
+            }
+            if total != len(usernames) {
+                t.Errorf("%s: expected %d entries without dave, got %d", board, len(usernames), total)
+            }
+            for i, username := range usernames {
+                if entries[i].Username != username || entries[i].Position != i+1 {
+                    t.Errorf("%s: expected %s at %d, got %+v", board, username, i+1, entries[i])
+                }
+            }
+        }
+    })
+
+    t.Run("Pagination And Position", func(t *testing.T) {
+        entries, total, _ := boards.Page(BoardRating, 1, 1)
+        if total != 3 || len(entries) != 1 || entries[0].Username != "carol" {
+            t.Errorf("expected carol on page 2, got %+v (total %d)", entries, total)
+        }
+
+        me, err := boards.Position(BoardPlacement, ids["bob"])
+        if err != nil || me.Position != 3 || me.AveragePlacement != 3.5 {
+            t.Errorf("expected bob 3rd with 3.5 average, got %+v (%v)", me, err)
+        }
+        if _, err := boards.Position(BoardWins, ids["dave"]); err != ErrNotOnBoard {
+            t.Errorf("expected %v, got %v", ErrNotOnBoard, err)
+        }
+        if _, _, err := boards.Page("kills", 0, 10); err != ErrUnknownBoard {
+            t.Errorf("expected %v, got %v", ErrUnknownBoard, err)
+        }
+    })
+
+    t.Run("Among Keeps Global Positions", func(t *testing.T) {
+        entries, _ := boards.Among(BoardRating, []string{ids["bob"], ids["alice"], ids["dave"]})
+        if len(entries) != 2 || entries[0].Position != 1 || entries[1].Position != 3 {
+            t.Errorf("expected alice (1) and bob (3), got %+v", entries)
+        }
+    })
+
+    t.Run("Refresh Picks Up New Results", func(t *testing.T) {
+        stats, _ := repo.GetStats(ctx, ids["bob"])
+        stats.Wins = 10
+        repo.UpdateStats(ctx, stats)
+
+        if top, _, _ := boards.Page(BoardWins, 0, 1); top[0].Username != "alice" {
+            t.Errorf("expected cached board until refresh, got %+v", top)
+        }
+        boards.MarkDirty(nil)
+        boards.Refresh()
+        if top, _, _ := boards.Page(BoardWins, 0, 1); top[0].Username != "bob" {
+            t.Errorf("expected bob on top after refresh, got %+v", top)
+        }
+    })
+
+    t.Run("Refresh While Results Are Recorded", func(t *testing.T) {
+        var wg sync.WaitGroup
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for i := 0; i < 50; i++ {
+                repo.RecordPlacement(ctx, ids["dave"], 1)
+                repo.UpdateRating(ctx, &repository.Rating{PlayerID: ids["dave"], Rating: 900 + float64(i), GamesRated: i + 1}, repository.RatingChange{})
+            }
+        }()
+        for i := 0; i < 50; i++ {
+            boards.Refresh()
+        }
+        wg.Wait()
+
+        boards.Refresh()
+        if _, err := boards.Position(BoardWins, ids["dave"]); err != nil {
+            t.Errorf("expected dave on the wins board, got %v", err)
+        }
+    })
+
+    t.Run("Results During Refresh Are Not Lost", func(t *testing.T) {
+        hooked := &listHookRepository{LeaderboardRepository: repo, onList: func() {}}
+        lagging := NewLeaderboardService(hooked, 0)
+
+        // เกมจบระหว่างที่ Refresh อ่าน repository อยู่ รอบถัดไปต้องสร้างใหม่อีกครั้ง
+        hooked.onList = func() { lagging.MarkDirty(nil) }
+        lagging.Refresh()
+        if !lagging.dirty {
+            t.Error("expected board to stay dirty after a result during refresh")
+        }
+    })
+}
\ No newline at end of file
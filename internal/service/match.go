@@ -0,0 +1,98 @@
+package service
+
+import (
+    "context"
+    "sort"
+    "sync"
+
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/internal/repository"
+)
+
+type MatchRepository interface {
+    SaveMatch(ctx context.Context, match *repository.MatchRecord) error
+    GetMatch(ctx context.Context, matchID string) (*repository.MatchRecord, error)
+    ListPlayerMatches(ctx context.Context, playerID string, offset, limit int) ([]*repository.MatchRecord, int, error)
+}
+
+// MatchHistoryService บันทึกผลเกมที่จบแล้วและแจ้งสรุปหลังเกม
+type MatchHistoryService struct {
+    mu         sync.Mutex
+    repo       MatchRepository
+    onRecorded func(*repository.MatchRecord)
+}
+
+func NewMatchHistoryService(repo MatchRepository) *MatchHistoryService {
+    return &MatchHistoryService{
+        repo:       repo,
+        onRecorded: func(*repository.MatchRecord) {},
+    }
+}
+
+// SetOnRecorded ตั้ง callback ที่จะถูกเรียกหลังบันทึกแต่ละเกม ใช้ส่งสรุปหลังเกมให้ผู้เล่น
+func (s *MatchHistoryService) SetOnRecorded(callback func(*repository.MatchRecord)) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.onRecorded = callback
+}
+
+// RecordGame ใช้เป็น callback ของ GameManager.AddOnGameFinished
+func (s *MatchHistoryService) RecordGame(g *game.Game) {
+    record := newMatchRecord(g)
+    if err := s.repo.SaveMatch(context.Background(), record); err != nil {
+        return
+    }
+
+    s.mu.Lock()
+    onRecorded := s.onRecorded
+    s.mu.Unlock()
+    onRecorded(record)
+}
+
+func (s *MatchHistoryService) GetMatch(matchID string) (*repository.MatchRecord, error) {
+    return s.repo.GetMatch(context.Background(), matchID)
+}
+
+func (s *MatchHistoryService) ListPlayerMatches(playerID string, offset, limit int) ([]*repository.MatchRecord, int, error) {
+    return s.repo.ListPlayerMatches(context.Background(), playerID, offset, limit)
+}
+
+func newMatchRecord(g *game.Game) *repository.MatchRecord {
+    record := &repository.MatchRecord{
+        ID:              g.ID,
+        Mode:            string(g.Mode),
+        Ranked:          g.Ranked,
+        Rounds:          g.Round,
+        StartedAt:       g.StartedAt,
+        EndedAt:         g.FinishedAt,
+        DurationSeconds: int(g.Duration().Seconds()),
+    }
+
+    for _, p := range g.Players {
+        items := make([]string, 0, len(p.Inventory))
+        for _, item := range p.Inventory {
+            items = append(items, item.ID)
+        }
+
+        record.Participants = append(record.Participants, repository.MatchParticipant{
+            PlayerID:    p.ID,
+            Username:    p.Username,
+            IsBot:       p.IsBot,
+            Placement:   p.Placement,
+            LastRound:   p.LastRound,
+            Health:      p.Health,
+            Level:       p.Level,
+            Attack:      p.Attack,
+            Defense:     p.Defense,
+            Gold:        p.Gold,
+            Items:       items,
+            GoldSpent:   p.GoldSpent,
+            DamageDealt: p.DamageDealt,
+        })
+    }
+    sort.SliceStable(record.Participants, func(i, j int) bool {
+        return record.Participants[i].Placement < record.Participants[j].Placement
+    })
+
+    return record
+}
\ No newline at end of file
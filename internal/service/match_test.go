@@ -0,0 +1,80 @@
+package service
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/internal/repository"
+)
+
+func TestMatchHistoryService(t *testing.T) {
+    repo := repository.NewMemoryPlayerRepository()
+    alice, _ := repo.Create(context.Background(), "alice", "alice@example.com", "secret")
+    bob, _ := repo.Create(context.Background(), "bob", "bob@example.com", "secret")
+
+    gm := game.NewGameManager(repo)
+    history := NewMatchHistoryService(repo)
+    gm.AddOnGameFinished(history.RecordGame)
+
+    var summaries []*repository.MatchRecord
+    history.SetOnRecorded(func(record *repository.MatchRecord) {
+        summaries = append(summaries, record)
+    })
+
+    played, err := gm.CreateMatch([]string{alice.ID, bob.ID})
+    if err != nil {
+        t.Fatalf("failed to create match: %v", err)
+    }
+    if err := gm.BuyItem(played.ID, alice.ID, "sword"); err != nil {
+        t.Fatalf("failed to buy item: %v", err)
+    }
+    if err := gm.ProcessAction(played.ID, game.GameAction{Type: game.ActionAttack, PlayerID: alice.ID, TargetID: bob.ID, Timestamp: time.Now()}); err != nil {
+        t.Fatalf("failed to attack: %v", err)
+    }
+    if err := gm.Surrender(played.ID, bob.ID); err != nil {
+        t.Fatalf("failed to surrender: %v", err)
+    }
+
+    t.Run("Game Is Recorded", func(t *testing.T) {
+        record, err := history.GetMatch(played.ID)
+        if err != nil {
+            t.Fatalf("expected match to be recorded: %v", err)
+        }
+        if !record.Ranked || len(record.Participants) != 2 || record.EndedAt.IsZero() {
+            t.Fatalf("unexpected record: %+v", record)
+        }
+
+        winner := record.Participants[0]
+        if winner.PlayerID != alice.ID || winner.Placement != 1 {
+            t.Errorf("expected alice first, got %+v", winner)
+        }
+        if len(winner.Items) != 1 || winner.Items[0] != "sword" || winner.GoldSpent == 0 {
+            t.Errorf("expected sword purchase to be recorded, got %+v", winner)
+        }
+        if winner.DamageDealt == 0 {
+            t.Errorf("expected damage dealt to be recorded, got %+v", winner)
+        }
+    })
+
+    t.Run("Summary Is Pushed", func(t *testing.T) {
+        if len(summaries) != 1 || summaries[0].ID != played.ID {
+            t.Errorf("expected one summary for %s, got %+v", played.ID, summaries)
+        }
+    })
+
+    t.Run("Player History Is Paginated", func(t *testing.T) {
+        rematch, _ := gm.CreateMatch([]string{alice.ID, bob.ID})
+        gm.Surrender(rematch.ID, alice.ID)
+
+        matches, total, _ := history.ListPlayerMatches(bob.ID, 0, 1)
+        if total != 2 || len(matches) != 1 || matches[0].ID != rematch.ID {
+            t.Errorf("expected newest match first out of 2, got %d total and %+v", total, matches)
+        }
+        matches, _, _ = history.ListPlayerMatches(bob.ID, 1, 1)
+        if len(matches) != 1 || matches[0].ID != played.ID {
+            t.Errorf("expected first match on page 2, got %+v", matches)
+        }
+    })
+}
\ No newline at end of file
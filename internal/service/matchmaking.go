@@ -0,0 +1,695 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sort"
+    "sync"
+    "time"
+
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/internal/repository"
+)
+
+var (
+    ErrAlreadyQueued  = errors.New("player is already in queue")
+    ErrNotQueued      = errors.New("player is not in queue")
+    ErrNoPendingMatch = errors.New("player has no match to accept")
+    ErrQueueLocked    = errors.New("player is locked out of the queue")
+    ErrGroupTooLarge  = errors.New("group is larger than the lobby")
+    ErrMatchPending   = errors.New("player has a match waiting to be accepted")
+)
+
+const (
+    QueueStateQueued     = "queued"
+    QueueStateMatched    = "matched"
+    QueueStateCancelled  = "cancelled"
+    QueueStateMatchFound = "match_found"
+    QueueStateRequeued   = "requeued" // มีคนปฏิเสธแมตช์ กลับเข้าคิวโดยคงลำดับเดิม
+    QueueStateDeclined   = "declined"
+
+    // จำนวนเวลารอล่าสุดที่ใช้คำนวณเวลารอโดยประมาณ
+    waitSampleSize = 20
+)
+
+type MatchmakingConfig struct {
+    LobbySize            int
+    BaseWindow           int           // ช่วง MMR เริ่มต้นที่ยอมจับคู่
+    WindowGrowth         int           // ช่วงที่เพิ่มขึ้นทุก WindowGrowthInterval
+    WindowGrowthInterval time.Duration
+    MaxWindow            int
+    TickInterval         time.Duration
+
+    AcceptTimeout time.Duration   // เวลาที่ให้กดยอมรับแมตช์
+    DodgeLockouts []time.Duration // เวลาห้ามเข้าคิวตามจำนวนครั้งที่ปฏิเสธ ครั้งที่เกินใช้ค่าสุดท้าย
+    DodgeDecay    time.Duration   // ไม่ปฏิเสธนานเท่านี้ จำนวนครั้งจะนับใหม่
+}
+
+func DefaultMatchmakingConfig() MatchmakingConfig {
+    return MatchmakingConfig{
+        LobbySize:            8,
+        BaseWindow:           100,
+        WindowGrowth:         50,
+        WindowGrowthInterval: 10 * time.Second,
+        MaxWindow:            1000,
+        TickInterval:         time.Second,
+
+        AcceptTimeout: 15 * time.Second,
+        DodgeLockouts: []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute, 30 * time.Minute},
+        DodgeDecay:    24 * time.Hour,
+    }
+}
+
+// QueueStatus คือข้อมูลที่ส่งให้ผู้เล่นระหว่างรอคิว
+type QueueStatus struct {
+    PlayerID             string `json:"player_id"`
+    State                string `json:"state"`
+    MMR                  int    `json:"mmr"`
+    Window               int    `json:"mmr_window"`
+    QueueSize            int    `json:"queue_size"`
+    WaitedSeconds        int    `json:"waited_seconds"`
+    EstimatedWaitSeconds int    `json:"estimated_wait_seconds"`
+    GameID               string `json:"game_id,omitempty"`
+
+    MatchID       string `json:"match_id,omitempty"`
+    AcceptSeconds int    `json:"accept_seconds,omitempty"`
+    Accepted      int    `json:"accepted,omitempty"`
+    Required      int    `json:"required,omitempty"`
+    LockedSeconds int    `json:"locked_seconds,omitempty"`
+    GroupSize     int    `json:"group_size,omitempty"`
+}
+
+// QueueStats คือภาพรวมของคิวที่ผู้เล่นทุกคนเห็นเหมือนกัน
+type QueueStats struct {
+    QueueSize          int `json:"queue_size"`
+    PendingMatches     int `json:"pending_matches"`
+    AverageWaitSeconds int `json:"average_wait_seconds"`
+}
+
+// MatchCreator คือสิ่งที่สร้างเกมจากกลุ่มผู้เล่นที่จับคู่ได้ ปกติคือ game.GameManager
+type MatchCreator interface {
+    CreateMatch(playerIDs []string) (*game.Game, error)
+}
+
+// QueuePenaltyRepository เก็บประวัติการปฏิเสธแมตช์ของผู้เล่น
+type QueuePenaltyRepository interface {
+    GetQueuePenalty(ctx context.Context, playerID string) (*repository.QueuePenalty, error)
+    UpdateQueuePenalty(ctx context.Context, penalty *repository.QueuePenalty) error
+}
+
+// queueEntry คือหน่วยที่เข้าคิว ผู้เล่นเดี่ยวหรือปาร์ตี้ทั้งกลุ่ม ซึ่งจะไม่ถูกแยกกันข้ามล็อบบี้
+type queueEntry struct {
+    members  []string
+    mmr      int
+    joinedAt time.Time
+}
+
+func (e *queueEntry) size() int {
+    return len(e.members)
+}
+
+func (e *queueEntry) has(playerID string) bool {
+    for _, member := range e.members {
+        if member == playerID {
+            return true
+        }
+    }
+    return false
+}
+
+// pendingMatch คือกลุ่มที่จับคู่ได้แล้วแต่ยังรอทุกคนกดยอมรับ
+type pendingMatch struct {
+    id        string
+    entries   []*queueEntry
+    accepted  map[string]bool // key: playerID
+    expiresAt time.Time
+}
+
+func (m *pendingMatch) players() int {
+    total := 0
+    for _, entry := range m.entries {
+        total += entry.size()
+    }
+    return total
+}
+
+type MatchmakingService struct {
+    mu         sync.Mutex
+    cfg        MatchmakingConfig
+    playerRepo PlayerRepository
+    penalties  QueuePenaltyRepository
+    matches    MatchCreator
+    queue      []*queueEntry            // เรียงตามเวลาที่เข้าคิว คนที่รอนานได้สิทธิ์ก่อน
+    pending    map[string]*pendingMatch // key: playerID ของสมาชิกทุกคน
+    matchSeq   int
+    waits      []time.Duration
+    onStatus   func(QueueStatus)
+}
+
+func NewMatchmakingService(cfg MatchmakingConfig, playerRepo PlayerRepository, penalties QueuePenaltyRepository, matches MatchCreator) *MatchmakingService {
+    return &MatchmakingService{
+        cfg:        cfg,
+        playerRepo: playerRepo,
+        penalties:  penalties,
+        matches:    matches,
+        pending:    make(map[string]*pendingMatch),
+        onStatus:   func(QueueStatus) {},
+    }
+}
+
+// SetOnStatus ตั้ง callback ที่จะถูกเรียกทุกครั้งที่สถานะคิวของผู้เล่นเปลี่ยน
+func (s *MatchmakingService) SetOnStatus(callback func(QueueStatus)) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.onStatus = callback
+}
+
+func (s *MatchmakingService) Enqueue(playerID string) (QueueStatus, error) {
+    return s.EnqueueGroup([]string{playerID})
+}
+
+// EnqueueGroup ให้ผู้เล่นทั้งกลุ่มเข้าคิวเป็นหน่วยเดียว สถานะที่คืนเป็นของคนแรกในกลุ่ม
+func (s *MatchmakingService) EnqueueGroup(playerIDs []string) (QueueStatus, error) {
+    if len(playerIDs) > s.cfg.LobbySize {
+        return QueueStatus{}, ErrGroupTooLarge
+    }
+
+    // MMR ของกลุ่มคือค่าเฉลี่ยของสมาชิก
+    total := 0
+    for _, playerID := range playerIDs {
+        player, err := s.playerRepo.GetByID(context.Background(), playerID)
+        if err != nil {
+            return QueueStatus{}, err
+        }
+        total += player.MMR
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    now := time.Now()
+    for _, playerID := range playerIDs {
+        if s.find(playerID) >= 0 || s.pending[playerID] != nil {
+            return QueueStatus{}, ErrAlreadyQueued
+        }
+        if err := s.lockoutError(playerID, now); err != nil {
+            return QueueStatus{}, err
+        }
+    }
+
+    entry := &queueEntry{
+        members:  append([]string(nil), playerIDs...),
+        mmr:      total / len(playerIDs),
+        joinedAt: now,
+    }
+    s.queue = append(s.queue, entry)
+
+    for _, member := range entry.members[1:] {
+        s.onStatus(s.status(entry, member, now))
+    }
+    return s.status(entry, playerIDs[0], now), nil
+}
+
+func (s *MatchmakingService) Cancel(playerID string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    // ออกจากคิวตอนที่มีแมตช์รอยอมรับอยู่ถือว่าปฏิเสธ
+    if match := s.pending[playerID]; match != nil {
+        s.dodge(match, []string{playerID}, time.Now())
+        return nil
+    }
+
+    i := s.find(playerID)
+    if i < 0 {
+        return ErrNotQueued
+    }
+    s.cancelEntry(i)
+    return nil
+}
+
+// Withdraw เอาทั้งกลุ่มที่มีผู้เล่นเหล่านี้ออกจากคิวโดยไม่ลงโทษ ใช้ตอนสมาชิกปาร์ตี้เปลี่ยน
+// ถ้ามีแมตช์รอยอมรับอยู่จะไม่ยอมให้เปลี่ยน
+func (s *MatchmakingService) Withdraw(playerIDs ...string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    for _, playerID := range playerIDs {
+        if s.pending[playerID] != nil {
+            return ErrMatchPending
+        }
+    }
+    for _, playerID := range playerIDs {
+        if i := s.find(playerID); i >= 0 {
+            s.cancelEntry(i)
+        }
+    }
+    return nil
+}
+
+func (s *MatchmakingService) cancelEntry(i int) {
+    entry := s.queue[i]
+    s.queue = append(s.queue[:i], s.queue[i+1:]...)
+
+    for _, member := range entry.members {
+        s.onStatus(QueueStatus{PlayerID: member, State: QueueStateCancelled})
+    }
+}
+
+func (s *MatchmakingService) Status(playerID string) (QueueStatus, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    now := time.Now()
+    if match := s.pending[playerID]; match != nil {
+        for _, entry := range match.entries {
+            if entry.has(playerID) {
+                return s.matchFoundStatus(match, entry, playerID, now), nil
+            }
+        }
+    }
+
+    i := s.find(playerID)
+    if i < 0 {
+        return QueueStatus{}, ErrNotQueued
+    }
+    return s.status(s.queue[i], playerID, now), nil
+}
+
+// Stats คืนภาพรวมของคิว ณ ตอนนี้
+func (s *MatchmakingService) Stats() QueueStats {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    matches := make(map[*pendingMatch]struct{})
+    for _, match := range s.pending {
+        matches[match] = struct{}{}
+    }
+    return QueueStats{
+        QueueSize:          s.queuedPlayers(),
+        PendingMatches:     len(matches),
+        AverageWaitSeconds: int(s.averageWait().Seconds()),
+    }
+}
+
+// Accept ยอมรับแมตช์ที่จับคู่ได้ เมื่อทุกคนยอมรับครบจะสร้างเกมทันที
+func (s *MatchmakingService) Accept(playerID string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    match := s.pending[playerID]
+    if match == nil {
+        return ErrNoPendingMatch
+    }
+    match.accepted[playerID] = true
+
+    now := time.Now()
+    if len(match.accepted) == match.players() {
+        s.startMatch(match, now)
+        return nil
+    }
+
+    s.notifyMatchFound(match, now)
+    return nil
+}
+
+// Decline ปฏิเสธแมตช์ ผู้เล่นคนอื่นกลับเข้าคิว ส่วนผู้ปฏิเสธถูกห้ามเข้าคิวชั่วคราว
+func (s *MatchmakingService) Decline(playerID string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    match := s.pending[playerID]
+    if match == nil {
+        return ErrNoPendingMatch
+    }
+    s.dodge(match, []string{playerID}, time.Now())
+    return nil
+}
+
+// Run จับคู่ผู้เล่นและส่งสถานะคิวทุก TickInterval จนกว่า ctx จะถูกยกเลิก
+func (s *MatchmakingService) Run(ctx context.Context) {
+    ticker := time.NewTicker(s.cfg.TickInterval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case now := <-ticker.C:
+            s.tick(now)
+        }
+    }
+}
+
+func (s *MatchmakingService) tick(now time.Time) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    s.expirePending(now)
+
+    for _, group := range s.formGroups(now) {
+        s.proposeMatch(group, now)
+    }
+
+    for _, entry := range s.queue {
+        for _, member := range entry.members {
+            s.onStatus(s.status(entry, member, now))
+        }
+    }
+}
+
+// formGroups หากลุ่มผู้เล่นที่ MMR อยู่ในช่วงของกันและกันครบตามขนาดล็อบบี้ ปาร์ตี้นับตามจำนวนสมาชิก
+func (s *MatchmakingService) formGroups(now time.Time) [][]*queueEntry {
+    var groups [][]*queueEntry
+    used := make(map[*queueEntry]bool)
+
+    for _, anchor := range s.queue {
+        if used[anchor] {
+            continue
+        }
+
+        // ผู้สมัครเรียงตาม MMR ที่ใกล้กับ anchor ที่สุด
+        var candidates []*queueEntry
+        for _, entry := range s.queue {
+            if entry != anchor && !used[entry] {
+                candidates = append(candidates, entry)
+            }
+        }
+        sort.SliceStable(candidates, func(i, j int) bool {
+            return abs(candidates[i].mmr-anchor.mmr) < abs(candidates[j].mmr-anchor.mmr)
+        })
+
+        group := []*queueEntry{anchor}
+        size := anchor.size()
+        for _, candidate := range candidates {
+            if size == s.cfg.LobbySize {
+                break
+            }
+            if size+candidate.size() > s.cfg.LobbySize {
+                continue
+            }
+            if s.fitsGroup(group, candidate, now) {
+                group = append(group, candidate)
+                size += candidate.size()
+            }
+        }
+
+        if size == s.cfg.LobbySize {
+            for _, entry := range group {
+                used[entry] = true
+            }
+            groups = append(groups, group)
+        }
+    }
+    return groups
+}
+
+// fitsGroup ผู้สมัครต้องอยู่ในช่วงของทุกคนในกลุ่ม และทุกคนต้องอยู่ในช่วงของผู้สมัคร
+func (s *MatchmakingService) fitsGroup(group []*queueEntry, candidate *queueEntry, now time.Time) bool {
+    candidateWindow := s.window(candidate, now)
+    for _, member := range group {
+        diff := abs(member.mmr - candidate.mmr)
+        if diff > candidateWindow || diff > s.window(member, now) {
+            return false
+        }
+    }
+    return true
+}
+
+// window คือช่วง MMR ที่ยอมจับคู่ ขยายขึ้นตามเวลาที่รอ
+func (s *MatchmakingService) window(entry *queueEntry, now time.Time) int {
+    window := s.cfg.BaseWindow
+    if s.cfg.WindowGrowthInterval > 0 {
+        steps := int(now.Sub(entry.joinedAt) / s.cfg.WindowGrowthInterval)
+        window += steps * s.cfg.WindowGrowth
+    }
+    if window > s.cfg.MaxWindow {
+        window = s.cfg.MaxWindow
+    }
+    return window
+}
+
+// proposeMatch ย้ายกลุ่มออกจากคิวไปรอให้ทุกคนกดยอมรับ
+func (s *MatchmakingService) proposeMatch(group []*queueEntry, now time.Time) {
+    s.matchSeq++
+    match := &pendingMatch{
+        id:        fmt.Sprintf("match_%d", s.matchSeq),
+        entries:   group,
+        accepted:  make(map[string]bool),
+        expiresAt: now.Add(s.cfg.AcceptTimeout),
+    }
+
+    for _, entry := range group {
+        s.remove(entry)
+        for _, member := range entry.members {
+            s.pending[member] = match
+        }
+    }
+    s.notifyMatchFound(match, now)
+}
+
+func (s *MatchmakingService) notifyMatchFound(match *pendingMatch, now time.Time) {
+    for _, entry := range match.entries {
+        for _, member := range entry.members {
+            s.onStatus(s.matchFoundStatus(match, entry, member, now))
+        }
+    }
+}
+
+// expirePending คนที่ไม่กดยอมรับทันเวลาถือว่าปฏิเสธ
+func (s *MatchmakingService) expirePending(now time.Time) {
+    seen := make(map[*pendingMatch]bool)
+    for _, match := range s.pending {
+        if seen[match] || now.Before(match.expiresAt) {
+            continue
+        }
+        seen[match] = true
+
+        var dodgers []string
+        for _, entry := range match.entries {
+            for _, member := range entry.members {
+                if !match.accepted[member] {
+                    dodgers = append(dodgers, member)
+                }
+            }
+        }
+        s.dodge(match, dodgers, now)
+    }
+}
+
+// dodge ยกเลิกแมตช์ ลงโทษคนที่ปฏิเสธ และส่งกลุ่มที่เหลือกลับเข้าคิว
+// เพื่อนร่วมปาร์ตี้ของคนที่ปฏิเสธจะหลุดออกจากคิวไปด้วยแต่ไม่ถูกลงโทษ
+func (s *MatchmakingService) dodge(match *pendingMatch, dodgers []string, now time.Time) {
+    declined := make(map[string]bool, len(dodgers))
+    for _, playerID := range dodgers {
+        declined[playerID] = true
+    }
+
+    for _, entry := range match.entries {
+        broken := false
+        for _, member := range entry.members {
+            delete(s.pending, member)
+            if declined[member] {
+                broken = true
+            }
+        }
+
+        if !broken {
+            s.requeue(entry)
+            for _, member := range entry.members {
+                status := s.status(entry, member, now)
+                status.State = QueueStateRequeued
+                status.MatchID = match.id
+                s.onStatus(status)
+            }
+            continue
+        }
+
+        for _, member := range entry.members {
+            if !declined[member] {
+                s.onStatus(QueueStatus{PlayerID: member, State: QueueStateCancelled, MatchID: match.id})
+                continue
+            }
+            until := s.penalize(member, now)
+            s.onStatus(QueueStatus{
+                PlayerID:      member,
+                State:         QueueStateDeclined,
+                MatchID:       match.id,
+                LockedSeconds: int(until.Sub(now).Seconds()),
+            })
+        }
+    }
+}
+
+// requeue ใส่กลุ่มกลับเข้าคิวตามเวลาที่เข้าคิวครั้งแรก ทำให้ไม่เสียลำดับและช่วง MMR ที่ขยายไว้
+func (s *MatchmakingService) requeue(entry *queueEntry) {
+    i := sort.Search(len(s.queue), func(i int) bool {
+        return s.queue[i].joinedAt.After(entry.joinedAt)
+    })
+    s.queue = append(s.queue, nil)
+    copy(s.queue[i+1:], s.queue[i:])
+    s.queue[i] = entry
+}
+
+func (s *MatchmakingService) startMatch(match *pendingMatch, now time.Time) {
+    playerIDs := make([]string, 0, match.players())
+    for _, entry := range match.entries {
+        playerIDs = append(playerIDs, entry.members...)
+    }
+
+    created, err := s.matches.CreateMatch(playerIDs)
+    if err != nil {
+        // สร้างเกมไม่ได้ไม่ใช่ความผิดของใคร ให้ทุกคนกลับเข้าคิวโดยไม่ลงโทษ
+        s.dodge(match, nil, now)
+        return
+    }
+
+    for _, entry := range match.entries {
+        s.recordWait(now.Sub(entry.joinedAt))
+        for _, member := range entry.members {
+            delete(s.pending, member)
+        }
+    }
+    for _, entry := range match.entries {
+        for _, member := range entry.members {
+            status := s.status(entry, member, now)
+            status.State = QueueStateMatched
+            status.MatchID = match.id
+            status.GameID = created.ID
+            s.onStatus(status)
+        }
+    }
+}
+
+// penalize เพิ่มจำนวนครั้งที่ปฏิเสธและคืนเวลาที่ห้ามเข้าคิวถึง
+func (s *MatchmakingService) penalize(playerID string, now time.Time) time.Time {
+    ctx := context.Background()
+
+    penalty, err := s.penalties.GetQueuePenalty(ctx, playerID)
+    if err != nil {
+        penalty = &repository.QueuePenalty{PlayerID: playerID}
+    }
+    if s.cfg.DodgeDecay > 0 && now.Sub(penalty.LastDodgeAt) > s.cfg.DodgeDecay {
+        penalty.Dodges = 0
+    }
+    penalty.Dodges++
+    penalty.LastDodgeAt = now
+
+    if len(s.cfg.DodgeLockouts) > 0 {
+        step := penalty.Dodges - 1
+        if step >= len(s.cfg.DodgeLockouts) {
+            step = len(s.cfg.DodgeLockouts) - 1
+        }
+        penalty.LockedUntil = now.Add(s.cfg.DodgeLockouts[step])
+    }
+
+    s.penalties.UpdateQueuePenalty(ctx, penalty)
+    return penalty.LockedUntil
+}
+
+// CheckLockout คืน ErrQueueLocked ถ้าผู้เล่นยังติดโทษปฏิเสธแมตช์ ใช้กับทางหาเกมที่ไม่ผ่านคิว
+func (s *MatchmakingService) CheckLockout(playerID string) error {
+    return s.lockoutError(playerID, time.Now())
+}
+
+func (s *MatchmakingService) lockoutError(playerID string, now time.Time) error {
+    if until, locked := s.lockedUntil(playerID, now); locked {
+        return fmt.Errorf("%w: %s until %s", ErrQueueLocked, playerID, until.Format(time.RFC3339))
+    }
+    return nil
+}
+
+func (s *MatchmakingService) lockedUntil(playerID string, now time.Time) (time.Time, bool) {
+    penalty, err := s.penalties.GetQueuePenalty(context.Background(), playerID)
+    if err != nil || !now.Before(penalty.LockedUntil) {
+        return time.Time{}, false
+    }
+    return penalty.LockedUntil, true
+}
+
+func (s *MatchmakingService) matchFoundStatus(match *pendingMatch, entry *queueEntry, playerID string, now time.Time) QueueStatus {
+    remaining := match.expiresAt.Sub(now)
+    if remaining < 0 {
+        remaining = 0
+    }
+
+    status := s.status(entry, playerID, now)
+    status.State = QueueStateMatchFound
+    status.MatchID = match.id
+    status.AcceptSeconds = int(remaining.Seconds())
+    status.Accepted = len(match.accepted)
+    status.Required = match.players()
+    return status
+}
+
+func (s *MatchmakingService) status(entry *queueEntry, playerID string, now time.Time) QueueStatus {
+    waited := now.Sub(entry.joinedAt)
+    estimate := s.averageWait() - waited
+    if estimate < 0 {
+        estimate = 0
+    }
+
+    return QueueStatus{
+        PlayerID:             playerID,
+        State:                QueueStateQueued,
+        MMR:                  entry.mmr,
+        Window:               s.window(entry, now),
+        QueueSize:            s.queuedPlayers(),
+        WaitedSeconds:        int(waited.Seconds()),
+        EstimatedWaitSeconds: int(estimate.Seconds()),
+        GroupSize:            entry.size(),
+    }
+}
+
+func (s *MatchmakingService) recordWait(wait time.Duration) {
+    s.waits = append(s.waits, wait)
+    if len(s.waits) > waitSampleSize {
+        s.waits = s.waits[len(s.waits)-waitSampleSize:]
+    }
+}
+
+func (s *MatchmakingService) averageWait() time.Duration {
+    if len(s.waits) == 0 {
+        return 0
+    }
+    var total time.Duration
+    for _, wait := range s.waits {
+        total += wait
+    }
+    return total / time.Duration(len(s.waits))
+}
+
+func (s *MatchmakingService) queuedPlayers() int {
+    total := 0
+    for _, entry := range s.queue {
+        total += entry.size()
+    }
+    return total
+}
+
+// find คืนตำแหน่งในคิวของกลุ่มที่มีผู้เล่นคนนี้
+func (s *MatchmakingService) find(playerID string) int {
+    for i, entry := range s.queue {
+        if entry.has(playerID) {
+            return i
+        }
+    }
+    return -1
+}
+
+func (s *MatchmakingService) remove(entry *queueEntry) {
+    for i, queued := range s.queue {
+        if queued == entry {
+            s.queue = append(s.queue[:i], s.queue[i+1:]...)
+            return
+        }
+    }
+}
+
+func abs(n int) int {
+    if n < 0 {
+        return -n
+    }
+    return n
+}
\ No newline at end of file
@@ -0,0 +1,256 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/internal/repository"
+)
+
+// fakeMatches เก็บกลุ่มผู้เล่นที่ถูกจับคู่ไว้ตรวจสอบ
+type fakeMatches struct {
+    groups [][]string
+}
+
+func (f *fakeMatches) CreateMatch(playerIDs []string) (*game.Game, error) {
+    f.groups = append(f.groups, playerIDs)
+    return game.NewGame("match"), nil
+}
+
+// newTestQueue สร้างคิวพร้อมผู้เล่นตาม MMR ที่ให้มา
+func newTestQueue(t *testing.T, lobbySize int, mmrs ...int) (*MatchmakingService, *fakeMatches, []string) {
+    t.Helper()
+
+    repo := repository.NewMemoryPlayerRepository()
+    ids := make([]string, 0, len(mmrs))
+    for i, mmr := range mmrs {
+        username := string(rune('a' + i))
+        player, err := repo.Create(context.Background(), username, username+"@example.com", "secret")
+        if err != nil {
+            t.Fatalf("failed to create player: %v", err)
+        }
+        player.MMR = mmr
+        repo.Update(context.Background(), player)
+        ids = append(ids, player.ID)
+    }
+
+    cfg := DefaultMatchmakingConfig()
+    cfg.LobbySize = lobbySize
+    matches := &fakeMatches{}
+    return NewMatchmakingService(cfg, repo, repo, matches), matches, ids
+}
+
+// acceptAll ให้ทุกคนในรายชื่อกดยอมรับแมตช์
+func acceptAll(t *testing.T, mm *MatchmakingService, ids ...string) {
+    t.Helper()
+    for _, id := range ids {
+        if err := mm.Accept(id); err != nil {
+            t.Fatalf("failed to accept match for %s: %v", id, err)
+        }
+    }
+}
+
+func TestMatchmaking(t *testing.T) {
+    t.Run("Pairs Close MMR First", func(t *testing.T) {
+        mm, matches, ids := newTestQueue(t, 2, 1000, 1600, 1050)
+        for _, id := range ids {
+            if _, err := mm.Enqueue(id); err != nil {
+                t.Fatalf("failed to enqueue: %v", err)
+            }
+        }
+
+        mm.tick(time.Now())
+        acceptAll(t, mm, ids[0], ids[2])
+
+        if len(matches.groups) != 1 {
+            t.Fatalf("expected 1 match, got %d", len(matches.groups))
+        }
+        group := matches.groups[0]
+        if group[0] != ids[0] || group[1] != ids[2] {
+            t.Errorf("expected %s and %s to be matched, got %v", ids[0], ids[2], group)
+        }
+        if _, err := mm.Status(ids[1]); err != nil {
+            t.Errorf("expected %s to still be queued, got %v", ids[1], err)
+        }
+    })
+
+    t.Run("Window Widens With Wait", func(t *testing.T) {
+        mm, matches, ids := newTestQueue(t, 2, 1000, 1300)
+
+        var statuses []QueueStatus
+        mm.SetOnStatus(func(status QueueStatus) {
+            statuses = append(statuses, status)
+        })
+        for _, id := range ids {
+            mm.Enqueue(id)
+        }
+
+        now := time.Now()
+        mm.tick(now)
+        if len(matches.groups) != 0 {
+            t.Fatalf("expected no match with 300 MMR gap, got %v", matches.groups)
+        }
+        if len(statuses) != 2 || statuses[0].State != QueueStateQueued {
+            t.Fatalf("expected queued status for both players, got %+v", statuses)
+        }
+
+        // ต้องรอ 40 วินาทีช่วงถึงจะกว้างพอ (100 + 4*50)
+        mm.tick(now.Add(30 * time.Second))
+        if len(matches.groups) != 0 {
+            t.Fatalf("expected no match yet, got %v", matches.groups)
+        }
+        mm.tick(now.Add(41 * time.Second))
+        if last := statuses[len(statuses)-1]; last.State != QueueStateMatchFound || last.Required != 2 {
+            t.Fatalf("expected match_found status, got %+v", last)
+        }
+        acceptAll(t, mm, ids...)
+        if len(matches.groups) != 1 {
+            t.Fatalf("expected match after window widened, got %v", matches.groups)
+        }
+        if last := statuses[len(statuses)-1]; last.State != QueueStateMatched || last.GameID == "" {
+            t.Errorf("expected matched status with game ID, got %+v", last)
+        }
+    })
+
+    t.Run("Cancel", func(t *testing.T) {
+        mm, _, ids := newTestQueue(t, 2, 1000)
+        mm.Enqueue(ids[0])
+
+        if _, err := mm.Enqueue(ids[0]); err != ErrAlreadyQueued {
+            t.Errorf("expected %v, got %v", ErrAlreadyQueued, err)
+        }
+        if err := mm.Cancel(ids[0]); err != nil {
+            t.Fatalf("failed to cancel: %v", err)
+        }
+        if err := mm.Cancel(ids[0]); err != ErrNotQueued {
+            t.Errorf("expected %v, got %v", ErrNotQueued, err)
+        }
+    })
+
+    t.Run("Enqueue While Rating Changes", func(t *testing.T) {
+        mm, _, ids := newTestQueue(t, 8, 1000)
+        repo := mm.playerRepo.(*repository.MemoryPlayerRepository)
+
+        // ให้ rating เปลี่ยนไปเรื่อยๆ จนกว่าการเข้าคิวจะเสร็จ สองฝั่งจึงทำงานซ้อนกันจริง
+        started, done := make(chan struct{}), make(chan struct{})
+        var wg sync.WaitGroup
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            close(started)
+            for i := 0; ; i++ {
+                select {
+                case <-done:
+                    return
+                default:
+                }
+                repo.UpdateRating(context.Background(), &repository.Rating{PlayerID: ids[0], Rating: 1000 + float64(i%100)}, repository.RatingChange{})
+            }
+        }()
+        defer func() {
+            close(done)
+            wg.Wait()
+        }()
+        <-started
+        for i := 0; i < 200; i++ {
+            if _, err := mm.Enqueue(ids[0]); err != nil {
+                t.Fatalf("failed to enqueue: %v", err)
+            }
+            mm.Cancel(ids[0])
+        }
+    })
+
+    t.Run("Stats", func(t *testing.T) {
+        mm, _, ids := newTestQueue(t, 2, 1000, 1020, 1900)
+        for _, id := range ids {
+            mm.Enqueue(id)
+        }
+        if stats := mm.Stats(); stats.QueueSize != 3 || stats.PendingMatches != 0 {
+            t.Fatalf("expected 3 queued and no pending match, got %+v", stats)
+        }
+
+        mm.tick(time.Now())
+        if stats := mm.Stats(); stats.QueueSize != 1 || stats.PendingMatches != 1 {
+            t.Errorf("expected 1 queued and 1 pending match, got %+v", stats)
+        }
+    })
+
+    t.Run("Decline Requeues Others With Priority", func(t *testing.T) {
+        mm, matches, ids := newTestQueue(t, 2, 1000, 1000, 1000)
+
+        var statuses []QueueStatus
+        mm.SetOnStatus(func(status QueueStatus) {
+            statuses = append(statuses, status)
+        })
+        for _, id := range ids {
+            mm.Enqueue(id)
+        }
+
+        now := time.Now()
+        mm.tick(now)
+        mm.Accept(ids[0])
+        if err := mm.Decline(ids[1]); err != nil {
+            t.Fatalf("failed to decline: %v", err)
+        }
+        if len(matches.groups) != 0 {
+            t.Fatalf("expected no game after decline, got %v", matches.groups)
+        }
+
+        // ผู้เล่นที่ยอมรับต้องกลับไปอยู่หน้าคิวก่อนคนที่เข้าคิวทีหลัง
+        if mm.queue[0].members[0] != ids[0] || mm.queue[1].members[0] != ids[2] {
+            t.Errorf("expected %s to keep priority, got queue %s, %s", ids[0], mm.queue[0].members[0], mm.queue[1].members[0])
+        }
+
+        var declined QueueStatus
+        for _, status := range statuses {
+            if status.PlayerID == ids[1] && status.State == QueueStateDeclined {
+                declined = status
+            }
+        }
+        if declined.LockedSeconds != 60 {
+            t.Errorf("expected 60s lockout, got %+v", declined)
+        }
+
+        if _, err := mm.Enqueue(ids[1]); !errors.Is(err, ErrQueueLocked) {
+            t.Errorf("expected %v, got %v", ErrQueueLocked, err)
+        }
+    })
+
+    t.Run("Timeout Escalates Lockout", func(t *testing.T) {
+        mm, _, ids := newTestQueue(t, 2, 1000, 1000)
+
+        now := time.Now()
+        var lockouts []time.Duration
+        for round := 0; round < 2; round++ {
+            if _, err := mm.Enqueue(ids[1]); err != nil {
+                t.Fatalf("round %d: failed to enqueue: %v", round, err)
+            }
+            mm.Enqueue(ids[0])
+            mm.tick(now)
+            mm.Accept(ids[0])
+            mm.tick(now.Add(mm.cfg.AcceptTimeout))
+            mm.Cancel(ids[0])
+
+            penalty, err := mm.penalties.GetQueuePenalty(context.Background(), ids[1])
+            if err != nil {
+                t.Fatalf("round %d: expected penalty to be stored: %v", round, err)
+            }
+            lockouts = append(lockouts, penalty.LockedUntil.Sub(penalty.LastDodgeAt))
+
+            // จำลองว่าพ้นโทษแล้วแต่ยังอยู่ในช่วงที่นับจำนวนครั้ง
+            penalty.LockedUntil = time.Time{}
+            mm.penalties.UpdateQueuePenalty(context.Background(), penalty)
+        }
+
+        if lockouts[0] != time.Minute || lockouts[1] != 5*time.Minute {
+            t.Errorf("expected lockouts of 1m then 5m, got %v", lockouts)
+        }
+        if _, err := mm.penalties.GetQueuePenalty(context.Background(), ids[0]); err == nil {
+            t.Error("expected player who accepted to stay unpenalised")
+        }
+    })
+}
\ No newline at end of file
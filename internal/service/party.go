@@ -0,0 +1,313 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sync"
+    "time"
+)
+
+var (
+    ErrPartyNotFound  = errors.New("party not found")
+    ErrAlreadyInParty = errors.New("player is already in a party")
+    ErrNotInParty     = errors.New("player is not in a party")
+    ErrNotPartyLeader = errors.New("only the party leader can do this")
+    ErrPartyFull      = errors.New("party is full")
+    ErrNoInvite       = errors.New("player has no invite to this party")
+    ErrInviteYourself = errors.New("cannot invite yourself")
+)
+
+const (
+    PartyEventInvited   = "invited"
+    PartyEventDeclined  = "declined"
+    PartyEventJoined    = "joined"
+    PartyEventLeft      = "left"
+    PartyEventKicked    = "kicked"
+    PartyEventDisbanded = "disbanded"
+
+    DefaultMaxPartySize = 2
+)
+
+type Party struct {
+    ID        string    `json:"id"`
+    LeaderID  string    `json:"leader_id"`
+    Members   []string  `json:"members"`
+    Invites   []string  `json:"invites"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// PartyEvent คือการเปลี่ยนแปลงของปาร์ตี้ที่ส่งให้สมาชิกและคนที่ถูกเชิญ
+type PartyEvent struct {
+    Type     string `json:"event"`
+    PlayerID string `json:"player_id"` // ผู้เล่นที่ทำให้เกิด event นี้
+    Party    Party  `json:"party"`
+}
+
+// PartyQueue คือคิวที่ปาร์ตี้ใช้เข้าจับคู่ ปกติคือ MatchmakingService
+type PartyQueue interface {
+    Enqueue(playerID string) (QueueStatus, error)
+    EnqueueGroup(playerIDs []string) (QueueStatus, error)
+    Withdraw(playerIDs ...string) error
+}
+
+// PartyService ดูแลการเชิญ การเข้าออกปาร์ตี้ และให้หัวหน้าพาทั้งปาร์ตี้เข้าคิว
+type PartyService struct {
+    mu         sync.Mutex
+    playerRepo PlayerRepository
+    queue      PartyQueue
+    maxSize    int
+    parties    map[string]*Party // key: partyID
+    byPlayer   map[string]*Party // key: playerID ของสมาชิก
+    seq        int
+    onEvent    func(recipients []string, event PartyEvent)
+}
+
+func NewPartyService(playerRepo PlayerRepository, queue PartyQueue, maxSize int) *PartyService {
+    if maxSize < 2 {
+        maxSize = DefaultMaxPartySize
+    }
+    return &PartyService{
+        playerRepo: playerRepo,
+        queue:      queue,
+        maxSize:    maxSize,
+        parties:    make(map[string]*Party),
+        byPlayer:   make(map[string]*Party),
+        onEvent:    func([]string, PartyEvent) {},
+    }
+}
+
+// SetOnEvent ตั้ง callback ที่จะถูกเรียกทุกครั้งที่ปาร์ตี้เปลี่ยน พร้อมรายชื่อคนที่ควรได้รับ
+func (s *PartyService) SetOnEvent(callback func(recipients []string, event PartyEvent)) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.onEvent = callback
+}
+
+func (s *PartyService) Get(playerID string) (Party, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    party := s.byPlayer[playerID]
+    if party == nil {
+        return Party{}, ErrNotInParty
+    }
+    return party.snapshot(), nil
+}
+
+// Invite เชิญผู้เล่นเข้าปาร์ตี้ ถ้าผู้เชิญยังไม่มีปาร์ตี้จะสร้างให้โดยเป็นหัวหน้า
+func (s *PartyService) Invite(leaderID, targetID string) (Party, error) {
+    if leaderID == targetID {
+        return Party{}, ErrInviteYourself
+    }
+    if _, err := s.playerRepo.GetByID(context.Background(), targetID); err != nil {
+        return Party{}, err
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    party := s.byPlayer[leaderID]
+    if party != nil && party.LeaderID != leaderID {
+        return Party{}, ErrNotPartyLeader
+    }
+    if s.byPlayer[targetID] != nil {
+        return Party{}, ErrAlreadyInParty
+    }
+
+    if party == nil {
+        s.seq++
+        party = &Party{
+            ID:        fmt.Sprintf("party_%d", s.seq),
+            LeaderID:  leaderID,
+            Members:   []string{leaderID},
+            CreatedAt: time.Now(),
+        }
+        s.parties[party.ID] = party
+        s.byPlayer[leaderID] = party
+    }
+
+    if containsID(party.Invites, targetID) {
+        return party.snapshot(), nil
+    }
+    if len(party.Members)+len(party.Invites) >= s.maxSize {
+        return Party{}, ErrPartyFull
+    }
+
+    party.Invites = append(party.Invites, targetID)
+    s.emit(party, PartyEventInvited, targetID, targetID)
+    return party.snapshot(), nil
+}
+
+func (s *PartyService) AcceptInvite(playerID, partyID string) (Party, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    party := s.parties[partyID]
+    if party == nil {
+        return Party{}, ErrPartyNotFound
+    }
+    if !containsID(party.Invites, playerID) {
+        return Party{}, ErrNoInvite
+    }
+    if s.byPlayer[playerID] != nil {
+        return Party{}, ErrAlreadyInParty
+    }
+
+    // ปาร์ตี้ที่กำลังรอคิวต้องออกจากคิวก่อน เพราะสมาชิกเปลี่ยน
+    affected := append([]string{playerID}, party.Members...)
+    if err := s.queue.Withdraw(affected...); err != nil {
+        return Party{}, err
+    }
+
+    party.Invites = removeID(party.Invites, playerID)
+    party.Members = append(party.Members, playerID)
+    s.byPlayer[playerID] = party
+
+    s.emit(party, PartyEventJoined, playerID)
+    return party.snapshot(), nil
+}
+
+func (s *PartyService) DeclineInvite(playerID, partyID string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    party := s.parties[partyID]
+    if party == nil {
+        return ErrPartyNotFound
+    }
+    if !containsID(party.Invites, playerID) {
+        return ErrNoInvite
+    }
+
+    party.Invites = removeID(party.Invites, playerID)
+    s.emit(party, PartyEventDeclined, playerID, playerID)
+    s.disbandIfEmpty(party)
+    return nil
+}
+
+// Leave ออกจากปาร์ตี้ ถ้าเป็นหัวหน้าจะส่งต่อให้สมาชิกที่เข้ามาก่อนที่สุด
+func (s *PartyService) Leave(playerID string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    party := s.byPlayer[playerID]
+    if party == nil {
+        return ErrNotInParty
+    }
+    if err := s.queue.Withdraw(party.Members...); err != nil {
+        return err
+    }
+
+    s.removeMember(party, playerID)
+    s.emit(party, PartyEventLeft, playerID, playerID)
+    s.disbandIfEmpty(party)
+    return nil
+}
+
+func (s *PartyService) Kick(leaderID, targetID string) error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    party := s.byPlayer[leaderID]
+    if party == nil {
+        return ErrNotInParty
+    }
+    if party.LeaderID != leaderID {
+        return ErrNotPartyLeader
+    }
+    if leaderID == targetID || !containsID(party.Members, targetID) {
+        return ErrNotInParty
+    }
+    if err := s.queue.Withdraw(party.Members...); err != nil {
+        return err
+    }
+
+    s.removeMember(party, targetID)
+    s.emit(party, PartyEventKicked, targetID, targetID)
+    s.disbandIfEmpty(party)
+    return nil
+}
+
+// Queue พาผู้เล่นเข้าคิว ถ้าอยู่ในปาร์ตี้ต้องเป็นหัวหน้าและจะเข้าคิวพร้อมกันทั้งปาร์ตี้
+func (s *PartyService) Queue(playerID string) (QueueStatus, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    party := s.byPlayer[playerID]
+    if party == nil {
+        return s.queue.Enqueue(playerID)
+    }
+    if party.LeaderID != playerID {
+        return QueueStatus{}, ErrNotPartyLeader
+    }
+    return s.queue.EnqueueGroup(party.Members)
+}
+
+// ต้องเรียกขณะถือ s.mu อยู่
+func (s *PartyService) removeMember(party *Party, playerID string) {
+    party.Members = removeID(party.Members, playerID)
+    delete(s.byPlayer, playerID)
+
+    if party.LeaderID == playerID && len(party.Members) > 0 {
+        party.LeaderID = party.Members[0]
+    }
+}
+
+// disbandIfEmpty ยุบปาร์ตี้ที่เหลือสมาชิกคนเดียวและไม่มีคำเชิญค้างอยู่
+// ต้องเรียกขณะถือ s.mu อยู่
+func (s *PartyService) disbandIfEmpty(party *Party) {
+    if len(party.Members) > 1 || len(party.Invites) > 0 {
+        return
+    }
+
+    s.emit(party, PartyEventDisbanded, party.LeaderID)
+    for _, member := range party.Members {
+        delete(s.byPlayer, member)
+    }
+    delete(s.parties, party.ID)
+}
+
+// emit ส่ง event ให้สมาชิก คนที่ถูกเชิญ และผู้รับเพิ่มเติม เช่นคนที่เพิ่งออกไป
+// ต้องเรียกขณะถือ s.mu อยู่
+func (s *PartyService) emit(party *Party, eventType, playerID string, extra ...string) {
+    recipients := append([]string(nil), party.Members...)
+    recipients = append(recipients, party.Invites...)
+    for _, id := range extra {
+        if !containsID(recipients, id) {
+            recipients = append(recipients, id)
+        }
+    }
+
+    s.onEvent(recipients, PartyEvent{
+        Type:     eventType,
+        PlayerID: playerID,
+        Party:    party.snapshot(),
+    })
+}
+
+func (p *Party) snapshot() Party {
+    copied := *p
+    copied.Members = append([]string{}, p.Members...)
+    copied.Invites = append([]string{}, p.Invites...)
+    return copied
+}
+
+func containsID(ids []string, id string) bool {
+    for _, existing := range ids {
+        if existing == id {
+            return true
+        }
+    }
+    return false
+}
+
+func removeID(ids []string, id string) []string {
+    result := ids[:0]
+    for _, existing := range ids {
+        if existing != id {
+            result = append(result, existing)
+        }
+    }
+    return result
+}
\ No newline at end of file
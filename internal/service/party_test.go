@@ -0,0 +1,93 @@
+package service
+
+import (
+    "testing"
+    "time"
+)
+
+func TestPartyService(t *testing.T) {
+    mm, matches, ids := newTestQueue(t, 4, 1000, 1200, 1100, 1100)
+    parties := NewPartyService(mm.playerRepo, mm, 2)
+
+    received := make(map[string][]string)
+    parties.SetOnEvent(func(recipients []string, event PartyEvent) {
+        for _, id := range recipients {
+            received[id] = append(received[id], event.Type)
+        }
+    })
+
+    leader, friend := ids[0], ids[1]
+
+    t.Run("Invite And Accept", func(t *testing.T) {
+        party, err := parties.Invite(leader, friend)
+        if err != nil {
+            t.Fatalf("failed to invite: %v", err)
+        }
+        if party.LeaderID != leader || len(party.Invites) != 1 {
+            t.Fatalf("unexpected party after invite: %+v", party)
+        }
+        if _, err := parties.Invite(leader, ids[2]); err != ErrPartyFull {
+            t.Errorf("expected %v, got %v", ErrPartyFull, err)
+        }
+
+        if _, err := parties.AcceptInvite(friend, party.ID); err != nil {
+            t.Fatalf("failed to accept invite: %v", err)
+        }
+        joined, _ := parties.Get(friend)
+        if len(joined.Members) != 2 || len(joined.Invites) != 0 {
+            t.Errorf("expected 2 members and no invites, got %+v", joined)
+        }
+        if got := received[friend]; len(got) != 2 || got[0] != PartyEventInvited || got[1] != PartyEventJoined {
+            t.Errorf("expected invited and joined events for friend, got %v", got)
+        }
+    })
+
+    t.Run("Only Leader Queues", func(t *testing.T) {
+        if _, err := parties.Queue(friend); err != ErrNotPartyLeader {
+            t.Errorf("expected %v, got %v", ErrNotPartyLeader, err)
+        }
+
+        status, err := parties.Queue(leader)
+        if err != nil {
+            t.Fatalf("failed to queue party: %v", err)
+        }
+        if status.GroupSize != 2 || status.MMR != 1100 {
+            t.Errorf("expected party of 2 with average MMR 1100, got %+v", status)
+        }
+        if _, err := mm.Status(friend); err != nil {
+            t.Errorf("expected friend to be queued with the party, got %v", err)
+        }
+    })
+
+    t.Run("Party Is Never Split", func(t *testing.T) {
+        parties.Queue(ids[2])
+        parties.Queue(ids[3])
+
+        mm.tick(time.Now())
+        acceptAll(t, mm, ids...)
+
+        if len(matches.groups) != 1 || len(matches.groups[0]) != 4 {
+            t.Fatalf("expected one lobby of 4, got %v", matches.groups)
+        }
+        if group := matches.groups[0]; group[0] != leader || group[1] != friend {
+            t.Errorf("expected party members to be matched together, got %v", group)
+        }
+    })
+
+    t.Run("Leaving Withdraws Party From Queue", func(t *testing.T) {
+        parties.Queue(leader)
+        if err := parties.Leave(leader); err != nil {
+            t.Fatalf("failed to leave: %v", err)
+        }
+
+        if _, err := mm.Status(friend); err != ErrNotQueued {
+            t.Errorf("expected party to leave the queue, got %v", err)
+        }
+        if _, err := parties.Get(friend); err != ErrNotInParty {
+            t.Errorf("expected party of one to be disbanded, got %v", err)
+        }
+        if got := received[friend]; got[len(got)-1] != PartyEventDisbanded {
+            t.Errorf("expected disbanded event last, got %v", got)
+        }
+    })
+}
\ No newline at end of file
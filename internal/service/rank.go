@@ -0,0 +1,205 @@
+package service
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/internal/domain/rank"
+    "github.com/tem-mars/tft-game-server/internal/repository"
+)
+
+const (
+    // ตอนจบ season ดึง rating เข้าหาค่ากลางครึ่งหนึ่ง และเพิ่ม deviation ให้ขยับได้เร็วขึ้น
+    softResetFactor    = 0.5
+    softResetDeviation = 200
+
+    seasonCheckInterval = time.Minute
+)
+
+type RankRepository interface {
+    GetRank(ctx context.Context, playerID string) (*repository.Rank, error)
+    UpdateRank(ctx context.Context, rank *repository.Rank) error
+    ListRanks(ctx context.Context) ([]*repository.Rank, error)
+    ArchiveSeason(ctx context.Context, playerID string, result repository.SeasonResult) error
+    GetSeasonHistory(ctx context.Context, playerID string) ([]repository.SeasonResult, error)
+
+    GetRating(ctx context.Context, playerID string) (*repository.Rating, error)
+    UpdateRating(ctx context.Context, rating *repository.Rating, change repository.RatingChange) error
+    ListRatings(ctx context.Context) ([]*repository.Rating, error)
+}
+
+type Season struct {
+    ID        int       `json:"id"`
+    StartedAt time.Time `json:"started_at"`
+    EndsAt    time.Time `json:"ends_at"`
+}
+
+// RankService ให้ LP จากเกมแรงค์และจัดการรอยต่อระหว่าง season
+type RankService struct {
+    mu            sync.Mutex
+    repo          RankRepository
+    season        Season
+    seasonLength  time.Duration
+    onSeasonEnded []func(Season)
+}
+
+func NewRankService(repo RankRepository, seasonLength time.Duration) *RankService {
+    now := time.Now()
+    return &RankService{
+        repo:         repo,
+        seasonLength: seasonLength,
+        season: Season{
+            ID:        1,
+            StartedAt: now,
+            EndsAt:    now.Add(seasonLength),
+        },
+    }
+}
+
+// AddOnSeasonEnded เพิ่ม callback ที่จะถูกเรียกหลัง soft reset พร้อม season ใหม่
+func (s *RankService) AddOnSeasonEnded(callback func(Season)) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.onSeasonEnded = append(s.onSeasonEnded, callback)
+}
+
+func (s *RankService) CurrentSeason() Season {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.season
+}
+
+// RecordGame ใช้เป็น callback ของ GameManager.AddOnGameFinished นับเฉพาะเกมแรงค์
+func (s *RankService) RecordGame(g *game.Game) {
+    if !g.Ranked {
+        return
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    ctx := context.Background()
+    for _, p := range g.Players {
+        if p.IsBot || p.Placement == 0 {
+            continue
+        }
+
+        current := s.currentRank(ctx, p.ID)
+        updated := toRank(current).Apply(rank.LPChange(p.Placement, len(g.Players)))
+
+        current.Tier = string(updated.Tier)
+        current.Division = updated.Division
+        current.LP = updated.LP
+        current.Games++
+        if p.Placement == 1 {
+            current.Wins++
+        }
+        s.repo.UpdateRank(ctx, current)
+    }
+}
+
+func (s *RankService) GetRank(playerID string) (*repository.Rank, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.currentRank(context.Background(), playerID), nil
+}
+
+func (s *RankService) GetSeasonHistory(playerID string) ([]repository.SeasonResult, error) {
+    return s.repo.GetSeasonHistory(context.Background(), playerID)
+}
+
+// currentRank คืนแรงค์ของ season ปัจจุบัน ถ้ายังไม่เคยเล่นใน season นี้จะเริ่มที่ Iron IV
+func (s *RankService) currentRank(ctx context.Context, playerID string) *repository.Rank {
+    if current, err := s.repo.GetRank(ctx, playerID); err == nil && current.Season == s.season.ID {
+        return current
+    }
+
+    starting := rank.Starting()
+    return &repository.Rank{
+        PlayerID: playerID,
+        Season:   s.season.ID,
+        Tier:     string(starting.Tier),
+        Division: starting.Division,
+        LP:       starting.LP,
+    }
+}
+
+// Run ตรวจรอยต่อ season เป็นระยะจนกว่า ctx จะถูกยกเลิก
+func (s *RankService) Run(ctx context.Context) {
+    ticker := time.NewTicker(seasonCheckInterval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case now := <-ticker.C:
+            if !now.Before(s.CurrentSeason().EndsAt) {
+                s.EndSeason(now)
+            }
+        }
+    }
+}
+
+// EndSeason เก็บผลของ season ปัจจุบัน soft reset rating แล้วเริ่ม season ใหม่
+// แรงค์ไม่ต้องล้าง เพราะ currentRank จะเริ่มใหม่เองเมื่อ season ไม่ตรง
+func (s *RankService) EndSeason(now time.Time) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    ctx := context.Background()
+
+    ranks, _ := s.repo.ListRanks(ctx)
+    for _, r := range ranks {
+        if r.Season != s.season.ID {
+            continue
+        }
+        result := repository.SeasonResult{
+            Season:   r.Season,
+            Tier:     r.Tier,
+            Division: r.Division,
+            LP:       r.LP,
+            Games:    r.Games,
+            Wins:     r.Wins,
+            EndedAt:  now,
+        }
+        if rating, err := s.repo.GetRating(ctx, r.PlayerID); err == nil {
+            result.Rating = rating.Rating
+        }
+        s.repo.ArchiveSeason(ctx, r.PlayerID, result)
+    }
+
+    ratings, _ := s.repo.ListRatings(ctx)
+    for _, r := range ratings {
+        before := r.Rating
+        r.Rating = repository.DefaultMMR + (r.Rating-repository.DefaultMMR)*softResetFactor
+        if r.Deviation < softResetDeviation {
+            r.Deviation = softResetDeviation
+        }
+        s.repo.UpdateRating(ctx, r, repository.RatingChange{
+            Before:    before,
+            After:     r.Rating,
+            Deviation: r.Deviation,
+            Reason:    "season_reset",
+        })
+    }
+
+    s.season = Season{
+        ID:        s.season.ID + 1,
+        StartedAt: now,
+        EndsAt:    now.Add(s.seasonLength),
+    }
+    for _, callback := range s.onSeasonEnded {
+        callback(s.season)
+    }
+}
+
+func toRank(r *repository.Rank) rank.Rank {
+    return rank.Rank{
+        Tier:     rank.Tier(r.Tier),
+        Division: r.Division,
+        LP:       r.LP,
+    }
+}
\ No newline at end of file
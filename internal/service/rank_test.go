@@ -0,0 +1,88 @@
+package service
+
+import (
+    "context"
+    "testing"
+    "time"
+
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/internal/domain/rank"
+    "github.com/tem-mars/tft-game-server/internal/repository"
+)
+
+func TestRankService(t *testing.T) {
+    ctx := context.Background()
+    repo := repository.NewMemoryPlayerRepository()
+    winner, _ := repo.Create(ctx, "winner", "winner@example.com", "secret")
+    loser, _ := repo.Create(ctx, "loser", "loser@example.com", "secret")
+
+    ranks := NewRankService(repo, time.Hour)
+
+    rankedGame := func(id string) *game.Game {
+        g := game.NewGame(id)
+        g.Status = game.StatusFinished
+        g.Ranked = true
+        g.Players = []*game.Player{
+            {ID: winner.ID, Placement: 1},
+            {ID: loser.ID, Placement: 2},
+        }
+        return g
+    }
+
+    t.Run("unranked games do not change LP", func(t *testing.T) {
+        casual := rankedGame("casual")
+        casual.Ranked = false
+        ranks.RecordGame(casual)
+
+        r, _ := ranks.GetRank(winner.ID)
+        if r.Games != 0 || r.LP != 0 {
+            t.Errorf("expected untouched rank, got %+v", r)
+        }
+    })
+
+    t.Run("ranked games move LP", func(t *testing.T) {
+        ranks.RecordGame(rankedGame("ranked-1"))
+
+        won, _ := ranks.GetRank(winner.ID)
+        if won.Tier != string(rank.Iron) || won.LP != rank.LPChange(1, 2) || won.Games != 1 || won.Wins != 1 {
+            t.Errorf("unexpected winner rank: %+v", won)
+        }
+
+        // Iron IV มีพื้น LP ไม่ติดลบ
+        lost, _ := ranks.GetRank(loser.ID)
+        if lost.LP != 0 || lost.Games != 1 || lost.Wins != 0 {
+            t.Errorf("unexpected loser rank: %+v", lost)
+        }
+    })
+
+    t.Run("season end archives ranks and soft resets ratings", func(t *testing.T) {
+        rating := &repository.Rating{PlayerID: winner.ID, Rating: 1400, Deviation: 50}
+        repo.UpdateRating(ctx, rating, repository.RatingChange{Before: repository.DefaultMMR, After: 1400})
+
+        var ended []Season
+        ranks.AddOnSeasonEnded(func(season Season) { ended = append(ended, season) })
+        ranks.EndSeason(time.Now())
+
+        if len(ended) != 1 || ended[0].ID != 2 {
+            t.Errorf("expected season end callback with season 2, got %+v", ended)
+        }
+        if ranks.CurrentSeason().ID != 2 {
+            t.Errorf("expected season 2, got %d", ranks.CurrentSeason().ID)
+        }
+
+        history, _ := ranks.GetSeasonHistory(winner.ID)
+        if len(history) != 1 || history[0].Season != 1 || history[0].Wins != 1 || history[0].Rating != 1400 {
+            t.Errorf("unexpected season history: %+v", history)
+        }
+
+        reset, _ := repo.GetRating(ctx, winner.ID)
+        if reset.Rating != 1200 || reset.Deviation != softResetDeviation {
+            t.Errorf("expected soft reset to 1200 with deviation %d, got %.1f and %.1f", softResetDeviation, reset.Rating, reset.Deviation)
+        }
+
+        fresh, _ := ranks.GetRank(winner.ID)
+        if fresh.Season != 2 || fresh.Games != 0 || fresh.LP != 0 {
+            t.Errorf("expected a fresh rank for the new season, got %+v", fresh)
+        }
+    })
+}
\ No newline at end of file
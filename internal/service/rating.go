@@ -0,0 +1,107 @@
+package service
+
+import (
+    "context"
+
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/internal/domain/rating"
+    "github.com/tem-mars/tft-game-server/internal/repository"
+)
+
+type RatingRepository interface {
+    GetRating(ctx context.Context, playerID string) (*repository.Rating, error)
+    UpdateRating(ctx context.Context, rating *repository.Rating, change repository.RatingChange) error
+    GetRatingHistory(ctx context.Context, playerID string) ([]repository.RatingChange, error)
+}
+
+// RatingService อัพเดท rating จากอันดับเมื่อเกมจบ อัลกอริทึมเปลี่ยนได้ผ่าน rating.Rater
+type RatingService struct {
+    repo  RatingRepository
+    rater rating.Rater
+}
+
+func NewRatingService(repo RatingRepository, rater rating.Rater) *RatingService {
+    return &RatingService{
+        repo:  repo,
+        rater: rater,
+    }
+}
+
+// RecordGame ใช้เป็น callback ของ GameManager.AddOnGameFinished นับเฉพาะเกมแรงค์
+// ล็อบบี้ส่วนตัวหรือ sandbox จึงใช้ปั๊ม MMR ไม่ได้ บอทไม่มี rating จึงไม่ถูกนับ
+func (s *RatingService) RecordGame(g *game.Game) {
+    if !g.Ranked {
+        return
+    }
+    ctx := context.Background()
+
+    var results []rating.Result
+    for _, p := range g.Players {
+        if p.IsBot || p.Placement == 0 {
+            continue
+        }
+        current, err := s.GetRating(p.ID)
+        if err != nil {
+            continue
+        }
+        results = append(results, rating.Result{
+            PlayerID:  p.ID,
+            Placement: p.Placement,
+            Rating: rating.Rating{
+                Value:      current.Rating,
+                Deviation:  current.Deviation,
+                Volatility: current.Volatility,
+            },
+        })
+    }
+    if len(results) < 2 {
+        return
+    }
+
+    updated := s.rater.Rate(results)
+    for _, result := range results {
+        r, exists := updated[result.PlayerID]
+        if !exists {
+            continue
+        }
+
+        record := &repository.Rating{
+            PlayerID:   result.PlayerID,
+            Rating:     r.Value,
+            Deviation:  r.Deviation,
+            Volatility: r.Volatility,
+        }
+        if current, err := s.repo.GetRating(ctx, result.PlayerID); err == nil {
+            record.GamesRated = current.GamesRated
+        }
+        record.GamesRated++
+
+        s.repo.UpdateRating(ctx, record, repository.RatingChange{
+            GameID:    g.ID,
+            Placement: result.Placement,
+            Before:    result.Rating.Value,
+            After:     r.Value,
+            Deviation: r.Deviation,
+        })
+    }
+}
+
+// GetRating คืน rating ปัจจุบัน ถ้ายังไม่เคยเล่นจะได้ค่าเริ่มต้น
+func (s *RatingService) GetRating(playerID string) (*repository.Rating, error) {
+    current, err := s.repo.GetRating(context.Background(), playerID)
+    if err == nil {
+        return current, nil
+    }
+
+    initial := rating.NewRating(repository.DefaultMMR)
+    return &repository.Rating{
+        PlayerID:   playerID,
+        Rating:     initial.Value,
+        Deviation:  initial.Deviation,
+        Volatility: initial.Volatility,
+    }, nil
+}
+
+func (s *RatingService) GetHistory(playerID string) ([]repository.RatingChange, error) {
+    return s.repo.GetRatingHistory(context.Background(), playerID)
+}
\ No newline at end of file
@@ -0,0 +1,67 @@
+package service
+
+import (
+    "context"
+    "testing"
+
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+    "github.com/tem-mars/tft-game-server/internal/domain/rating"
+    "github.com/tem-mars/tft-game-server/internal/repository"
+)
+
+func TestRatingService(t *testing.T) {
+    repo := repository.NewMemoryPlayerRepository()
+    winner, _ := repo.Create(context.Background(), "winner", "winner@example.com", "secret")
+    loser, _ := repo.Create(context.Background(), "loser", "loser@example.com", "secret")
+
+    ratings := NewRatingService(repo, rating.NewGlicko2(repository.DefaultMMR))
+
+    finished := game.NewGame("rated-game")
+    finished.Status = game.StatusFinished
+    finished.Ranked = true
+    finished.Players = []*game.Player{
+        {ID: winner.ID, Placement: 1},
+        {ID: loser.ID, Placement: 3},
+        {ID: "bot_1", Placement: 2, IsBot: true},
+    }
+    ratings.RecordGame(finished)
+
+    won, _ := ratings.GetRating(winner.ID)
+    lost, _ := ratings.GetRating(loser.ID)
+    if won.Rating <= repository.DefaultMMR || lost.Rating >= repository.DefaultMMR {
+        t.Errorf("expected winner above and loser below %d, got %.1f and %.1f", repository.DefaultMMR, won.Rating, lost.Rating)
+    }
+    if won.GamesRated != 1 || won.Deviation >= rating.DefaultDeviation {
+        t.Errorf("expected 1 rated game with lower deviation, got %d games and %.1f", won.GamesRated, won.Deviation)
+    }
+    if player, _ := repo.GetByID(context.Background(), winner.ID); player.MMR != int(won.Rating+0.5) {
+        t.Errorf("expected MMR %d to follow rating %.1f", player.MMR, won.Rating)
+    }
+
+    history, _ := ratings.GetHistory(winner.ID)
+    if len(history) != 1 || history[0].GameID != "rated-game" || history[0].Before != repository.DefaultMMR {
+        t.Errorf("unexpected rating history: %+v", history)
+    }
+
+    if _, err := repo.GetRating(context.Background(), "bot_1"); err == nil {
+        t.Error("expected bots to stay unrated")
+    }
+
+    t.Run("Unranked Games Are Not Rated", func(t *testing.T) {
+        casual := game.NewGame("casual-game")
+        casual.Status = game.StatusFinished
+        casual.Players = []*game.Player{
+            {ID: winner.ID, Placement: 2},
+            {ID: loser.ID, Placement: 1},
+        }
+        ratings.RecordGame(casual)
+
+        after, _ := ratings.GetRating(winner.ID)
+        if after.Rating != won.Rating || after.GamesRated != 1 {
+            t.Errorf("expected rating %.1f to stay unchanged, got %.1f after %d games", won.Rating, after.Rating, after.GamesRated)
+        }
+        if history, _ := ratings.GetHistory(winner.ID); len(history) != 1 {
+            t.Errorf("expected no history for unranked game, got %d entries", len(history))
+        }
+    })
+}
\ No newline at end of file
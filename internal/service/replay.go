@@ -0,0 +1,47 @@
+package service
+
+import (
+    "context"
+
+    "github.com/tem-mars/tft-game-server/internal/domain/game"
+)
+
+type ReplayRepository interface {
+    SaveReplay(ctx context.Context, gameID string, data []byte) error
+    GetReplay(ctx context.Context, gameID string) ([]byte, error)
+}
+
+// ReplayService บันทึก replay ของเกมที่จบแล้วเป็นไฟล์บีบอัด
+type ReplayService struct {
+    repo ReplayRepository
+}
+
+func NewReplayService(repo ReplayRepository) *ReplayService {
+    return &ReplayService{repo: repo}
+}
+
+// RecordGame ใช้เป็น callback ของ GameManager.AddOnGameFinished
+func (s *ReplayService) RecordGame(g *game.Game) {
+    replay, err := game.NewReplay(g)
+    if err != nil {
+        return
+    }
+    data, err := replay.Encode()
+    if err != nil {
+        return
+    }
+    s.repo.SaveReplay(context.Background(), g.ID, data)
+}
+
+func (s *ReplayService) Get(gameID string) (*game.Replay, error) {
+    data, err := s.repo.GetReplay(context.Background(), gameID)
+    if err != nil {
+        return nil, err
+    }
+    return game.DecodeReplay(data)
+}
+
+// Export คืนไฟล์ replay ตามที่เก็บไว้ (JSON บีบอัดด้วย gzip)
+func (s *ReplayService) Export(gameID string) ([]byte, error) {
+    return s.repo.GetReplay(context.Background(), gameID)
+}
\ No newline at end of file
@@ -0,0 +1,85 @@
+// Package broker ส่งข้อความตาม topic ระหว่าง server หลายเครื่อง
+// ผู้ publish ไม่ต้องรู้ว่าผู้รับอยู่เครื่องไหน
+package broker
+
+import (
+    "errors"
+    "sync"
+)
+
+var ErrClosed = errors.New("broker is closed")
+
+// Handler รับข้อความของ topic ที่ subscribe ไว้ ถูกเรียกจาก goroutine ของ broker จึงต้องไม่บล็อก
+type Handler func(data []byte)
+
+// Broker ส่งข้อความของ topic ให้ทุก handler ที่ subscribe ไว้ ทั้งในเครื่องนี้และเครื่องอื่นที่ใช้ broker เดียวกัน
+// ข้อความของ topic เดียวกันจากผู้ publish เดียวกันถึง handler ตามลำดับ ข้อความที่ส่งระหว่างที่ broker หลุดอาจหายได้
+type Broker interface {
+    Publish(topic string, data []byte) error
+    // Subscribe คืน unsubscribe ที่ยกเลิกเฉพาะ handler นี้ เมื่อ Subscribe คืนค่าแล้ว
+    // ข้อความที่ publish หลังจากนี้จะถึง handler
+    Subscribe(topic string, handler Handler) (unsubscribe func(), err error)
+    Close() error
+}
+
+// Local ส่งข้อความภายใน process เดียว ใช้เมื่อมี server เครื่องเดียว handler ถูกเรียกใน goroutine ของผู้ publish
+type Local struct {
+    mu       sync.RWMutex
+    handlers map[string]map[*Handler]struct{}
+    closed   bool
+}
+
+func NewLocal() *Local {
+    return &Local{handlers: make(map[string]map[*Handler]struct{})}
+}
+
+func (l *Local) Publish(topic string, data []byte) error {
+    l.mu.RLock()
+    if l.closed {
+        l.mu.RUnlock()
+        return ErrClosed
+    }
+    handlers := make([]Handler, 0, len(l.handlers[topic]))
+    for handler := range l.handlers[topic] {
+        handlers = append(handlers, *handler)
+    }
+    l.mu.RUnlock()
+
+    for _, handler := range handlers {
+        handler(data)
+    }
+    return nil
+}
+
+func (l *Local) Subscribe(topic string, handler Handler) (func(), error) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    if l.closed {
+        return nil, ErrClosed
+    }
+
+    handlers, exists := l.handlers[topic]
+    if !exists {
+        handlers = make(map[*Handler]struct{})
+        l.handlers[topic] = handlers
+    }
+    key := &handler
+    handlers[key] = struct{}{}
+
+    return func() {
+        l.mu.Lock()
+        defer l.mu.Unlock()
+        delete(l.handlers[topic], key)
+        if len(l.handlers[topic]) == 0 {
+            delete(l.handlers, topic)
+        }
+    }, nil
+}
+
+func (l *Local) Close() error {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.closed = true
+    l.handlers = make(map[string]map[*Handler]struct{})
+    return nil
+}
\ No newline at end of file
@@ -0,0 +1,194 @@
+package broker
+
+import (
+    "errors"
+    "fmt"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/tem-mars/tft-game-server/pkg/broker/brokertest"
+    "github.com/tem-mars/tft-game-server/pkg/logger"
+)
+
+// inbox เก็บข้อความที่ handler ได้รับ ใช้ได้จากหลาย goroutine
+type inbox struct {
+    mu       sync.Mutex
+    messages []string
+    arrived  chan struct{}
+}
+
+func newInbox() *inbox { return &inbox{arrived: make(chan struct{}, 1024)} }
+
+func (in *inbox) handler(data []byte) {
+    in.mu.Lock()
+    in.messages = append(in.messages, string(data))
+    in.mu.Unlock()
+    in.arrived <- struct{}{}
+}
+
+// wait รอจนได้ข้อความครบ n อันแล้วคืนทั้งหมด
+func (in *inbox) wait(t *testing.T, n int) []string {
+    t.Helper()
+    timeout := time.After(2 * time.Second)
+    for {
+        in.mu.Lock()
+        if len(in.messages) >= n {
+            messages := append([]string(nil), in.messages...)
+            in.mu.Unlock()
+            return messages
+        }
+        in.mu.Unlock()
+        select {
+        case <-in.arrived:
+        case <-timeout:
+            t.Fatalf("expected %d messages, got %v", n, in.messages)
+        }
+    }
+}
+
+func (in *inbox) count() int {
+    in.mu.Lock()
+    defer in.mu.Unlock()
+    return len(in.messages)
+}
+
+// testBroker ทดสอบพฤติกรรมที่ทุก Broker ต้องมี nodes คือ broker ของแต่ละเครื่องที่เชื่อมถึงกัน
+func testBroker(t *testing.T, nodes []Broker) {
+    first, last := nodes[0], nodes[len(nodes)-1]
+
+    t.Run("Every Node Receives", func(t *testing.T) {
+        inboxes := make([]*inbox, len(nodes))
+        for i, node := range nodes {
+            inboxes[i] = newInbox()
+            unsubscribe, err := node.Subscribe("game:1", inboxes[i].handler)
+            if err != nil {
+                t.Fatalf("failed to subscribe: %v", err)
+            }
+            defer unsubscribe()
+        }
+
+        for i := 0; i < 20; i++ {
+            if err := first.Publish("game:1", []byte(fmt.Sprint(i))); err != nil {
+                t.Fatalf("failed to publish: %v", err)
+            }
+        }
+        for i, in := range inboxes {
+            messages := in.wait(t, 20)
+            for j, message := range messages {
+                if message != fmt.Sprint(j) {
+                    t.Fatalf("node %d: expected messages in order, got %v", i, messages)
+                }
+            }
+        }
+    })
+
+    t.Run("Topics Are Separate", func(t *testing.T) {
+        game, lobby := newInbox(), newInbox()
+        unsubscribeGame, _ := last.Subscribe("game:2", game.handler)
+        defer unsubscribeGame()
+        unsubscribeLobby, _ := last.Subscribe("lobby", lobby.handler)
+        defer unsubscribeLobby()
+
+        first.Publish("lobby", []byte("list"))
+        if got := lobby.wait(t, 1); got[0] != "list" {
+            t.Errorf("expected lobby message, got %v", got)
+        }
+        if game.count() != 0 {
+            t.Error("expected nothing on game:2")
+        }
+    })
+
+    t.Run("Unsubscribe Stops Only That Handler", func(t *testing.T) {
+        kept, dropped := newInbox(), newInbox()
+        unsubscribeKept, _ := last.Subscribe("player:1", kept.handler)
+        defer unsubscribeKept()
+        unsubscribeDropped, _ := last.Subscribe("player:1", dropped.handler)
+
+        unsubscribeDropped()
+        first.Publish("player:1", []byte("hello"))
+        kept.wait(t, 1)
+        if dropped.count() != 0 {
+            t.Error("expected no message after unsubscribe")
+        }
+    })
+}
+
+func TestLocal(t *testing.T) {
+    local := NewLocal()
+    testBroker(t, []Broker{local})
+
+    local.Close()
+    if err := local.Publish("lobby", nil); !errors.Is(err, ErrClosed) {
+        t.Errorf("expected %v, got %v", ErrClosed, err)
+    }
+}
+
+func TestRedis(t *testing.T) {
+    server, err := brokertest.NewServer("secret")
+    if err != nil {
+        t.Fatalf("failed to start server: %v", err)
+    }
+    defer server.Close()
+
+    log := logger.New()
+    cfg := RedisConfig{Addr: server.Addr(), Password: "secret", Prefix: "tft:"}
+    nodeA, err := NewRedis(cfg, log)
+    if err != nil {
+        t.Fatalf("failed to connect: %v", err)
+    }
+    defer nodeA.Close()
+    nodeB, _ := NewRedis(cfg, log)
+    defer nodeB.Close()
+
+    testBroker(t, []Broker{nodeA, nodeB})
+
+    t.Run("Wrong Password", func(t *testing.T) {
+        if _, err := NewRedis(RedisConfig{Addr: server.Addr(), Password: "nope"}, log); err == nil {
+            t.Error("expected auth error")
+        }
+    })
+
+    t.Run("Channels Use Prefix", func(t *testing.T) {
+        unsubscribe, _ := nodeB.Subscribe("global", func([]byte) {})
+        defer unsubscribe()
+        if server.Subscribers("tft:global") != 1 {
+            t.Errorf("expected subscription on tft:global, got %d", server.Subscribers("tft:global"))
+        }
+    })
+
+    t.Run("Resubscribes After Reconnect", func(t *testing.T) {
+        in := newInbox()
+        unsubscribe, _ := nodeB.Subscribe("queue:ranked", in.handler)
+        defer unsubscribe()
+
+        server.DropConnections()
+        deadline := time.Now().Add(2 * time.Second)
+        for server.Subscribers("tft:queue:ranked") == 0 {
+            if time.Now().After(deadline) {
+                t.Fatal("expected node to subscribe again after reconnect")
+            }
+            time.Sleep(10 * time.Millisecond)
+        }
+
+        // การเชื่อมต่อ publish อาจยังต่อใหม่ไม่เสร็จ ส่งซ้ำจนกว่าจะได้
+        for in.count() == 0 {
+            if time.Now().After(deadline) {
+                t.Fatal("expected messages after reconnect")
+            }
+            nodeA.Publish("queue:ranked", []byte("stats"))
+            time.Sleep(20 * time.Millisecond)
+        }
+    })
+
+    t.Run("Closed", func(t *testing.T) {
+        node, _ := NewRedis(cfg, log)
+        node.Close()
+        if err := node.Publish("lobby", nil); !errors.Is(err, ErrClosed) {
+            t.Errorf("expected %v, got %v", ErrClosed, err)
+        }
+        if _, err := node.Subscribe("lobby", func([]byte) {}); !errors.Is(err, ErrClosed) {
+            t.Errorf("expected %v, got %v", ErrClosed, err)
+        }
+    })
+}
\ No newline at end of file
@@ -0,0 +1,249 @@
+// Package brokertest มี server จำลองที่พูดโปรโตคอลของ Redis เฉพาะส่วนที่ broker.Redis ใช้
+// สำหรับทดสอบหลาย node ในเครื่องเดียวโดยไม่ต้องมี Redis จริง
+package brokertest
+
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "net"
+    "strconv"
+    "strings"
+    "sync"
+)
+
+// Server รับ AUTH, PING, PUBLISH, SUBSCRIBE และ UNSUBSCRIBE
+type Server struct {
+    listener net.Listener
+    password string
+
+    mu      sync.Mutex
+    clients map[*client]struct{}
+    closed  bool
+    wg      sync.WaitGroup
+}
+
+type client struct {
+    conn     net.Conn
+    mu       sync.Mutex // เรียงการเขียนเพราะ PUBLISH ของ client อื่นเขียนมาที่นี่ด้วย
+    channels map[string]struct{}
+    authed   bool
+}
+
+// NewServer เปิด server ที่ 127.0.0.1 บน port ว่าง ถ้า password ไม่ว่างต้อง AUTH ก่อนใช้คำสั่งอื่น
+func NewServer(password string) (*Server, error) {
+    listener, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        return nil, err
+    }
+    s := &Server{
+        listener: listener,
+        password: password,
+        clients:  make(map[*client]struct{}),
+    }
+    s.wg.Add(1)
+    go s.accept()
+    return s, nil
+}
+
+func (s *Server) Addr() string { return s.listener.Addr().String() }
+
+// Close ปิด server และทุกการเชื่อมต่อ
+func (s *Server) Close() {
+    s.mu.Lock()
+    s.closed = true
+    s.mu.Unlock()
+    s.listener.Close()
+    s.DropConnections()
+    s.wg.Wait()
+}
+
+// DropConnections ตัดทุกการเชื่อมต่อที่เปิดอยู่ ใช้ทดสอบการต่อใหม่
+func (s *Server) DropConnections() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    for c := range s.clients {
+        c.conn.Close()
+    }
+}
+
+// Subscribers คืนจำนวนการเชื่อมต่อที่ subscribe channel นี้อยู่
+func (s *Server) Subscribers(channel string) int {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    count := 0
+    for c := range s.clients {
+        if _, ok := c.channels[channel]; ok {
+            count++
+        }
+    }
+    return count
+}
+
+func (s *Server) accept() {
+    defer s.wg.Done()
+    for {
+        conn, err := s.listener.Accept()
+        if err != nil {
+            return
+        }
+        c := &client{conn: conn, channels: make(map[string]struct{}), authed: s.password == ""}
+        s.mu.Lock()
+        if s.closed {
+            s.mu.Unlock()
+            conn.Close()
+            return
+        }
+        s.clients[c] = struct{}{}
+        s.mu.Unlock()
+
+        s.wg.Add(1)
+        go s.serve(c)
+    }
+}
+
+func (s *Server) serve(c *client) {
+    defer s.wg.Done()
+    defer func() {
+        s.mu.Lock()
+        delete(s.clients, c)
+        s.mu.Unlock()
+        c.conn.Close()
+    }()
+
+    reader := bufio.NewReader(c.conn)
+    for {
+        args, err := readCommand(reader)
+        if err != nil {
+            return
+        }
+        s.handle(c, args)
+    }
+}
+
+func (s *Server) handle(c *client, args []string) {
+    command := strings.ToUpper(args[0])
+    if !c.authed && command != "AUTH" {
+        c.write(appendError(nil, "NOAUTH Authentication required."))
+        return
+    }
+
+    switch command {
+    case "AUTH":
+        if len(args) != 2 || args[1] != s.password {
+            c.write(appendError(nil, "WRONGPASS invalid password"))
+            return
+        }
+        c.authed = true
+        c.write([]byte("+OK\r\n"))
+    case "PING":
+        c.write([]byte("+PONG\r\n"))
+    case "PUBLISH":
+        if len(args) != 3 {
+            c.write(appendError(nil, "ERR wrong number of arguments for 'publish' command"))
+            return
+        }
+        c.write(appendInt(nil, s.publish(args[1], args[2])))
+    case "SUBSCRIBE", "UNSUBSCRIBE":
+        for _, channel := range args[1:] {
+            s.mu.Lock()
+            if command == "SUBSCRIBE" {
+                c.channels[channel] = struct{}{}
+            } else {
+                delete(c.channels, channel)
+            }
+            count := len(c.channels)
+            s.mu.Unlock()
+            c.write(appendPush(nil, strings.ToLower(command), channel, count))
+        }
+    default:
+        c.write(appendError(nil, fmt.Sprintf("ERR unknown command '%s'", args[0])))
+    }
+}
+
+// publish ส่งข้อความให้ทุก client ที่ subscribe channel คืนจำนวนผู้รับแบบเดียวกับ Redis
+func (s *Server) publish(channel, message string) int {
+    s.mu.Lock()
+    var receivers []*client
+    for c := range s.clients {
+        if _, ok := c.channels[channel]; ok {
+            receivers = append(receivers, c)
+        }
+    }
+    s.mu.Unlock()
+
+    for _, c := range receivers {
+        b := appendArrayHeader(nil, 3)
+        b = appendBulk(b, "message")
+        b = appendBulk(b, channel)
+        b = appendBulk(b, message)
+        c.write(b)
+    }
+    return len(receivers)
+}
+
+func (c *client) write(b []byte) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.conn.Write(b)
+}
+
+// readCommand อ่านคำสั่งที่เป็น array ของ bulk string
+func readCommand(r *bufio.Reader) ([]string, error) {
+    n, err := readHeader(r, '*')
+    if err != nil {
+        return nil, err
+    }
+    if n < 1 {
+        return nil, fmt.Errorf("empty command")
+    }
+    args := make([]string, n)
+    for i := range args {
+        size, err := readHeader(r, '$')
+        if err != nil {
+            return nil, err
+        }
+        data := make([]byte, size+2)
+        if _, err := io.ReadFull(r, data); err != nil {
+            return nil, err
+        }
+        args[i] = string(data[:size])
+    }
+    return args, nil
+}
+
+func readHeader(r *bufio.Reader, prefix byte) (int, error) {
+    line, err := r.ReadString('\n')
+    if err != nil {
+        return 0, err
+    }
+    if len(line) < 3 || line[0] != prefix {
+        return 0, fmt.Errorf("unexpected %q", line)
+    }
+    return strconv.Atoi(strings.TrimRight(line[1:], "\r\n"))
+}
+
+func appendError(b []byte, message string) []byte {
+    return append(append(append(b, '-'), message...), '\r', '\n')
+}
+
+func appendInt(b []byte, n int) []byte {
+    return append(strconv.AppendInt(append(b, ':'), int64(n), 10), '\r', '\n')
+}
+
+func appendArrayHeader(b []byte, n int) []byte {
+    return append(strconv.AppendInt(append(b, '*'), int64(n), 10), '\r', '\n')
+}
+
+func appendBulk(b []byte, s string) []byte {
+    b = append(strconv.AppendInt(append(b, '$'), int64(len(s)), 10), '\r', '\n')
+    return append(append(b, s...), '\r', '\n')
+}
+
+// appendPush คือคำยืนยันของ SUBSCRIBE และ UNSUBSCRIBE
+func appendPush(b []byte, kind, channel string, count int) []byte {
+    b = appendArrayHeader(b, 3)
+    b = appendBulk(b, kind)
+    b = appendBulk(b, channel)
+    return appendInt(b, count)
+}
\ No newline at end of file
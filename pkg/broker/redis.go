@@ -0,0 +1,459 @@
+package broker
+
+import (
+    "bufio"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "strconv"
+    "sync"
+    "time"
+
+    "github.com/tem-mars/tft-game-server/pkg/logger"
+)
+
+var ErrQueueFull = errors.New("publish queue is full")
+
+// RedisConfig คือการเชื่อมต่อกับ server ที่พูดโปรโตคอลของ Redis (RESP) เช่น Redis, Valkey หรือ KeyDB
+type RedisConfig struct {
+    Addr        string
+    Password    string        // ว่าง = ไม่ต้อง AUTH
+    Prefix      string        // นำหน้าชื่อ channel กันชนกับระบบอื่นที่ใช้ Redis เดียวกัน
+    DialTimeout time.Duration // 0 = 5 วินาที
+    QueueSize   int           // ข้อความที่รอส่งได้ 0 = 1024
+}
+
+const (
+    redisConfirmTimeout = 5 * time.Second
+    redisMaxBackoff     = 5 * time.Second
+)
+
+// Redis ส่งข้อความผ่าน PUBLISH/SUBSCRIBE ของ Redis ใช้การเชื่อมต่อสองเส้น
+// เส้นหนึ่งส่ง PUBLISH ต่อกันโดยไม่รอคำตอบ อีกเส้นอยู่ในโหมด subscribe และรับข้อความ
+// ถ้าการเชื่อมต่อหลุดจะต่อใหม่เองและ subscribe ทุก channel ใหม่ ข้อความระหว่างนั้นหาย
+type Redis struct {
+    cfg RedisConfig
+    log logger.Logger
+
+    queue chan [2]string // channel และข้อความที่รอ PUBLISH
+    done  chan struct{}
+
+    mu       sync.Mutex
+    sub      net.Conn                 // nil ระหว่างต่อใหม่
+    channels map[string]*redisChannel // key: channel ที่มี prefix แล้ว
+    inflight map[string]int           // SUBSCRIBE ที่ส่งไปแล้วแต่ยังไม่ได้คำยืนยันในการเชื่อมต่อปัจจุบัน
+    closed   bool
+    wg       sync.WaitGroup
+}
+
+// redisChannel คือ handler ทั้งหมดของ channel หนึ่งในเครื่องนี้
+type redisChannel struct {
+    handlers map[*Handler]struct{}
+    ready    chan struct{} // ปิดเมื่อ Redis ยืนยัน SUBSCRIBE ของการเชื่อมต่อปัจจุบันแล้ว
+}
+
+// NewRedis ต่อ Redis ทันทีเพื่อให้ server ที่ตั้งค่าผิดล้มตั้งแต่เริ่ม
+func NewRedis(cfg RedisConfig, log logger.Logger) (*Redis, error) {
+    if cfg.DialTimeout <= 0 {
+        cfg.DialTimeout = 5 * time.Second
+    }
+    if cfg.QueueSize <= 0 {
+        cfg.QueueSize = 1024
+    }
+
+    r := &Redis{
+        cfg:      cfg,
+        log:      log,
+        queue:    make(chan [2]string, cfg.QueueSize),
+        done:     make(chan struct{}),
+        channels: make(map[string]*redisChannel),
+        inflight: make(map[string]int),
+    }
+
+    pub, err := r.dial()
+    if err != nil {
+        return nil, err
+    }
+    sub, err := r.dial()
+    if err != nil {
+        pub.Close()
+        return nil, err
+    }
+    r.sub = sub
+
+    r.wg.Add(2)
+    go r.publishLoop(pub)
+    go r.subscribeLoop(sub)
+    return r, nil
+}
+
+// dial ต่อ Redis และ AUTH ถ้ามีรหัสผ่าน
+func (r *Redis) dial() (net.Conn, error) {
+    conn, err := net.DialTimeout("tcp", r.cfg.Addr, r.cfg.DialTimeout)
+    if err != nil {
+        return nil, err
+    }
+    if r.cfg.Password != "" {
+        conn.SetDeadline(time.Now().Add(r.cfg.DialTimeout))
+        if _, err := conn.Write(appendCommand(nil, "AUTH", r.cfg.Password)); err != nil {
+            conn.Close()
+            return nil, err
+        }
+        if _, err := readReply(bufio.NewReader(conn)); err != nil {
+            conn.Close()
+            return nil, fmt.Errorf("auth: %w", err)
+        }
+        conn.SetDeadline(time.Time{})
+    }
+    return conn, nil
+}
+
+// redial ต่อใหม่จนได้หรือจนกว่าจะ Close รอนานขึ้นเรื่อยๆ ระหว่างแต่ละครั้ง
+func (r *Redis) redial() (net.Conn, bool) {
+    backoff := 100 * time.Millisecond
+    for {
+        select {
+        case <-r.done:
+            return nil, false
+        case <-time.After(backoff):
+        }
+        conn, err := r.dial()
+        if err == nil {
+            return conn, true
+        }
+        r.log.Error("Failed to reconnect to broker", logger.String("addr", r.cfg.Addr), logger.Error(err))
+        if backoff *= 2; backoff > redisMaxBackoff {
+            backoff = redisMaxBackoff
+        }
+    }
+}
+
+// Publish ใส่ข้อความลงคิวแล้วคืนทันที ข้อความถูกส่งตามลำดับที่เข้าคิว
+func (r *Redis) Publish(topic string, data []byte) error {
+    select {
+    case <-r.done:
+        return ErrClosed
+    default:
+    }
+    select {
+    case r.queue <- [2]string{r.cfg.Prefix + topic, string(data)}:
+        return nil
+    default:
+        return ErrQueueFull
+    }
+}
+
+// publishLoop เขียน PUBLISH ทุกอันในคิวต่อกันแล้วค่อย flush ส่วนคำตอบอ่านทิ้งใน goroutine แยก
+func (r *Redis) publishLoop(conn net.Conn) {
+    defer r.wg.Done()
+
+    for {
+        replies := make(chan struct{})
+        go r.drainReplies(conn, replies)
+
+        err := r.writePublishes(conn, replies)
+        conn.Close()
+        <-replies
+        if err == nil {
+            return
+        }
+        r.log.Error("Lost broker publish connection", logger.Error(err))
+
+        var ok bool
+        if conn, ok = r.redial(); !ok {
+            return
+        }
+    }
+}
+
+// writePublishes คืน nil เมื่อ Close และคืน error เมื่อการเชื่อมต่อหลุด ซึ่ง replies จะถูกปิด
+func (r *Redis) writePublishes(conn net.Conn, replies <-chan struct{}) error {
+    w := bufio.NewWriter(conn)
+    var cmd []byte
+    for {
+        var msg [2]string
+        select {
+        case <-r.done:
+            return nil
+        case <-replies:
+            return io.ErrUnexpectedEOF
+        case msg = <-r.queue:
+        }
+        for {
+            cmd = appendCommand(cmd[:0], "PUBLISH", msg[0], msg[1])
+            if _, err := w.Write(cmd); err != nil {
+                return err
+            }
+            // เขียนต่อเท่าที่มีในคิว แล้ว flush ทีเดียว
+            select {
+            case msg = <-r.queue:
+                continue
+            default:
+            }
+            break
+        }
+        if err := w.Flush(); err != nil {
+            return err
+        }
+    }
+}
+
+func (r *Redis) drainReplies(conn net.Conn, done chan struct{}) {
+    defer close(done)
+    reader := bufio.NewReader(conn)
+    for {
+        if _, err := readReply(reader); err != nil {
+            var rerr redisError
+            if errors.As(err, &rerr) {
+                r.log.Error("Broker rejected publish", logger.Error(err))
+                continue
+            }
+            return
+        }
+    }
+}
+
+// Subscribe รอจน Redis ยืนยันว่า subscribe แล้ว ถ้าการเชื่อมต่อหลุดอยู่จะรอไม่เกิน 5 วินาทีแล้วคืน
+// การ subscribe ยังมีผลและจะถูกส่งใหม่เมื่อต่อได้
+func (r *Redis) Subscribe(topic string, handler Handler) (func(), error) {
+    channel := r.cfg.Prefix + topic
+    key := &handler
+
+    r.mu.Lock()
+    if r.closed {
+        r.mu.Unlock()
+        return nil, ErrClosed
+    }
+    state, exists := r.channels[channel]
+    if !exists {
+        state = &redisChannel{handlers: make(map[*Handler]struct{}), ready: make(chan struct{})}
+        r.channels[channel] = state
+        r.sendLocked("SUBSCRIBE", channel)
+    }
+    state.handlers[key] = struct{}{}
+    ready := state.ready
+    r.mu.Unlock()
+
+    unsubscribe := func() {
+        r.mu.Lock()
+        defer r.mu.Unlock()
+        if r.channels[channel] != state {
+            return
+        }
+        delete(state.handlers, key)
+        if len(state.handlers) == 0 {
+            delete(r.channels, channel)
+            r.sendLocked("UNSUBSCRIBE", channel)
+        }
+    }
+
+    select {
+    case <-ready:
+    case <-r.done:
+    case <-time.After(redisConfirmTimeout):
+        r.log.Error("Broker did not confirm subscription", logger.String("channel", channel))
+    }
+    return unsubscribe, nil
+}
+
+// sendLocked เขียนคำสั่งบนการเชื่อมต่อ subscribe ต้องถือ r.mu อยู่
+// ถ้าเขียนไม่ได้จะปิดการเชื่อมต่อ subscribeLoop จะต่อใหม่และ subscribe ทุก channel ให้เอง
+func (r *Redis) sendLocked(command, channel string) {
+    if r.sub == nil {
+        return
+    }
+    if command == "SUBSCRIBE" {
+        r.inflight[channel]++
+    }
+    if _, err := r.sub.Write(appendCommand(nil, command, channel)); err != nil {
+        r.sub.Close()
+    }
+}
+
+// subscribeLoop อ่านข้อความจากการเชื่อมต่อ subscribe และต่อใหม่เมื่อหลุด
+func (r *Redis) subscribeLoop(conn net.Conn) {
+    defer r.wg.Done()
+
+    for {
+        err := r.readMessages(conn)
+        conn.Close()
+
+        r.mu.Lock()
+        r.sub = nil
+        closed := r.closed
+        r.mu.Unlock()
+        if closed {
+            return
+        }
+        r.log.Error("Lost broker subscribe connection", logger.Error(err))
+
+        var ok bool
+        if conn, ok = r.redial(); !ok {
+            return
+        }
+        r.resubscribe(conn)
+    }
+}
+
+// resubscribe ส่ง SUBSCRIBE ของทุก channel ที่ยังมี handler บนการเชื่อมต่อใหม่
+func (r *Redis) resubscribe(conn net.Conn) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    r.sub = conn
+    r.inflight = make(map[string]int)
+    for channel, state := range r.channels {
+        select {
+        case <-state.ready:
+            state.ready = make(chan struct{})
+        default:
+        }
+        r.sendLocked("SUBSCRIBE", channel)
+    }
+}
+
+func (r *Redis) readMessages(conn net.Conn) error {
+    reader := bufio.NewReader(conn)
+    for {
+        reply, err := readReply(reader)
+        if err != nil {
+            return err
+        }
+        push, ok := reply.([]interface{})
+        if !ok || len(push) < 3 {
+            continue
+        }
+        kind, _ := push[0].([]byte)
+        channel, _ := push[1].([]byte)
+
+        switch string(kind) {
+        case "subscribe":
+            r.confirm(string(channel))
+        case "message":
+            data, _ := push[2].([]byte)
+            for _, handler := range r.handlers(string(channel)) {
+                handler(data)
+            }
+        }
+    }
+}
+
+// confirm ปิด ready ของ channel เมื่อได้คำยืนยันของ SUBSCRIBE ครบทุกอันที่ส่งไป
+// คำยืนยันของ SUBSCRIBE เก่าก่อน UNSUBSCRIBE จึงไม่ถูกนับเป็นของอันใหม่
+func (r *Redis) confirm(channel string) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if r.inflight[channel]--; r.inflight[channel] > 0 {
+        return
+    }
+    delete(r.inflight, channel)
+    if state, exists := r.channels[channel]; exists {
+        select {
+        case <-state.ready:
+        default:
+            close(state.ready)
+        }
+    }
+}
+
+// handlers คืนสำเนาของ handler ของ channel เพื่อเรียกโดยไม่ถือ lock
+func (r *Redis) handlers(channel string) []Handler {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    state, exists := r.channels[channel]
+    if !exists {
+        return nil
+    }
+    handlers := make([]Handler, 0, len(state.handlers))
+    for handler := range state.handlers {
+        handlers = append(handlers, *handler)
+    }
+    return handlers
+}
+
+// Close ปิดการเชื่อมต่อทั้งหมด ข้อความที่ยังอยู่ในคิวจะไม่ถูกส่ง
+func (r *Redis) Close() error {
+    r.mu.Lock()
+    if r.closed {
+        r.mu.Unlock()
+        return nil
+    }
+    r.closed = true
+    close(r.done)
+    if r.sub != nil {
+        r.sub.Close()
+    }
+    r.mu.Unlock()
+
+    r.wg.Wait()
+    return nil
+}
+
+// redisError คือคำตอบแบบ error (-ERR ...) จาก Redis
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+// appendCommand เข้ารหัสคำสั่งเป็น array ของ bulk string ตาม RESP
+func appendCommand(b []byte, args ...string) []byte {
+    b = append(b, '*')
+    b = strconv.AppendInt(b, int64(len(args)), 10)
+    b = append(b, '\r', '\n')
+    for _, arg := range args {
+        b = append(b, '$')
+        b = strconv.AppendInt(b, int64(len(arg)), 10)
+        b = append(b, '\r', '\n')
+        b = append(b, arg...)
+        b = append(b, '\r', '\n')
+    }
+    return b
+}
+
+// readReply อ่านคำตอบหนึ่งอันของ RESP2 คืน string, redisError, int64, []byte (nil ถ้าเป็น null) หรือ []interface{}
+// คำตอบแบบ error คืนเป็น err ด้วยเพื่อให้ผู้เรียกแยกได้ง่าย
+func readReply(r *bufio.Reader) (interface{}, error) {
+    line, err := r.ReadString('\n')
+    if err != nil {
+        return nil, err
+    }
+    if len(line) < 3 || line[len(line)-2] != '\r' {
+        return nil, fmt.Errorf("malformed reply %q", line)
+    }
+    kind, body := line[0], line[1:len(line)-2]
+
+    switch kind {
+    case '+':
+        return body, nil
+    case '-':
+        return nil, redisError(body)
+    case ':':
+        return strconv.ParseInt(body, 10, 64)
+    case '$':
+        n, err := strconv.Atoi(body)
+        if err != nil || n < 0 {
+            return nil, err
+        }
+        data := make([]byte, n+2)
+        if _, err := io.ReadFull(r, data); err != nil {
+            return nil, err
+        }
+        return data[:n], nil
+    case '*':
+        n, err := strconv.Atoi(body)
+        if err != nil || n < 0 {
+            return nil, err
+        }
+        items := make([]interface{}, n)
+        for i := range items {
+            if items[i], err = readReply(r); err != nil {
+                return nil, err
+            }
+        }
+        return items, nil
+    default:
+        return nil, fmt.Errorf("unknown reply type %q", kind)
+    }
+}
\ No newline at end of file
@@ -0,0 +1,284 @@
+// Package jsonpatch สร้างและใช้ JSON Patch (RFC 6902) กับเอกสารที่ถอดจาก JSON แล้ว
+// (map[string]interface{}, []interface{}, float64, string, bool, nil)
+package jsonpatch
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "reflect"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+const (
+    OpAdd     = "add"
+    OpRemove  = "remove"
+    OpReplace = "replace"
+)
+
+var ErrInvalidPath = errors.New("invalid patch path")
+
+// Operation คือหนึ่งคำสั่งของ patch
+type Operation struct {
+    Op    string      `json:"op"`
+    Path  string      `json:"path"`
+    Value interface{} `json:"value,omitempty"`
+}
+
+// MarshalJSON ใส่ value เสมอสำหรับ add/replace แม้ค่าจะเป็น null
+func (o Operation) MarshalJSON() ([]byte, error) {
+    if o.Op == OpRemove {
+        return json.Marshal(struct {
+            Op   string `json:"op"`
+            Path string `json:"path"`
+        }{o.Op, o.Path})
+    }
+    return json.Marshal(struct {
+        Op    string      `json:"op"`
+        Path  string      `json:"path"`
+        Value interface{} `json:"value"`
+    }{o.Op, o.Path, o.Value})
+}
+
+// Decode แปลงค่าใดๆ เป็นเอกสาร JSON ทั่วไปที่ Diff และ Apply ใช้ได้
+func Decode(v interface{}) (interface{}, error) {
+    data, err := json.Marshal(v)
+    if err != nil {
+        return nil, err
+    }
+    var doc interface{}
+    if err := json.Unmarshal(data, &doc); err != nil {
+        return nil, err
+    }
+    return doc, nil
+}
+
+// Diff คืน patch ที่เปลี่ยน from ให้เป็น to
+// array ที่ยาวขึ้นจะได้แค่ add ของสมาชิกใหม่ ขนาดของ patch จึงขึ้นกับสิ่งที่เปลี่ยนเท่านั้น
+func Diff(from, to interface{}) []Operation {
+    return diff("", from, to, nil)
+}
+
+func diff(path string, from, to interface{}, ops []Operation) []Operation {
+    switch f := from.(type) {
+    case map[string]interface{}:
+        t, ok := to.(map[string]interface{})
+        if !ok {
+            return append(ops, Operation{Op: OpReplace, Path: path, Value: to})
+        }
+        for _, key := range sortedKeys(f) {
+            if _, exists := t[key]; !exists {
+                ops = append(ops, Operation{Op: OpRemove, Path: path + "/" + escape(key)})
+            }
+        }
+        for _, key := range sortedKeys(t) {
+            if old, exists := f[key]; exists {
+                ops = diff(path+"/"+escape(key), old, t[key], ops)
+            } else {
+                ops = append(ops, Operation{Op: OpAdd, Path: path + "/" + escape(key), Value: t[key]})
+            }
+        }
+        return ops
+
+    case []interface{}:
+        t, ok := to.([]interface{})
+        if !ok {
+            return append(ops, Operation{Op: OpReplace, Path: path, Value: to})
+        }
+        common := len(f)
+        if len(t) < common {
+            common = len(t)
+        }
+        for i := 0; i < common; i++ {
+            ops = diff(path+"/"+strconv.Itoa(i), f[i], t[i], ops)
+        }
+        // ลบจากท้ายก่อนเพื่อไม่ให้ index ของตัวที่เหลือเลื่อน
+        for i := len(f) - 1; i >= len(t); i-- {
+            ops = append(ops, Operation{Op: OpRemove, Path: path + "/" + strconv.Itoa(i)})
+        }
+        for i := len(f); i < len(t); i++ {
+            ops = append(ops, Operation{Op: OpAdd, Path: path + "/" + strconv.Itoa(i), Value: t[i]})
+        }
+        return ops
+
+    default:
+        if !reflect.DeepEqual(from, to) {
+            ops = append(ops, Operation{Op: OpReplace, Path: path, Value: to})
+        }
+        return ops
+    }
+}
+
+// Apply ใช้ patch กับ doc และคืนเอกสารผลลัพธ์ doc อาจถูกแก้ไปด้วย
+func Apply(doc interface{}, ops []Operation) (interface{}, error) {
+    for _, op := range ops {
+        var err error
+        doc, err = applyOne(doc, op)
+        if err != nil {
+            return nil, fmt.Errorf("%s %s: %w", op.Op, op.Path, err)
+        }
+    }
+    return doc, nil
+}
+
+func applyOne(doc interface{}, op Operation) (interface{}, error) {
+    if op.Path == "" {
+        if op.Op == OpRemove {
+            return nil, nil
+        }
+        return copyValue(op.Value), nil
+    }
+    if !strings.HasPrefix(op.Path, "/") {
+        return nil, ErrInvalidPath
+    }
+
+    tokens := strings.Split(op.Path[1:], "/")
+    for i := range tokens {
+        tokens[i] = unescape(tokens[i])
+    }
+    parent, err := walk(doc, tokens[:len(tokens)-1])
+    if err != nil {
+        return nil, err
+    }
+    last := tokens[len(tokens)-1]
+
+    switch container := parent.(type) {
+    case map[string]interface{}:
+        _, exists := container[last]
+        switch op.Op {
+        case OpAdd:
+            container[last] = copyValue(op.Value)
+        case OpReplace:
+            if !exists {
+                return nil, ErrInvalidPath
+            }
+            container[last] = copyValue(op.Value)
+        case OpRemove:
+            if !exists {
+                return nil, ErrInvalidPath
+            }
+            delete(container, last)
+        default:
+            return nil, fmt.Errorf("unsupported op %q", op.Op)
+        }
+        return doc, nil
+
+    case []interface{}:
+        index := len(container)
+        if last != "-" {
+            index, err = strconv.Atoi(last)
+            if err != nil || index < 0 || index > len(container) {
+                return nil, ErrInvalidPath
+            }
+        }
+        var updated []interface{}
+        switch op.Op {
+        case OpAdd:
+            updated = append(container[:index:index], append([]interface{}{copyValue(op.Value)}, container[index:]...)...)
+        case OpReplace, OpRemove:
+            if index >= len(container) {
+                return nil, ErrInvalidPath
+            }
+            if op.Op == OpReplace {
+                container[index] = copyValue(op.Value)
+                return doc, nil
+            }
+            updated = append(container[:index:index], container[index+1:]...)
+        default:
+            return nil, fmt.Errorf("unsupported op %q", op.Op)
+        }
+        // array เปลี่ยนขนาด ต้องเอา slice ใหม่ไปใส่แทนที่ใน parent ของมัน
+        return setAt(doc, tokens[:len(tokens)-1], updated)
+
+    default:
+        return nil, ErrInvalidPath
+    }
+}
+
+// walk เดินตาม token จาก doc และคืนค่าที่ปลายทาง
+func walk(doc interface{}, tokens []string) (interface{}, error) {
+    current := doc
+    for _, token := range tokens {
+        switch container := current.(type) {
+        case map[string]interface{}:
+            next, exists := container[token]
+            if !exists {
+                return nil, ErrInvalidPath
+            }
+            current = next
+        case []interface{}:
+            index, err := strconv.Atoi(token)
+            if err != nil || index < 0 || index >= len(container) {
+                return nil, ErrInvalidPath
+            }
+            current = container[index]
+        default:
+            return nil, ErrInvalidPath
+        }
+    }
+    return current, nil
+}
+
+// setAt แทนค่าที่ตำแหน่ง tokens ด้วย value และคืน doc ใหม่
+func setAt(doc interface{}, tokens []string, value interface{}) (interface{}, error) {
+    if len(tokens) == 0 {
+        return value, nil
+    }
+    parent, err := walk(doc, tokens[:len(tokens)-1])
+    if err != nil {
+        return nil, err
+    }
+    last := tokens[len(tokens)-1]
+    switch container := parent.(type) {
+    case map[string]interface{}:
+        container[last] = value
+    case []interface{}:
+        index, err := strconv.Atoi(last)
+        if err != nil || index < 0 || index >= len(container) {
+            return nil, ErrInvalidPath
+        }
+        container[index] = value
+    default:
+        return nil, ErrInvalidPath
+    }
+    return doc, nil
+}
+
+// copyValue คัดลอก map และ slice เพื่อไม่ให้เอกสารผลลัพธ์แชร์ข้อมูลกับ patch
+func copyValue(v interface{}) interface{} {
+    switch value := v.(type) {
+    case map[string]interface{}:
+        copied := make(map[string]interface{}, len(value))
+        for k, item := range value {
+            copied[k] = copyValue(item)
+        }
+        return copied
+    case []interface{}:
+        copied := make([]interface{}, len(value))
+        for i, item := range value {
+            copied[i] = copyValue(item)
+        }
+        return copied
+    default:
+        return value
+    }
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+    keys := make([]string, 0, len(m))
+    for k := range m {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+func escape(token string) string {
+    return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescape(token string) string {
+    return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
\ No newline at end of file
@@ -0,0 +1,92 @@
+package jsonpatch
+
+import (
+    "encoding/json"
+    "reflect"
+    "testing"
+)
+
+func decode(t *testing.T, raw string) interface{} {
+    t.Helper()
+    var doc interface{}
+    if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+        t.Fatalf("bad fixture %s: %v", raw, err)
+    }
+    return doc
+}
+
+func TestDiffApply(t *testing.T) {
+    tests := []struct {
+        name string
+        from string
+        to   string
+        ops  int
+    }{
+        {"No Change", `{"a":1,"b":[1,2]}`, `{"a":1,"b":[1,2]}`, 0},
+        {"Replace Scalar", `{"a":1}`, `{"a":2}`, 1},
+        {"Add And Remove Key", `{"a":1,"b":2}`, `{"a":1,"c":3}`, 2},
+        {"Append To Array", `{"list":[1,2]}`, `{"list":[1,2,3,4]}`, 2},
+        {"Shrink Array", `{"list":[1,2,3,4]}`, `{"list":[1]}`, 3},
+        {"Nested Object", `{"players":[{"id":"p1","hp":100},{"id":"p2","hp":100}]}`, `{"players":[{"id":"p1","hp":100},{"id":"p2","hp":80}]}`, 1},
+        {"Type Change", `{"inventory":null}`, `{"inventory":[{"id":"i1"}]}`, 1},
+        {"Value To Null", `{"a":{"b":1}}`, `{"a":null}`, 1},
+        {"Escaped Keys", `{"a/b":1,"c~d":1}`, `{"a/b":2,"c~d":2}`, 2},
+        {"Whole Document", `[1]`, `{"a":1}`, 1},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            ops := Diff(decode(t, tt.from), decode(t, tt.to))
+            if len(ops) != tt.ops {
+                t.Errorf("expected %d ops, got %d: %+v", tt.ops, len(ops), ops)
+            }
+
+            // ส่งผ่าน JSON เหมือนที่ client ได้รับจริง
+            data, err := json.Marshal(ops)
+            if err != nil {
+                t.Fatalf("failed to marshal ops: %v", err)
+            }
+            var received []Operation
+            if err := json.Unmarshal(data, &received); err != nil {
+                t.Fatalf("failed to unmarshal ops: %v", err)
+            }
+
+            got, err := Apply(decode(t, tt.from), received)
+            if err != nil {
+                t.Fatalf("apply failed: %v", err)
+            }
+            if want := decode(t, tt.to); !reflect.DeepEqual(got, want) {
+                t.Errorf("expected %v, got %v", want, got)
+            }
+        })
+    }
+}
+
+func TestAppendOnlySendsNewItems(t *testing.T) {
+    from := decode(t, `{"actions":[{"type":"attack"},{"type":"attack"},{"type":"attack"}]}`)
+    to := decode(t, `{"actions":[{"type":"attack"},{"type":"attack"},{"type":"attack"},{"type":"surrender"}]}`)
+
+    ops := Diff(from, to)
+    if len(ops) != 1 || ops[0].Op != OpAdd || ops[0].Path != "/actions/3" {
+        t.Errorf("expected a single add of /actions/3, got %+v", ops)
+    }
+}
+
+func TestApplyErrors(t *testing.T) {
+    tests := []struct {
+        name string
+        op   Operation
+    }{
+        {"Missing Parent", Operation{Op: OpAdd, Path: "/missing/a", Value: 1}},
+        {"Replace Missing Key", Operation{Op: OpReplace, Path: "/missing", Value: 1}},
+        {"Index Out Of Range", Operation{Op: OpRemove, Path: "/list/5"}},
+        {"Relative Path", Operation{Op: OpAdd, Path: "a", Value: 1}},
+        {"Unknown Op", Operation{Op: "move", Path: "/a"}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if _, err := Apply(decode(t, `{"a":1,"list":[1]}`), []Operation{tt.op}); err == nil {
+                t.Errorf("expected error for %+v", tt.op)
+            }
+        })
+    }
+}
\ No newline at end of file
@@ -0,0 +1,325 @@
+// Package protovalue เข้ารหัสค่าแบบ JSON เป็น protobuf ตาม message Value ใน value.proto
+// ใช้ให้ข้อมูลชุดเดียวกับที่ส่งเป็น JSON ส่งเป็น binary ได้โดยไม่ต้องมี schema แยกต่อ payload
+package protovalue
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "math"
+    "sort"
+
+    "google.golang.org/protobuf/encoding/protowire"
+)
+
+// หมายเลขฟิลด์ของ Value, ListValue และ Object ใน value.proto
+const (
+    fieldNull   protowire.Number = 1
+    fieldBool   protowire.Number = 2
+    fieldInt    protowire.Number = 3 // sint64
+    fieldDouble protowire.Number = 4
+    fieldString protowire.Number = 5
+    fieldList   protowire.Number = 6
+    fieldObject protowire.Number = 7
+
+    fieldListValues protowire.Number = 1 // ListValue.values
+
+    fieldObjectKeys   protowire.Number = 1 // Object.keys เป็น index ในตาราง key
+    fieldObjectValues protowire.Number = 2 // Object.values
+)
+
+// maxDepth กันข้อความที่ซ้อนลึกจน stack ล้น
+const maxDepth = 64
+
+var (
+    ErrTooDeep     = errors.New("value nested too deeply")
+    ErrUnsupported = errors.New("unsupported value type")
+    ErrBadKey      = errors.New("object key index out of range")
+)
+
+// Encoder เข้ารหัสหลายค่าโดยใช้ตาราง key ร่วมกัน ชื่อฟิลด์ที่ซ้ำกันเช่นของผู้เล่นแต่ละคนจึงถูกเก็บครั้งเดียว
+type Encoder struct {
+    keys  []string
+    index map[string]uint64
+}
+
+func NewEncoder() *Encoder {
+    return &Encoder{index: make(map[string]uint64)}
+}
+
+// Keys คืนตาราง key ที่ต้องส่งไปพร้อมค่าที่เข้ารหัสแล้ว
+func (e *Encoder) Keys() []string {
+    return e.keys
+}
+
+func (e *Encoder) key(k string) uint64 {
+    if i, exists := e.index[k]; exists {
+        return i
+    }
+    i := uint64(len(e.keys))
+    e.keys = append(e.keys, k)
+    e.index[k] = i
+    return i
+}
+
+// FromJSON แปลง JSON เป็น Value ที่เข้ารหัสแล้ว ตัวเลขที่เป็นจำนวนเต็มจะเก็บเป็น sint64
+func (e *Encoder) FromJSON(raw []byte) ([]byte, error) {
+    decoder := json.NewDecoder(bytes.NewReader(raw))
+    decoder.UseNumber()
+    var v interface{}
+    if err := decoder.Decode(&v); err != nil {
+        return nil, err
+    }
+    return e.Append(nil, v)
+}
+
+// ToJSON แปลง Value ที่เข้ารหัสแล้วกลับเป็น JSON ด้วยตาราง key ที่มากับข้อความ
+func ToJSON(b []byte, keys []string) ([]byte, error) {
+    v, err := Consume(b, keys)
+    if err != nil {
+        return nil, err
+    }
+    return json.Marshal(v)
+}
+
+// Append เข้ารหัส v เป็น Value ต่อท้าย b
+// v ต้องเป็นค่าที่ได้จาก json.Unmarshal (ใช้ UseNumber ได้) หรือ int, int64
+func (e *Encoder) Append(b []byte, v interface{}) ([]byte, error) {
+    switch value := v.(type) {
+    case nil:
+        b = protowire.AppendTag(b, fieldNull, protowire.VarintType)
+        return protowire.AppendVarint(b, 1), nil
+    case bool:
+        b = protowire.AppendTag(b, fieldBool, protowire.VarintType)
+        return protowire.AppendVarint(b, protowire.EncodeBool(value)), nil
+    case int:
+        return appendInt(b, int64(value)), nil
+    case int64:
+        return appendInt(b, value), nil
+    case float64:
+        if value == math.Trunc(value) && math.Abs(value) < 1<<53 {
+            return appendInt(b, int64(value)), nil
+        }
+        return appendDouble(b, value), nil
+    case json.Number:
+        if i, err := value.Int64(); err == nil {
+            return appendInt(b, i), nil
+        }
+        f, err := value.Float64()
+        if err != nil {
+            return nil, err
+        }
+        return appendDouble(b, f), nil
+    case string:
+        b = protowire.AppendTag(b, fieldString, protowire.BytesType)
+        return protowire.AppendString(b, value), nil
+    case []interface{}:
+        var list []byte
+        for _, item := range value {
+            encoded, err := e.Append(nil, item)
+            if err != nil {
+                return nil, err
+            }
+            list = protowire.AppendTag(list, fieldListValues, protowire.BytesType)
+            list = protowire.AppendBytes(list, encoded)
+        }
+        b = protowire.AppendTag(b, fieldList, protowire.BytesType)
+        return protowire.AppendBytes(b, list), nil
+    case map[string]interface{}:
+        keys := make([]string, 0, len(value))
+        for k := range value {
+            keys = append(keys, k)
+        }
+        sort.Strings(keys)
+
+        // keys เป็น packed varint ตามด้วย values ตามลำดับเดียวกัน
+        var packed, object []byte
+        for _, k := range keys {
+            packed = protowire.AppendVarint(packed, e.key(k))
+        }
+        if len(packed) > 0 {
+            object = protowire.AppendTag(object, fieldObjectKeys, protowire.BytesType)
+            object = protowire.AppendBytes(object, packed)
+        }
+        for _, k := range keys {
+            encoded, err := e.Append(nil, value[k])
+            if err != nil {
+                return nil, err
+            }
+            object = protowire.AppendTag(object, fieldObjectValues, protowire.BytesType)
+            object = protowire.AppendBytes(object, encoded)
+        }
+        b = protowire.AppendTag(b, fieldObject, protowire.BytesType)
+        return protowire.AppendBytes(b, object), nil
+    default:
+        return nil, fmt.Errorf("%w: %T", ErrUnsupported, v)
+    }
+}
+
+func appendInt(b []byte, v int64) []byte {
+    b = protowire.AppendTag(b, fieldInt, protowire.VarintType)
+    return protowire.AppendVarint(b, protowire.EncodeZigZag(v))
+}
+
+func appendDouble(b []byte, v float64) []byte {
+    b = protowire.AppendTag(b, fieldDouble, protowire.Fixed64Type)
+    return protowire.AppendFixed64(b, math.Float64bits(v))
+}
+
+// Consume ถอดรหัส Value ทั้งก้อน จำนวนเต็มได้เป็น int64 และทศนิยมได้เป็น float64
+// Value ที่ไม่มีฟิลด์ใดเลยถือเป็น null ฟิลด์ที่ไม่รู้จักจะถูกข้าม
+func Consume(b []byte, keys []string) (interface{}, error) {
+    return consume(b, keys, 0)
+}
+
+func consume(b []byte, keys []string, depth int) (interface{}, error) {
+    if depth > maxDepth {
+        return nil, ErrTooDeep
+    }
+
+    var result interface{}
+    for len(b) > 0 {
+        num, typ, n := protowire.ConsumeTag(b)
+        if n < 0 {
+            return nil, protowire.ParseError(n)
+        }
+        b = b[n:]
+
+        switch {
+        case num == fieldNull && typ == protowire.VarintType:
+            _, n = protowire.ConsumeVarint(b)
+            result = nil
+        case num == fieldBool && typ == protowire.VarintType:
+            var v uint64
+            v, n = protowire.ConsumeVarint(b)
+            result = protowire.DecodeBool(v)
+        case num == fieldInt && typ == protowire.VarintType:
+            var v uint64
+            v, n = protowire.ConsumeVarint(b)
+            result = protowire.DecodeZigZag(v)
+        case num == fieldDouble && typ == protowire.Fixed64Type:
+            var v uint64
+            v, n = protowire.ConsumeFixed64(b)
+            result = math.Float64frombits(v)
+        case num == fieldString && typ == protowire.BytesType:
+            var v string
+            v, n = protowire.ConsumeString(b)
+            result = v
+        case num == fieldList && typ == protowire.BytesType:
+            var v []byte
+            v, n = protowire.ConsumeBytes(b)
+            if n >= 0 {
+                list, err := consumeList(v, keys, depth+1)
+                if err != nil {
+                    return nil, err
+                }
+                result = list
+            }
+        case num == fieldObject && typ == protowire.BytesType:
+            var v []byte
+            v, n = protowire.ConsumeBytes(b)
+            if n >= 0 {
+                object, err := consumeObject(v, keys, depth+1)
+                if err != nil {
+                    return nil, err
+                }
+                result = object
+            }
+        default:
+            n = protowire.ConsumeFieldValue(num, typ, b)
+        }
+        if n < 0 {
+            return nil, protowire.ParseError(n)
+        }
+        b = b[n:]
+    }
+    return result, nil
+}
+
+func consumeList(b []byte, keys []string, depth int) ([]interface{}, error) {
+    list := []interface{}{}
+    for len(b) > 0 {
+        num, typ, n := protowire.ConsumeTag(b)
+        if n < 0 {
+            return nil, protowire.ParseError(n)
+        }
+        b = b[n:]
+        if num == fieldListValues && typ == protowire.BytesType {
+            var item []byte
+            item, n = protowire.ConsumeBytes(b)
+            if n >= 0 {
+                v, err := consume(item, keys, depth)
+                if err != nil {
+                    return nil, err
+                }
+                list = append(list, v)
+            }
+        } else {
+            n = protowire.ConsumeFieldValue(num, typ, b)
+        }
+        if n < 0 {
+            return nil, protowire.ParseError(n)
+        }
+        b = b[n:]
+    }
+    return list, nil
+}
+
+func consumeObject(b []byte, keys []string, depth int) (map[string]interface{}, error) {
+    var indexes []uint64
+    var values []interface{}
+    for len(b) > 0 {
+        num, typ, n := protowire.ConsumeTag(b)
+        if n < 0 {
+            return nil, protowire.ParseError(n)
+        }
+        b = b[n:]
+        switch {
+        case num == fieldObjectKeys && typ == protowire.BytesType:
+            var packed []byte
+            packed, n = protowire.ConsumeBytes(b)
+            for len(packed) > 0 && n >= 0 {
+                i, m := protowire.ConsumeVarint(packed)
+                if m < 0 {
+                    return nil, protowire.ParseError(m)
+                }
+                indexes = append(indexes, i)
+                packed = packed[m:]
+            }
+        case num == fieldObjectKeys && typ == protowire.VarintType:
+            // keys แบบไม่ packed ซึ่ง protobuf ยอมรับเหมือนกัน
+            var i uint64
+            i, n = protowire.ConsumeVarint(b)
+            indexes = append(indexes, i)
+        case num == fieldObjectValues && typ == protowire.BytesType:
+            var item []byte
+            item, n = protowire.ConsumeBytes(b)
+            if n >= 0 {
+                v, err := consume(item, keys, depth)
+                if err != nil {
+                    return nil, err
+                }
+                values = append(values, v)
+            }
+        default:
+            n = protowire.ConsumeFieldValue(num, typ, b)
+        }
+        if n < 0 {
+            return nil, protowire.ParseError(n)
+        }
+        b = b[n:]
+    }
+
+    if len(indexes) != len(values) {
+        return nil, fmt.Errorf("object has %d keys but %d values", len(indexes), len(values))
+    }
+    object := make(map[string]interface{}, len(values))
+    for i, index := range indexes {
+        if index >= uint64(len(keys)) {
+            return nil, ErrBadKey
+        }
+        object[keys[index]] = values[i]
+    }
+    return object, nil
+}
\ No newline at end of file
@@ -0,0 +1,110 @@
+package protovalue
+
+import (
+    "encoding/json"
+    "errors"
+    "reflect"
+    "testing"
+
+    "google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestRoundTrip(t *testing.T) {
+    tests := []struct {
+        name string
+        json string
+    }{
+        {"Null", `null`},
+        {"Bool", `true`},
+        {"Integer", `-42`},
+        {"Large Integer", `9007199254740993`},
+        {"Double", `1.5`},
+        {"String", `"สวัสดี"`},
+        {"Empty List", `[]`},
+        {"Empty Object", `{}`},
+        {"Nested", `{"players":[{"id":"p1","health":100,"inventory":null,"ready":false}],"seq":7,"ratio":0.25}`},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            encoder := NewEncoder()
+            encoded, err := encoder.FromJSON([]byte(tt.json))
+            if err != nil {
+                t.Fatalf("encode failed: %v", err)
+            }
+            decoded, err := ToJSON(encoded, encoder.Keys())
+            if err != nil {
+                t.Fatalf("decode failed: %v", err)
+            }
+
+            var want, got interface{}
+            json.Unmarshal([]byte(tt.json), &want)
+            json.Unmarshal(decoded, &got)
+            if !reflect.DeepEqual(want, got) {
+                t.Errorf("expected %s, got %s", tt.json, decoded)
+            }
+        })
+    }
+}
+
+func TestSmallerThanJSON(t *testing.T) {
+    // ผู้เล่นแปดคนที่มีฟิลด์ชุดเดียวกัน แบบเดียวกับสถานะเกมจริง
+    players := make([]interface{}, 8)
+    for i := range players {
+        players[i] = map[string]interface{}{
+            "id": "player_" + string(rune('a'+i)), "health": 100 - i*7, "gold": 50 + i,
+            "level": 3, "attack": 12, "defense": 8, "eliminated": false, "auto_pilot": false,
+        }
+    }
+    raw, _ := json.Marshal(map[string]interface{}{"id": "game_1", "round": 12, "players": players})
+
+    encoder := NewEncoder()
+    encoded, err := encoder.FromJSON(raw)
+    if err != nil {
+        t.Fatalf("encode failed: %v", err)
+    }
+    size := len(encoded)
+    for _, k := range encoder.Keys() {
+        size += len(k) + 2
+    }
+    if size*3 > len(raw)*2 {
+        t.Errorf("expected binary under two thirds of %d bytes of JSON, got %d", len(raw), size)
+    }
+}
+
+func TestConsumeErrors(t *testing.T) {
+    t.Run("Truncated", func(t *testing.T) {
+        encoder := NewEncoder()
+        encoded, _ := encoder.FromJSON([]byte(`{"a":"hello"}`))
+        if _, err := Consume(encoded[:len(encoded)-2], encoder.Keys()); err == nil {
+            t.Error("expected error for truncated value")
+        }
+    })
+
+    t.Run("Too Deep", func(t *testing.T) {
+        encoded, _ := NewEncoder().Append(nil, nil)
+        for i := 0; i <= maxDepth+1; i++ {
+            var list []byte
+            list = protowire.AppendTag(list, fieldListValues, protowire.BytesType)
+            list = protowire.AppendBytes(list, encoded)
+            encoded = protowire.AppendTag(nil, fieldList, protowire.BytesType)
+            encoded = protowire.AppendBytes(encoded, list)
+        }
+        if _, err := Consume(encoded, nil); !errors.Is(err, ErrTooDeep) {
+            t.Errorf("expected %v, got %v", ErrTooDeep, err)
+        }
+    })
+
+    t.Run("Unsupported Type", func(t *testing.T) {
+        if _, err := NewEncoder().Append(nil, struct{}{}); !errors.Is(err, ErrUnsupported) {
+            t.Errorf("expected %v, got %v", ErrUnsupported, err)
+        }
+    })
+
+    t.Run("Key Out Of Range", func(t *testing.T) {
+        encoder := NewEncoder()
+        encoded, _ := encoder.FromJSON([]byte(`{"a":1,"b":2}`))
+        if _, err := Consume(encoded, encoder.Keys()[:1]); !errors.Is(err, ErrBadKey) {
+            t.Errorf("expected %v, got %v", ErrBadKey, err)
+        }
+    })
+}
\ No newline at end of file